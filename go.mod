@@ -11,5 +11,5 @@ require (
 	github.com/vedhavyas/go-subkey v1.0.2
 	github.com/zeebo/blake3 v0.1.1
 	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
-	golang.org/x/sys v0.0.0-20210823070655-63515b42dcdf // indirect
+	golang.org/x/sys v0.0.0-20210823070655-63515b42dcdf
 )
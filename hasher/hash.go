@@ -31,9 +31,11 @@ const (
 	BLAKE2B_384
 	BLAKE2B_512
 	BLAKE3_256
+	SHAKE128
+	SHAKE256
 )
 
-const HashersLen = BLAKE3_256 + 1 // 12
+const HashersLen = SHAKE256 + 1 // 14
 
 // Returns a new hasher object
 func (h Hasher) New() hash.Hash {
@@ -65,6 +67,8 @@ func (h Hasher) New() hash.Hash {
 		return b
 	case BLAKE3_256:
 		return blake3.New()
+	case SHAKE128, SHAKE256:
+		return newShake(h)
 	default:
 		return nil
 	}
@@ -97,12 +101,17 @@ func (h Hasher) String() string {
 		return "BLAKE2B_512"
 	case BLAKE3_256:
 		return "BLAKE3_256"
+	case SHAKE128:
+		return "SHAKE128"
+	case SHAKE256:
+		return "SHAKE256"
 	default:
 		return "UNKNOWN HASH FUNCTION"
 	}
 }
 
 // Returns the output size of the hash function
+// For extendable output functions, this is the default output size, see xof.go
 func (h Hasher) Size() int {
 	hf := h.New()
 	if hf == nil {
@@ -58,7 +58,7 @@ func TestHashType_String(t *testing.T) {
 	}
 }
 
-var sizes = [HashersLen]int {28, 32, 48, 64, 28, 32, 48, 64, 32, 48, 64, 32}
+var sizes = [HashersLen]int {28, 32, 48, 64, 28, 32, 48, 64, 32, 48, 64, 32, 32, 64}
 
 func testSize(typ Hasher, t *testing.T) {
 	size := typ.Size()
@@ -88,6 +88,7 @@ func TestHashType_Size(t *testing.T) {
 // SHA2 and SHA3 zero hashes taken from https://www.di-mgt.com.au/sha_testvectors.html
 // BLAKE2 zero hashes taken from official test vectors https://github.com/BLAKE2/BLAKE2/tree/master/testvectors
 // BLAKE3 zero hashes taken from official b3sum utility https://github.com/BLAKE3-team/BLAKE3 reading /dev/null
+// SHAKE zero hashes taken from https://csrc.nist.gov/projects/cryptographic-standards-and-guidelines/example-values
 var zeroHashes = [HashersLen]string {
 	"d14a028c2a3a2bc9476102bb288234c415a2b01f828ea62ac5b3e42f",                                          // SHA2_224
 	"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",                                  // SHA2_256
@@ -107,6 +108,10 @@ var zeroHashes = [HashersLen]string {
 		"903a685b1448b755d56f701afe9be2ce",                                                              // BLAKE2B_512
 
 	"af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",                                  // BLAKE3_256
+
+	"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26",                                  // SHAKE128
+	"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6" +
+		"fc821c49479ab48640292eacb3b7c4be",                                                              // SHAKE256
 }
 // ----------------------------------------------------------------------------------------------------------------- //
 
@@ -158,6 +163,10 @@ var xxnetworkHashes = [HashersLen]string {
 		"ae8728703d4fc20df69426a2518c1a4d",                                                              // BLAKE2B_512
 
 	"65bf8ea0fba6d6a5e4b34593ae374914370ad0d271ba23313bfcd973fb341c21",                                  // BLAKE3_256
+
+	"447eafad1a84058aa87b122b55d191adfa61d7f406bd24196d90cd29717ac2d5",                                  // SHAKE128
+	"6df1a53e65399ee9150ab3e7ca214d9f90e8da30aec3966c0138b49dabab0442936b596393b0b1fc359ba3ec585353aa" +
+		"84f15bcf83312d612bb2a3bd5c7d4d5d",                                                              // SHAKE256
 }
 // ----------------------------------------------------------------------------------------------------------------- //
 
@@ -0,0 +1,88 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/blake2b"
+	"hash"
+	"reflect"
+)
+
+///////////////////////////////////////////////////////////////////////
+// KEYED HASHING
+/*
+	BLAKE2b and BLAKE3 have a native keyed mode, where the key is set
+	in the hash state instead of being written as a prefix of the data.
+	Reset keeps the key, so a keyed object can be created once and used
+	to hash many inputs with the same key
+
+	For BLAKE3, the key replaces the IV, so a 32 byte key costs nothing,
+	while a 32 byte prefix fills half of a 64 byte block. BLAKE2b instead
+	compresses the key as a padded 128 byte block before the data, which
+	costs one more compression for inputs shorter than a block
+
+	BLAKE2b accepts keys of 1 to 64 bytes, and BLAKE3 keys of exactly 32 bytes
+
+	Since Reset and Wipe keep the key, hash objects keyed with a secret
+	must be overwritten with Destroy once they are no longer needed
+*/
+
+// Returns true if the hash function has a native keyed mode
+func (h Hasher) Keyed() bool {
+	switch h {
+	case BLAKE2B_256, BLAKE2B_384, BLAKE2B_512, BLAKE3_256:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns a new hash object keyed with the given key
+// Returns nil if the hash function has no keyed mode, or the key size is not supported
+func (h Hasher) NewKeyed(key []byte) hash.Hash {
+	if len(key) == 0 {
+		return nil
+	}
+	var hf hash.Hash
+	var err error
+	switch h {
+	case BLAKE2B_256:
+		hf, err = blake2b.New256(key)
+	case BLAKE2B_384:
+		hf, err = blake2b.New384(key)
+	case BLAKE2B_512:
+		hf, err = blake2b.New512(key)
+	case BLAKE3_256:
+		hf, err = blake3.NewKeyed(key)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return hf
+}
+
+// Overwrite the whole state of the hash object, including the key of keyed hash objects
+// The hash object can't be used after this
+func Destroy(hf hash.Hash) {
+	if s, ok := hf.(*shake); ok {
+		zeroState(s.ShakeHash)
+	}
+	zeroState(hf)
+}
+
+// Set the value pointed to by the hash object to its zero value
+func zeroState(hf interface{}) {
+	v := reflect.ValueOf(hf)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	state := v.Elem()
+	state.Set(reflect.Zero(state.Type()))
+}
@@ -0,0 +1,126 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------------------------------------------- //
+// TEST VECTORS FOR KEYED ZERO HASHES
+// BLAKE2B_512 taken from official test vectors https://github.com/BLAKE2/BLAKE2/tree/master/testvectors
+// with key 0x00..0x3f
+// BLAKE3_256 taken from official test vectors https://github.com/BLAKE3-team/BLAKE3/blob/master/test_vectors
+// with key "whats the Elvish word for friend"
+var keyedZeroHashes = map[Hasher]struct {
+	key  []byte
+	hash string
+}{
+	BLAKE2B_512: {
+		key: func() []byte {
+			k := make([]byte, 64)
+			for i := range k {
+				k[i] = byte(i)
+			}
+			return k
+		}(),
+		hash: "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2f" +
+			"f4233499391653df7aefcbc13fc51568",
+	},
+	BLAKE3_256: {
+		key:  []byte("whats the Elvish word for friend"),
+		hash: "92b2b75604ed3c761f9d6f62392c8a9227ad0ea3f09573e783f1498a4ed60d26",
+	},
+}
+
+// ----------------------------------------------------------------------------------------------------------------- //
+
+func TestHasher_NewKeyed(t *testing.T) {
+	for typ, vector := range keyedZeroHashes {
+		ref, _ := hex.DecodeString(vector.hash)
+		h := typ.NewKeyed(vector.key)
+		if h == nil {
+			t.Fatalf("%s: Hasher.NewKeyed() returned nil hash function for a valid key!", typ)
+		}
+		if zero := h.Sum(nil); !bytes.Equal(zero, ref) {
+			t.Errorf("%s: Hasher.NewKeyed() returned wrong keyed zero hash! Got %x, expected %x", typ, zero, ref)
+		}
+	}
+
+	key := make([]byte, 32)
+	for i := Hasher(0); i < HashersLen; i++ {
+		h := i.NewKeyed(key)
+		if (h == nil) == i.Keyed() {
+			t.Fatalf("%s: Hasher.NewKeyed() should return nil only if Hasher.Keyed() is false", i)
+		}
+		if h == nil {
+			continue
+		}
+
+		// Keyed hash differs from the hash of the data
+		h.Write(testData)
+		keyed := h.Sum(nil)
+		if len(keyed) != i.Size() || bytes.Equal(keyed, i.Hash(testData)) {
+			t.Errorf("%s: Hasher.NewKeyed() returned hash object that isn't keyed", i)
+		}
+
+		// Reset keeps the key
+		h.Reset()
+		h.Write(testData)
+		if !bytes.Equal(h.Sum(nil), keyed) {
+			t.Errorf("%s: keyed hash object should keep the key when Reset", i)
+		}
+	}
+
+	// Invalid key sizes
+	if BLAKE3_256.NewKeyed(make([]byte, 16)) != nil || BLAKE2B_256.NewKeyed(make([]byte, 65)) != nil ||
+		BLAKE2B_256.NewKeyed(nil) != nil {
+		t.Errorf("Hasher.NewKeyed() should have returned nil for invalid key size!")
+	}
+
+	// Test non existing type
+	typ := HashersLen
+	if typ.Keyed() || typ.NewKeyed(key) != nil {
+		t.Errorf("Hasher.NewKeyed() should have returned nil for unknown type!")
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(0xC0 + i)
+	}
+
+	for i := Hasher(0); i < HashersLen; i++ {
+		var h hash.Hash
+		if i.Keyed() {
+			h = i.NewKeyed(key)
+		} else {
+			h = i.New()
+			h.Write(key)
+		}
+		h.Write(testData)
+		_ = SumTo(h, nil)
+
+		// Wipe keeps the key
+		memory := stateMemory(h)
+		Wipe(h)
+		if i.Keyed() && !containsChunk(memory, key) {
+			t.Errorf("%s: key should be in memory before Destroy()", i)
+		}
+
+		// Destroy overwrites the whole state
+		Destroy(h)
+		if !bytes.Equal(memory, make([]byte, len(memory))) {
+			t.Errorf("%s: Destroy() didn't overwrite the state of the hash object", i)
+		}
+	}
+	Destroy(nil)
+}
@@ -0,0 +1,119 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"hash"
+	"io"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// HASH POOLS
+/*
+	Creating a hash object allocates its state, so code that hashes
+	many times per operation can reuse hash objects from a pool of
+	each hash function instead
+
+	Get returns a hash object that is ready to use, and Put returns
+	it to the pool once it is no longer needed. Objects obtained with
+	Get must not be used after Put
+
+	Reset doesn't clear the buffered data of most hash functions, so
+	objects returned with Put can keep the last data written to them.
+	Hash objects that hash secrets, e.g., a secret seed, are taken from
+	a separate pool with GetSecret, and returned with PutSecret, which
+	overwrites the buffered data and state with Wipe
+*/
+
+var pools, secretPools [HashersLen]sync.Pool
+
+// Zeros written by Wipe, as large as the largest block size (BLAKE3)
+var zeroBlock [8192]byte
+
+func init() {
+	for i := range pools {
+		h := Hasher(i)
+		pools[i].New = func() interface{} {
+			return h.New()
+		}
+		secretPools[i].New = pools[i].New
+	}
+}
+
+// Get a hash object from the pool of the hash function
+// Returns nil for unknown hash functions
+func (h Hasher) Get() hash.Hash {
+	if h >= HashersLen {
+		return nil
+	}
+	return pools[h].Get().(hash.Hash)
+}
+
+// Reset the hash object and return it to the pool of the hash function
+func (h Hasher) Put(hf hash.Hash) {
+	if h >= HashersLen || hf == nil {
+		return
+	}
+	hf.Reset()
+	pools[h].Put(hf)
+}
+
+// Get a hash object for secret data from the secret pool of the hash function
+// The hash object must be returned with PutSecret
+// Returns nil for unknown hash functions
+func (h Hasher) GetSecret() hash.Hash {
+	if h >= HashersLen {
+		return nil
+	}
+	return secretPools[h].Get().(hash.Hash)
+}
+
+// Wipe the hash object and return it to the secret pool of the hash function
+func (h Hasher) PutSecret(hf hash.Hash) {
+	if h >= HashersLen || hf == nil {
+		return
+	}
+	Wipe(hf)
+	secretPools[h].Put(hf)
+}
+
+// Overwrite the data buffered by the hash object, and Reset it
+// Writing a block of zeros after Reset overwrites the buffer of every hash
+// function in this package, but only if the first byte is written on its own:
+// otherwise, full blocks are hashed directly from the input, skipping the buffer
+// Keyed hash objects keep their key
+func Wipe(hf hash.Hash) {
+	if hf == nil {
+		return
+	}
+	size := hf.BlockSize()
+	if size > len(zeroBlock) {
+		size = len(zeroBlock)
+	}
+	hf.Reset()
+	hf.Write(zeroBlock[:1])
+	hf.Write(zeroBlock[1:size])
+	hf.Reset()
+}
+
+// Append the hash of the data written to hf to dst, like hf.Sum(dst)
+// Unlike Sum, the hash object can't be used after this, until it is Reset
+// If dst has enough capacity, no memory is allocated, which isn't the
+// case for Sum with SHA3 hash functions, so SHA3 outputs are read
+// directly from the sponge instead of from a copy of the state
+func SumTo(hf hash.Hash, dst []byte) []byte {
+	size := hf.Size()
+	if cap(dst)-len(dst) < size {
+		return hf.Sum(dst)
+	}
+	if r, ok := hf.(io.Reader); ok {
+		_, _ = r.Read(dst[len(dst) : len(dst)+size])
+		return dst[:len(dst)+size]
+	}
+	return hf.Sum(dst)
+}
@@ -0,0 +1,155 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestHasher_Pool(t *testing.T) {
+	for i := Hasher(0); i < HashersLen; i++ {
+		// Dirty hash objects are reset when returned to the pool
+		h := i.Get()
+		h.Write([]byte("dirty"))
+		i.Put(h)
+
+		h = i.Get()
+		h.Write(testData)
+		if !bytes.Equal(h.Sum(nil), i.Hash(testData)) {
+			t.Errorf("%s: Hasher.Get() returned hash object that wasn't reset", i)
+		}
+		i.Put(h)
+	}
+
+	// Test non existing type
+	typ := HashersLen
+	if typ.Get() != nil {
+		t.Errorf("Hasher.Get() should have returned nil for unknown type!")
+	}
+	typ.Put(nil)
+}
+
+// Get the memory of the state of a hash object, following the sponge of XOFs
+func stateMemory(hf interface{}) []byte {
+	if s, ok := hf.(*shake); ok {
+		return stateMemory(s.ShakeHash)
+	}
+	v := reflect.ValueOf(hf)
+	size := v.Elem().Type().Size()
+	return (*[1 << 20]byte)(unsafe.Pointer(v.Pointer()))[:size:size]
+}
+
+// Check if the memory contains any 8 byte chunk of data
+func containsChunk(memory, data []byte) bool {
+	for i := 0; i+8 <= len(data); i += 8 {
+		if bytes.Contains(memory, data[i:i+8]) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWipe(t *testing.T) {
+	// Shorter than a block, so it's buffered
+	secret := make([]byte, 40)
+	for i := range secret {
+		secret[i] = byte(0xA0 + i)
+	}
+
+	for i := Hasher(0); i < HashersLen; i++ {
+		h := i.New()
+		h.Write(secret)
+		out := SumTo(h, nil)
+
+		// The secret or the output stay in memory after Reset
+		memory := stateMemory(h)
+		h.Reset()
+		if !containsChunk(memory, secret) && !containsChunk(memory, out) {
+			t.Errorf("%s: secret data should be in memory before Wipe()", i)
+		}
+
+		// But not after Wipe
+		Wipe(h)
+		if containsChunk(memory, secret) || containsChunk(memory, out) {
+			t.Errorf("%s: Wipe() didn't overwrite secret data in memory", i)
+		}
+
+		// The wiped object is reset
+		h.Write(testData)
+		if !bytes.Equal(h.Sum(nil), i.Hash(testData)) {
+			t.Errorf("%s: Wipe() should reset the hash object", i)
+		}
+	}
+	Wipe(nil)
+}
+
+func TestHasher_SecretPool(t *testing.T) {
+	for i := Hasher(0); i < HashersLen; i++ {
+		// Dirty hash objects are wiped when returned to the pool
+		h := i.GetSecret()
+		h.Write([]byte("secret"))
+		i.PutSecret(h)
+
+		h = i.GetSecret()
+		h.Write(testData)
+		if !bytes.Equal(h.Sum(nil), i.Hash(testData)) {
+			t.Errorf("%s: Hasher.GetSecret() returned hash object that wasn't reset", i)
+		}
+		i.PutSecret(h)
+	}
+
+	// Test non existing type
+	typ := HashersLen
+	if typ.GetSecret() != nil {
+		t.Errorf("Hasher.GetSecret() should have returned nil for unknown type!")
+	}
+	typ.PutSecret(nil)
+}
+
+func TestSumTo(t *testing.T) {
+	prefix := []byte("prefix")
+	for i := Hasher(0); i < HashersLen; i++ {
+		expected := append(append([]byte{}, prefix...), i.Hash(testData)...)
+
+		// With enough capacity
+		dst := make([]byte, len(prefix), len(prefix)+i.Size())
+		copy(dst, prefix)
+		h := i.Get()
+		h.Write(testData)
+		out := SumTo(h, dst)
+		if !bytes.Equal(out, expected) {
+			t.Errorf("%s: SumTo() returned wrong hash! Got %x, expected %x", i, out, expected)
+		}
+		if &out[0] != &dst[0] {
+			t.Errorf("%s: SumTo() should reuse the memory of dst", i)
+		}
+
+		// Without enough capacity
+		h.Reset()
+		h.Write(testData)
+		out = SumTo(h, prefix)
+		if !bytes.Equal(out, expected) {
+			t.Errorf("%s: SumTo() returned wrong hash without enough capacity! Got %x, expected %x", i, out, expected)
+		}
+		i.Put(h)
+
+		// No allocations
+		dst = dst[:0]
+		allocs := testing.AllocsPerRun(10, func() {
+			h := i.Get()
+			h.Write(testData)
+			dst = SumTo(h, dst[:0])
+			i.Put(h)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Errorf("%s: SumTo() shouldn't allocate memory, got %.1f allocations", i, allocs)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+//go:build race
+// +build race
+
+package hasher
+
+// sync.Pool drops objects randomly with the race detector,
+// so allocations can't be checked
+const raceEnabled = true
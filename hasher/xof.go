@@ -0,0 +1,136 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"golang.org/x/crypto/sha3"
+	"hash"
+	"io"
+)
+
+///////////////////////////////////////////////////////////////////////
+// EXTENDABLE OUTPUT FUNCTIONS
+/*
+	SHAKE128 and SHAKE256 are the extendable output functions (XOFs) of
+	FIPS 202: once the data is written, any number of output bytes can be
+	read from the sponge, and shorter outputs are prefixes of longer ones
+
+	NewXOF returns the sponge itself, and HashN hashes data into exactly n
+	bytes. New returns a hash.Hash with a default output size, so XOFs can
+	be used anywhere a fixed size hash is expected:
+	  SHAKE128: 32 bytes, as in the SHAKE instantiations of XMSS and SPHINCS+
+	  SHAKE256: 64 bytes
+	and SumN appends exactly n bytes of output for any hash object created
+	by New, reading them from the sponge for XOFs
+*/
+
+// Default output sizes of the XOFs
+const (
+	shake128Size = 32
+	shake256Size = 64
+)
+
+// SHAKE sponge with a default output size, to be used as a hash.Hash
+type shake struct {
+	sha3.ShakeHash
+	size      int
+	blockSize int
+}
+
+func newShake(h Hasher) *shake {
+	switch h {
+	case SHAKE128:
+		return &shake{ShakeHash: sha3.NewShake128(), size: shake128Size, blockSize: 168}
+	case SHAKE256:
+		return &shake{ShakeHash: sha3.NewShake256(), size: shake256Size, blockSize: 136}
+	default:
+		return nil
+	}
+}
+
+// Append the default size output to b, without changing the state
+func (s *shake) Sum(b []byte) []byte {
+	out, dst := extendSlice(b, s.size)
+	_, _ = s.Clone().Read(dst)
+	return out
+}
+
+func (s *shake) Size() int {
+	return s.size
+}
+
+func (s *shake) BlockSize() int {
+	return s.blockSize
+}
+
+// Returns true if the hash function is an extendable output function
+func (h Hasher) XOF() bool {
+	return h == SHAKE128 || h == SHAKE256
+}
+
+// Returns a new XOF object
+// Returns nil if the hash function is not an XOF
+func (h Hasher) NewXOF() sha3.ShakeHash {
+	switch h {
+	case SHAKE128:
+		return sha3.NewShake128()
+	case SHAKE256:
+		return sha3.NewShake256()
+	default:
+		return nil
+	}
+}
+
+// Compute n bytes of hash of data
+// Fixed size hash functions return the first n bytes of the hash, and nil if n is larger than their size
+func (h Hasher) HashN(data []byte, n int) []byte {
+	if n < 0 {
+		return nil
+	}
+	if h.XOF() {
+		xof := h.NewXOF()
+		xof.Write(data)
+		out := make([]byte, n)
+		_, _ = xof.Read(out)
+		return out
+	}
+	if n > h.Size() {
+		return nil
+	}
+	return h.Hash(data)[:n]
+}
+
+// Append n bytes of the hash of the data written to hf to dst, like SumTo
+// For XOFs created by New, the n bytes are read from the sponge, so n can be
+// larger than hf.Size(). Otherwise, the first n bytes of the hash are appended,
+// and n must be at most hf.Size()
+// If dst has enough capacity for n bytes, or for hf.Size() bytes for fixed
+// size hash functions, no memory is allocated
+func SumN(hf hash.Hash, dst []byte, n int) []byte {
+	if s, ok := hf.(*shake); ok {
+		// Like SumTo, read from a copy of the sponge if memory is allocated anyway
+		var xof io.Reader = s
+		if cap(dst)-len(dst) < n {
+			xof = s.Clone()
+		}
+		out, buf := extendSlice(dst, n)
+		_, _ = xof.Read(buf)
+		return out
+	}
+	return SumTo(hf, dst)[:len(dst)+n]
+}
+
+// Extend b by n bytes, returning the extended slice and the new n bytes
+func extendSlice(b []byte, n int) ([]byte, []byte) {
+	size := len(b) + n
+	if cap(b) < size {
+		nb := make([]byte, len(b), size)
+		copy(nb, b)
+		b = nb
+	}
+	return b[:size], b[len(b):size]
+}
@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package hasher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Long and short outputs of "XX NETWORK", computed with Python hashlib shake_128 and shake_256
+var xofHashes = map[Hasher]string{
+	SHAKE128: "447eafad1a84058aa87b122b55d191adfa61d7f406bd24196d90cd29717ac2d572a33d4dee801d4f093fdfece9eb2369" +
+		"26cb107361f79a4e8b6e5f8c6380167ab3f582bf7c48c14a997fd4c48bb19aec34b8affe582202f066c03d544792bdb8e3c4117e",
+	SHAKE256: "6df1a53e65",
+}
+
+func TestHashType_XOF(t *testing.T) {
+	for i := Hasher(0); i < HashersLen+1; i++ {
+		xof := i.NewXOF()
+		if i.XOF() != (i == SHAKE128 || i == SHAKE256) {
+			t.Errorf("%s: Hasher.XOF() returned wrong value", i)
+		}
+		if (xof == nil) == i.XOF() {
+			t.Errorf("%s: Hasher.NewXOF() should return an object only for XOFs", i)
+		}
+	}
+}
+
+func TestHashType_HashN(t *testing.T) {
+	for h, str := range xofHashes {
+		ref, _ := hex.DecodeString(str)
+		if out := h.HashN(testData, len(ref)); !bytes.Equal(out, ref) {
+			t.Errorf("%s: Hasher.HashN() returned wrong hash! Got %x, expected %x", h, out, ref)
+		}
+	}
+
+	// Fixed size hash functions are truncated
+	ref, _ := hex.DecodeString(xxnetworkHashes[SHA3_256])
+	if out := SHA3_256.HashN(testData, 20); !bytes.Equal(out, ref[:20]) {
+		t.Errorf("Hasher.HashN() should return truncated hash for fixed size hash function! Got %x", out)
+	}
+	if SHA3_256.HashN(testData, 33) != nil || SHAKE128.HashN(testData, -1) != nil || HashersLen.HashN(testData, 1) != nil {
+		t.Errorf("Hasher.HashN() should return nil for invalid output sizes and unknown types!")
+	}
+}
+
+func TestSumN(t *testing.T) {
+	prefix := []byte("prefix")
+	for i := Hasher(0); i < HashersLen; i++ {
+		for _, n := range []int{1, i.Size(), 3 * i.Size()} {
+			if !i.XOF() && n > i.Size() {
+				continue
+			}
+			hf := i.New()
+			hf.Write(testData)
+			out := SumN(hf, append([]byte{}, prefix...), n)
+			if !bytes.Equal(out[:len(prefix)], prefix) || !bytes.Equal(out[len(prefix):], i.HashN(testData, n)) {
+				t.Errorf("%s: SumN() returned wrong output for %d bytes! Got %x", i, n, out)
+			}
+		}
+	}
+
+	// No allocations with enough capacity
+	hf := SHAKE256.New()
+	dst := make([]byte, 0, 200)
+	allocs := testing.AllocsPerRun(100, func() {
+		hf.Reset()
+		hf.Write(testData)
+		dst = SumN(hf, dst[:0], 200)
+	})
+	if allocs != 0 {
+		t.Errorf("SumN() should not allocate with enough capacity, got %v allocations", allocs)
+	}
+}
+
+func TestShake_Sum(t *testing.T) {
+	// Sum doesn't change the state of the sponge
+	hf := SHAKE128.New()
+	hf.Write(testData[:2])
+	first := hf.Sum(nil)
+	if !bytes.Equal(hf.Sum(nil), first) {
+		t.Errorf("Sum() should return the same output when called twice")
+	}
+	hf.Write(testData[2:])
+	if !bytes.Equal(hf.Sum(nil), SHAKE128.Hash(testData)) {
+		t.Errorf("Sum() should allow writing more data after it is called")
+	}
+
+	// SumN without enough capacity doesn't change the state either
+	if !bytes.Equal(SumN(hf, nil, 100), SHAKE128.HashN(testData, 100)) ||
+		!bytes.Equal(SumN(hf, nil, 100), SHAKE128.HashN(testData, 100)) {
+		t.Errorf("SumN() should return the same output when called twice without enough capacity")
+	}
+	if hf.BlockSize() != 168 || SHAKE256.New().BlockSize() != 136 {
+		t.Errorf("BlockSize() should return the rate of the sponge")
+	}
+}
@@ -0,0 +1,22 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+//go:build linux
+// +build linux
+
+package secmem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func lock(b []byte) error {
+	return unix.Mlock(b)
+}
+
+func unlock(b []byte) error {
+	return unix.Munlock(b)
+}
@@ -0,0 +1,18 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+//go:build !linux
+// +build !linux
+
+package secmem
+
+func lock(b []byte) error {
+	return ErrLockNotSupported
+}
+
+func unlock(b []byte) error {
+	return ErrLockNotSupported
+}
@@ -0,0 +1,61 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+// Package secmem provides helpers to handle memory holding secrets
+// Secrets are wiped as soon as they are no longer needed, and memory
+// can be locked to prevent it from being swapped to disk (Linux only)
+package secmem
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+)
+
+var ErrLockNotSupported = errors.New("memory locking is not supported on this platform")
+
+// Overwrite the slice with zeros
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	// Make sure the writes are not optimized away
+	runtime.KeepAlive(b)
+}
+
+// Overwrite all the slices with zeros
+func WipeAll(bs [][]byte) {
+	for _, b := range bs {
+		Wipe(b)
+	}
+}
+
+// Overwrite the internal representation of the big integer with zeros
+func WipeBigInt(x *big.Int) {
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+	runtime.KeepAlive(words)
+}
+
+// Lock the memory of the slice, preventing it from being swapped to disk
+// Returns ErrLockNotSupported if the platform doesn't support it
+func Lock(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return lock(b)
+}
+
+// Unlock memory previously locked with Lock
+func Unlock(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return unlock(b)
+}
@@ -0,0 +1,72 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package secmem
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"runtime"
+	"testing"
+)
+
+func TestWipe(t *testing.T) {
+	b := make([]byte, 64)
+	_, _ = rand.Read(b)
+	Wipe(b)
+
+	if !bytes.Equal(b, make([]byte, 64)) {
+		t.Fatalf("Wipe() didn't zero the slice")
+	}
+
+	bs := [][]byte{{1, 2, 3}, {4, 5}}
+	WipeAll(bs)
+
+	if !bytes.Equal(bs[0], []byte{0, 0, 0}) || !bytes.Equal(bs[1], []byte{0, 0}) {
+		t.Fatalf("WipeAll() didn't zero all slices")
+	}
+
+	x := new(big.Int).SetBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	words := x.Bits()
+	WipeBigInt(x)
+
+	if x.Sign() != 0 {
+		t.Fatalf("WipeBigInt() didn't set the integer to 0")
+	}
+
+	for _, w := range words[:cap(words)] {
+		if w != 0 {
+			t.Fatalf("WipeBigInt() didn't zero the integer words")
+		}
+	}
+}
+
+func TestLock(t *testing.T) {
+	// Empty slices are always fine
+	if Lock(nil) != nil || Unlock(nil) != nil {
+		t.Fatalf("Lock() and Unlock() shouldn't return error for empty slices")
+	}
+
+	b := make([]byte, 32)
+	err := Lock(b)
+
+	if runtime.GOOS != "linux" {
+		if err != ErrLockNotSupported {
+			t.Fatalf("Lock() should return ErrLockNotSupported on %s", runtime.GOOS)
+		}
+		return
+	}
+
+	// Locking may be forbidden by resource limits
+	if err != nil {
+		t.Skipf("Lock() returned error, memory locking is probably limited: %s", err)
+	}
+
+	if err = Unlock(b); err != nil {
+		t.Fatalf("Unlock() returned error: %s", err)
+	}
+}
@@ -0,0 +1,334 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package kat
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/wallet"
+	"github.com/xx-labs/sleeve/wots"
+)
+
+///////////////////////////////////////////////////////////////////////
+// KNOWN ANSWER TESTS
+/*
+	Known answer test (KAT) vectors fix the outputs of this implementation
+	for given inputs, so that other implementations, e.g., ports to other
+	languages, can prove they match it byte for byte
+
+	Generate creates a vector file with:
+	  WOTS:   one vector for each registered params set, with the secret
+	          and public seeds, a message, the public key and the signature
+	  Sleeve: one vector for each Sleeve security level, with the entropy,
+	          passphrase and account, the Sleeve mnemonic, derivation path,
+	          sleeve secret key, WOTS+ public key, output mnemonic, xx network
+	          and testnet addresses, and a signature with the WOTS+ key
+	All inputs are derived from the name of the vector, i.e., the params
+	name for WOTS+ vectors and "Sleeve " || params name for Sleeve vectors,
+	so the same vectors are generated every time:
+	  input = SHA3_256(Label || name || field)
+	The Sleeve passphrase is the hex encoding of the first 8 bytes of its
+	input, the account is the security level, and byte fields are hex
+	encoded in JSON
+
+	Check loads a vector file, e.g., generated by another implementation,
+	recomputes every vector with this implementation, and returns all
+	the fields that don't match
+*/
+
+// Version of the vector file format
+const Version = 1
+
+// Label of the derivation of the vector inputs
+const Label = "xx network sleeve KAT"
+
+// A KAT vector file
+type File struct {
+	Version int            `json:"Version"`
+	WOTS    []WOTSVector   `json:"WOTS"`
+	Sleeve  []SleeveVector `json:"Sleeve"`
+}
+
+// A WOTS+ vector
+type WOTSVector struct {
+	Params     string `json:"Params"`
+	Encoding   uint8  `json:"Encoding"`
+	Seed       string `json:"Seed"`
+	PublicSeed string `json:"PublicSeed"`
+	Message    string `json:"Message"`
+	PublicKey  string `json:"PublicKey"`
+	Signature  string `json:"Signature"`
+}
+
+// A Sleeve wallet vector
+type SleeveVector struct {
+	Params           string `json:"Params"`
+	Encoding         uint8  `json:"Encoding"`
+	Entropy          string `json:"Entropy"`
+	Passphrase       string `json:"Passphrase"`
+	Account          uint32 `json:"Account"`
+	Mnemonic         string `json:"QuantumPhrase"`
+	Path             string `json:"DerivationPath"`
+	SecretKey        string `json:"SleeveSecretKey"`
+	QuantumPublicKey string `json:"QuantumPublicKey"`
+	Output           string `json:"StandardPhrase"`
+	Address          string `json:"Address"`
+	TestnetAddress   string `json:"TestnetAddress"`
+	Message          string `json:"Message"`
+	Signature        string `json:"Signature"`
+}
+
+// A field of a vector that doesn't match this implementation
+type Mismatch struct {
+	// Section and index of the vector in the file
+	Section string
+	Index   int
+	// Name of the vector and field
+	Name  string
+	Field string
+	// Value computed by this implementation, and value in the file
+	Expected string
+	Got      string
+}
+
+func (m Mismatch) Error() string {
+	return fmt.Sprintf("%s vector %d (%s): %s doesn't match. Expected %s, got %s",
+		m.Section, m.Index, m.Name, m.Field, m.Expected, m.Got)
+}
+
+// Sleeve security levels, see sleevage
+var sleeveLevels = []wots.ParamsEncoding{wots.Level0, wots.Level1, wots.Level2, wots.Level3}
+
+///////////////////////////////////////////////////////////////////////
+// GENERATION
+
+// Generate the vectors for all registered params sets and Sleeve security levels
+func Generate() (*File, error) {
+	f := &File{Version: Version}
+
+	// 1. WOTS+ vectors
+	for _, info := range wots.RegisteredParams() {
+		f.WOTS = append(f.WOTS, wotsVector(info.Encoding, info.Name))
+	}
+
+	// 2. Sleeve vectors
+	for i, enc := range sleeveLevels {
+		v, err := sleeveVector(enc, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		f.Sleeve = append(f.Sleeve, v)
+	}
+	return f, nil
+}
+
+// Derive the input field of the named vector
+func derive(name, field string) []byte {
+	h := hasher.SHA3_256.New()
+	h.Write([]byte(Label))
+	h.Write([]byte(name))
+	h.Write([]byte(field))
+	return h.Sum(nil)
+}
+
+// Create the WOTS+ vector of the registered params
+func wotsVector(enc wots.ParamsEncoding, name string) WOTSVector {
+	v := WOTSVector{
+		Params:     name,
+		Encoding:   uint8(enc),
+		Seed:       hex.EncodeToString(derive(name, "seed")),
+		PublicSeed: hex.EncodeToString(derive(name, "public seed")),
+		Message:    hex.EncodeToString(derive(name, "message")),
+	}
+	v.PublicKey, v.Signature = computeWOTS(enc, derive(name, "seed"), derive(name, "public seed"), derive(name, "message"))
+	return v
+}
+
+// Compute the public key and signature of the message, hex encoded
+func computeWOTS(enc wots.ParamsEncoding, seed, pSeed, msg []byte) (string, string) {
+	key := wots.NewKeyFromSeed(wots.DecodeParams(enc), seed, pSeed)
+	return hex.EncodeToString(key.ComputePK()), hex.EncodeToString(key.Sign(msg))
+}
+
+// Create the Sleeve vector of the security level, using the level as account
+func sleeveVector(enc wots.ParamsEncoding, account uint32) (SleeveVector, error) {
+	name := "Sleeve " + enc.String()
+	v := SleeveVector{
+		Params:     enc.String(),
+		Encoding:   uint8(enc),
+		Entropy:    hex.EncodeToString(derive(name, "entropy")),
+		Passphrase: hex.EncodeToString(derive(name, "passphrase")[:8]),
+		Account:    account,
+		Message:    hex.EncodeToString(derive(name, "message")),
+	}
+	if err := computeSleeve(&v, derive(name, "entropy"), derive(name, "message")); err != nil {
+		return SleeveVector{}, err
+	}
+	return v, nil
+}
+
+// Compute the output fields of the Sleeve vector from its inputs
+func computeSleeve(v *SleeveVector, ent, msg []byte) error {
+	// 1. Generate Sleeve
+	spec := wallet.NewGenSpec(v.Account, wots.ParamsEncoding(v.Encoding))
+	sleeve, err := wallet.NewSleeveFromEntropy(ent, v.Passphrase, spec)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error generating sleeve for %s: %s", v.Params, err))
+	}
+	defer sleeve.Destroy()
+	path, err := spec.PathFromSpec()
+	if err != nil {
+		return errors.New(fmt.Sprintf("error computing path for %s: %s", v.Params, err))
+	}
+
+	// 2. Fill in output fields
+	v.Mnemonic = sleeve.GetMnemonic()
+	v.Path = path.String()
+	v.SecretKey = hex.EncodeToString(sleeve.GetSleeveSecretKey())
+	v.QuantumPublicKey = hex.EncodeToString(sleeve.GetQuantumPublicKey())
+	v.Output = sleeve.GetOutputMnemonic()
+	v.Address = wallet.XXNetworkAddressFromMnemonic(v.Output)
+	v.TestnetAddress = wallet.TestnetAddressFromMnemonic(v.Output)
+	sig, err := sleeve.SignQuantum(msg)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error signing with sleeve for %s: %s", v.Params, err))
+	}
+	v.Signature = hex.EncodeToString(sig)
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// CONFORMANCE
+
+// Parse a vector file
+func Parse(data []byte) (*File, error) {
+	f := new(File)
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	if f.Version != Version {
+		return nil, errors.New(fmt.Sprintf("unsupported KAT vector file version %d, expected %d", f.Version, Version))
+	}
+	return f, nil
+}
+
+// Recompute all the vectors of the file, and return the fields that don't match
+// Returns an error if a vector can't be recomputed, e.g., its params are unknown
+func Check(f *File) ([]Mismatch, error) {
+	var mismatches []Mismatch
+	for i, v := range f.WOTS {
+		m, err := checkWOTS(i, v)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m...)
+	}
+	for i, v := range f.Sleeve {
+		m, err := checkSleeve(i, v)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m...)
+	}
+	return mismatches, nil
+}
+
+// Recompute the WOTS+ vector with the given inputs
+func checkWOTS(index int, v WOTSVector) ([]Mismatch, error) {
+	// 1. Get params
+	enc := wots.ParamsEncoding(v.Encoding)
+	info, ok := wots.LookupParams(enc)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("WOTS vector %d (%s): unknown params encoding %d", index, v.Params, v.Encoding))
+	}
+
+	// 2. Decode inputs
+	seed, err := decodeInput("WOTS", index, "Seed", v.Seed)
+	if err != nil {
+		return nil, err
+	}
+	pSeed, err := decodeInput("WOTS", index, "PublicSeed", v.PublicSeed)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := decodeInput("WOTS", index, "Message", v.Message)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != wots.SeedSize || len(pSeed) != wots.SeedSize {
+		return nil, errors.New(fmt.Sprintf("WOTS vector %d (%s): seeds must have %d bytes", index, v.Params, wots.SeedSize))
+	}
+
+	// 3. Compare outputs
+	pk, sig := computeWOTS(enc, seed, pSeed, msg)
+	return compare("WOTS", index, v.Params, []string{"Params", "PublicKey", "Signature"},
+		[]string{info.Name, pk, sig}, []string{v.Params, v.PublicKey, v.Signature}), nil
+}
+
+// Recompute the Sleeve vector with the given inputs
+func checkSleeve(index int, v SleeveVector) ([]Mismatch, error) {
+	// 1. Get params
+	enc := wots.ParamsEncoding(v.Encoding)
+	info, ok := wots.LookupParams(enc)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Sleeve vector %d (%s): unknown params encoding %d", index, v.Params, v.Encoding))
+	}
+
+	// 2. Decode inputs
+	ent, err := decodeInput("Sleeve", index, "Entropy", v.Entropy)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := decodeInput("Sleeve", index, "Message", v.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Compare outputs
+	exp := v
+	exp.Params = info.Name
+	if err = computeSleeve(&exp, ent, msg); err != nil {
+		return nil, errors.New(fmt.Sprintf("Sleeve vector %d: %s", index, err))
+	}
+	fields := []string{"Params", "QuantumPhrase", "DerivationPath", "SleeveSecretKey", "QuantumPublicKey",
+		"StandardPhrase", "Address", "TestnetAddress", "Signature"}
+	return compare("Sleeve", index, v.Params, fields,
+		[]string{exp.Params, exp.Mnemonic, exp.Path, exp.SecretKey, exp.QuantumPublicKey,
+			exp.Output, exp.Address, exp.TestnetAddress, exp.Signature},
+		[]string{v.Params, v.Mnemonic, v.Path, v.SecretKey, v.QuantumPublicKey,
+			v.Output, v.Address, v.TestnetAddress, v.Signature}), nil
+}
+
+// Decode a hex input field of a vector
+func decodeInput(section string, index int, field, value string) ([]byte, error) {
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("%s vector %d: invalid %s hex: %s", section, index, field, err))
+	}
+	return data, nil
+}
+
+// Compare the output fields computed by this implementation with the ones in the file
+func compare(section string, index int, name string, fields []string, expected, got []string) []Mismatch {
+	var mismatches []Mismatch
+	for i, field := range fields {
+		if expected[i] != got[i] {
+			mismatches = append(mismatches, Mismatch{
+				Section:  section,
+				Index:    index,
+				Name:     name,
+				Field:    field,
+				Expected: expected[i],
+				Got:      got[i],
+			})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,121 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package kat
+
+import (
+	"encoding/json"
+	"github.com/xx-labs/sleeve/wots"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+const vectorFile = "testdata/vectors.json"
+
+func readVectors(t *testing.T) *File {
+	data, err := ioutil.ReadFile(vectorFile)
+	if err != nil {
+		t.Fatalf("error reading vector file: %s", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() returned error for vector file: %s", err)
+	}
+	return f
+}
+
+func TestGenerate(t *testing.T) {
+	f, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() returned error: %s", err)
+	}
+
+	if len(f.WOTS) != len(wots.RegisteredParams()) || len(f.Sleeve) != len(sleeveLevels) {
+		t.Fatalf("Generate() should return a vector for each registered params and Sleeve security level")
+	}
+
+	// Vectors must not change, regenerate the file with make kat if params are registered
+	if !reflect.DeepEqual(f, readVectors(t)) {
+		t.Fatalf("Generate() returned vectors different from %s", vectorFile)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte("{")); err == nil {
+		t.Fatalf("Parse() should return error for invalid JSON")
+	}
+
+	data, _ := json.Marshal(File{Version: Version + 1})
+	if _, err := Parse(data); err == nil {
+		t.Fatalf("Parse() should return error for unsupported version")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := readVectors(t)
+	mismatches, err := Check(f)
+	if err != nil {
+		t.Fatalf("Check() returned error for vector file: %s", err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("Check() should return no mismatches for vector file, got %v", mismatches)
+	}
+}
+
+func TestCheck_Mismatch(t *testing.T) {
+	f := readVectors(t)
+	f.WOTS[1].Signature = f.WOTS[0].Signature
+	f.Sleeve[2].Address = f.Sleeve[2].TestnetAddress
+	f.Sleeve[3].Passphrase = "wrong"
+
+	mismatches, err := Check(f)
+	if err != nil {
+		t.Fatalf("Check() returned error: %s", err)
+	}
+
+	// Wrong passphrase changes every Sleeve output except the quantum phrase and path
+	if len(mismatches) != 2+6 {
+		t.Fatalf("Check() returned wrong number of mismatches: %v", mismatches)
+	}
+	if m := mismatches[0]; m.Section != "WOTS" || m.Index != 1 || m.Field != "Signature" || m.Got != f.WOTS[0].Signature {
+		t.Fatalf("Check() returned wrong WOTS mismatch: %v", m)
+	}
+	if m := mismatches[1]; m.Section != "Sleeve" || m.Index != 2 || m.Field != "Address" {
+		t.Fatalf("Check() returned wrong Sleeve mismatch: %v", m)
+	}
+	for _, m := range mismatches[2:] {
+		if m.Index != 3 {
+			t.Fatalf("Check() returned mismatch for wrong Sleeve vector: %v", m)
+		}
+	}
+}
+
+func TestCheck_Errors(t *testing.T) {
+	f := readVectors(t)
+	f.WOTS[0].Encoding = uint8(wots.ParamsEncodingLen)
+	if _, err := Check(f); err == nil {
+		t.Fatalf("Check() should return error for unknown params")
+	}
+
+	f = readVectors(t)
+	f.WOTS[0].Seed = "zz"
+	if _, err := Check(f); err == nil {
+		t.Fatalf("Check() should return error for invalid hex")
+	}
+
+	f = readVectors(t)
+	f.WOTS[0].Seed = f.WOTS[0].Seed[:2]
+	if _, err := Check(f); err == nil {
+		t.Fatalf("Check() should return error for seed with wrong size")
+	}
+
+	f = readVectors(t)
+	f.Sleeve[0].Entropy = f.Sleeve[0].Entropy[:32]
+	if _, err := Check(f); err == nil {
+		t.Fatalf("Check() should return error for entropy with wrong size")
+	}
+}
@@ -0,0 +1,236 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2021 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/xx-labs/sleeve/wots/analysis"
+	"io/ioutil"
+	"strings"
+)
+
+// Analyze flags
+var analyzePK string
+var analyzeLeaks []string
+var analyzeLeaksFile string
+var analyzeTarget string
+var analyzeSample uint32
+var analyzeTries uint32
+
+// analyzeCmd represents the analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "analyze the damage of a WOTS+ key that signed more than once",
+	Long: `A WOTS+ key must only sign once. When it signs more messages, the
+lowest signed position of each ladder is exposed, and messages whose
+positions are all at or above the exposed ones can be forged.
+
+analyze computes the exposed positions from the leaked signatures of a
+WOTS+ public key, and tests if a target message, or a sample of random
+messages, can be forged.
+
+Leaked signatures are given as <message hex>:<signature hex>, either with
+--leak, or one per line in the file given by --leaks-file.
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !checkArgs() {
+			return
+		}
+		an, err := analyze()
+		if err != nil {
+			fmt.Printf("Error analyzing signatures: %s\n", err.Error())
+			return
+		}
+		handleAnalysisOutput(an)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().StringVar(&analyzePK, "pk", "", "specify the WOTS+ public key, in hex")
+	analyzeCmd.Flags().StringArrayVarP(&analyzeLeaks, "leak", "l", nil, "specify a leaked signature as <message hex>:<signature hex>. Can be repeated")
+	analyzeCmd.Flags().StringVar(&analyzeLeaksFile, "leaks-file", "", "specify the leaked signatures from a file, one <message hex>:<signature hex> per line")
+	analyzeCmd.Flags().StringVar(&analyzeTarget, "target", "", "specify a target message to forge, in hex")
+	analyzeCmd.Flags().Uint32Var(&analyzeSample, "sample", 0, "specify the number of random messages to forge")
+	analyzeCmd.Flags().Uint32Var(&analyzeTries, "tries", 1, "specify the number of tries per message, for params where the forger chooses the randomizer or counter")
+}
+
+type ForgeryJson struct {
+	Message   string `json:"Message"`
+	Forgeable bool   `json:"Forgeable"`
+	Tries     int    `json:"Tries"`
+	Blocked   []int  `json:"BlockedLadders"`
+}
+
+type SampleJson struct {
+	Messages  int     `json:"Messages"`
+	Forgeable int     `json:"Forgeable"`
+	Rate      float64 `json:"Rate"`
+}
+
+type AnalysisJson struct {
+	Params     string       `json:"Params"`
+	Signatures int          `json:"Signatures"`
+	Exposed    []int        `json:"ExposedPositions"`
+	Grindable  bool         `json:"Grindable"`
+	Target     *ForgeryJson `json:"Target,omitempty"`
+	Sample     *SampleJson  `json:"Sample,omitempty"`
+}
+
+func (a AnalysisJson) String() string {
+	str := fmt.Sprintf("params: %s\n", a.Params)
+	str += fmt.Sprintf("signatures: %d\n", a.Signatures)
+	str += fmt.Sprintf("exposed positions: %v", a.Exposed)
+	if a.Grindable {
+		str += fmt.Sprintf("\nforger chooses the randomizer or counter: each message can be tried many times")
+	}
+	if a.Target != nil {
+		if a.Target.Forgeable {
+			str += fmt.Sprintf("\ntarget message %s: FORGEABLE after %d tries", a.Target.Message, a.Target.Tries)
+		} else {
+			str += fmt.Sprintf("\ntarget message %s: not forgeable in %d tries, blocked ladders: %v",
+				a.Target.Message, a.Target.Tries, a.Target.Blocked)
+		}
+	}
+	if a.Sample != nil {
+		str += fmt.Sprintf("\nrandom messages: %d of %d forgeable (%.4f%%)",
+			a.Sample.Forgeable, a.Sample.Messages, 100*a.Sample.Rate)
+	}
+	return str
+}
+
+// Parse a leaked signature given as <message hex>:<signature hex>
+func parseLeak(str string) (analysis.Leak, error) {
+	parts := strings.Split(strings.TrimSpace(str), ":")
+	if len(parts) != 2 {
+		return analysis.Leak{}, errors.New(fmt.Sprintf("invalid leaked signature %q: should be <message hex>:<signature hex>", str))
+	}
+	msg, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return analysis.Leak{}, errors.New(fmt.Sprintf("invalid message hex: %s", err))
+	}
+	sig, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return analysis.Leak{}, errors.New(fmt.Sprintf("invalid signature hex: %s", err))
+	}
+	return analysis.Leak{Msg: msg, Signature: sig}, nil
+}
+
+func getLeaks() ([]analysis.Leak, error) {
+	lines := analyzeLeaks
+	// Read leaks from file if specified
+	if analyzeLeaksFile != "" {
+		val, err := ioutil.ReadFile(analyzeLeaksFile)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("error opening leaks file: %s", err))
+		}
+		for _, line := range strings.Split(string(val), "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	leaks := make([]analysis.Leak, len(lines))
+	for i, line := range lines {
+		leak, err := parseLeak(line)
+		if err != nil {
+			return nil, err
+		}
+		leaks[i] = leak
+	}
+	return leaks, nil
+}
+
+func analyze() (AnalysisJson, error) {
+	// 1. Parse input
+	pk, err := hex.DecodeString(analyzePK)
+	if err != nil {
+		return AnalysisJson{}, errors.New(fmt.Sprintf("invalid public key hex: %s", err))
+	}
+	leaks, err := getLeaks()
+	if err != nil {
+		return AnalysisJson{}, err
+	}
+
+	// 2. Compute exposed positions
+	e, err := analysis.Analyze(pk, leaks)
+	if err != nil {
+		return AnalysisJson{}, err
+	}
+	an := AnalysisJson{
+		Params:     e.Encoding().String(),
+		Signatures: e.Signatures(),
+		Exposed:    e.Positions(),
+		Grindable:  e.Grindable(),
+	}
+
+	// 3. Test target message
+	if analyzeTarget != "" {
+		msg, err := hex.DecodeString(analyzeTarget)
+		if err != nil {
+			return AnalysisJson{}, errors.New(fmt.Sprintf("invalid target message hex: %s", err))
+		}
+		forgery, err := e.TestMessage(msg, int(analyzeTries), rand.Reader)
+		if err != nil {
+			return AnalysisJson{}, err
+		}
+		an.Target = &ForgeryJson{
+			Message:   analyzeTarget,
+			Forgeable: forgery.Forgeable,
+			Tries:     forgery.Tries,
+			Blocked:   forgery.Blocked,
+		}
+	}
+
+	// 4. Test random messages
+	if analyzeSample > 0 {
+		sample, err := e.Sample(int(analyzeSample), int(analyzeTries), rand.Reader)
+		if err != nil {
+			return AnalysisJson{}, err
+		}
+		an.Sample = &SampleJson{
+			Messages:  sample.Messages,
+			Forgeable: sample.Forgeable,
+			Rate:      sample.Rate(),
+		}
+	}
+	return an, nil
+}
+
+func handleAnalysisOutput(an AnalysisJson) {
+	// Get output according to type
+	var out []byte
+	var err error
+	switch outputType {
+	case "text":
+		out = []byte(an.String())
+	case "json":
+		out, err = json.MarshalIndent(an, "", "  ")
+		if err != nil {
+			panic(fmt.Sprintf("error marshalling analysis to json: %s", err))
+		}
+	default:
+		// noop
+	}
+	// If an output file was specified, write output to file
+	if outputFile != "" {
+		err = ioutil.WriteFile(outputFile, out, 0400)
+		if err != nil {
+			panic(fmt.Sprintf("error writing analysis to file: %s", err))
+		}
+	} else {
+		// Write to stdout
+		fmt.Println(string(out))
+	}
+}
@@ -0,0 +1,101 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2021 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/xx-labs/sleeve/kat"
+	"io/ioutil"
+	"os"
+)
+
+// KAT flags
+var katCheckFile string
+
+// katCmd represents the kat command
+var katCmd = &cobra.Command{
+	Use:   "kat",
+	Short: "generate or check known answer test vectors",
+	Long: `Known answer test vectors fix the outputs of this implementation for
+given inputs, so that other implementations can prove they match it byte
+for byte.
+
+When no arguments are provided, kat generates the JSON vectors of all
+WOTS+ params and Sleeve security levels. With --check, kat recomputes the
+vectors of the given file, e.g., generated by another implementation, and
+reports every field that doesn't match.
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if katCheckFile != "" {
+			if !checkKAT() {
+				os.Exit(1)
+			}
+			return
+		}
+		generateKAT()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(katCmd)
+
+	katCmd.Flags().StringVar(&katCheckFile, "check", "", "specify a vector file to check against this implementation")
+}
+
+func generateKAT() {
+	f, err := kat.Generate()
+	if err != nil {
+		fmt.Printf("Error generating KAT vectors: %s\n", err.Error())
+		return
+	}
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("error marshalling KAT vectors to json: %s", err))
+	}
+	// If an output file was specified, write output to file
+	if outputFile != "" {
+		err = ioutil.WriteFile(outputFile, append(out, '\n'), 0644)
+		if err != nil {
+			panic(fmt.Sprintf("error writing KAT vectors to file: %s", err))
+		}
+		fmt.Printf("Wrote %d WOTS+ and %d Sleeve vectors to %s\n", len(f.WOTS), len(f.Sleeve), outputFile)
+	} else {
+		// Write to stdout
+		fmt.Println(string(out))
+	}
+}
+
+// Check the vector file, returning true if all vectors match
+func checkKAT() bool {
+	data, err := ioutil.ReadFile(katCheckFile)
+	if err != nil {
+		fmt.Printf("Error opening vector file: %s\n", err.Error())
+		return false
+	}
+	f, err := kat.Parse(data)
+	if err != nil {
+		fmt.Printf("Error parsing vector file: %s\n", err.Error())
+		return false
+	}
+	mismatches, err := kat.Check(f)
+	if err != nil {
+		fmt.Printf("Error checking vectors: %s\n", err.Error())
+		return false
+	}
+	for _, m := range mismatches {
+		fmt.Println(m.Error())
+	}
+	if len(mismatches) > 0 {
+		fmt.Printf("FAIL: %d mismatched fields in %d WOTS+ and %d Sleeve vectors\n", len(mismatches), len(f.WOTS), len(f.Sleeve))
+		return false
+	}
+	fmt.Printf("OK: %d WOTS+ and %d Sleeve vectors match\n", len(f.WOTS), len(f.Sleeve))
+	return true
+}
@@ -32,6 +32,7 @@ var passphraseFile string
 var outputFile string
 var outputType string
 var testnet bool
+var showSecretKey bool
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -89,6 +90,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&outputFile, "output","o", "", "output file. Defaults to stdout. When specified, only address is shown on stdout")
 	rootCmd.PersistentFlags().StringVarP(&outputType, "output-type","t", "text", "output type. One of [text, json]")
 	rootCmd.PersistentFlags().BoolVar(&testnet, "testnet",  false, "generate testnet address")
+	rootCmd.PersistentFlags().BoolVar(&showSecretKey, "secret-key", false, "include the sleeve secret key in the output. WARNING: revealing it links the quantum public key to the standard wallet")
 }
 
 func checkArgs() bool {
@@ -8,6 +8,7 @@ package cmd
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/xx-labs/sleeve/wallet"
@@ -29,6 +30,8 @@ type SleeveJson struct {
 	Path          string               `json:"DerivationPath"`
 	Standard      string               `json:"StandardPhrase"`
 	Address       string               `json:"Address"`
+	QuantumPK     string               `json:"QuantumPublicKey"`
+	SecretKey     string               `json:"SleeveSecretKey,omitempty"`
 	StandardDeriv []StandardDerivation `json:"StandardDerivations"`
 }
 
@@ -37,7 +40,11 @@ func (s SleeveJson) String() string {
 	str += fmt.Sprintf("passphrase: %s\n", s.Pass)
 	str += fmt.Sprintf("path: %s\n", s.Path)
 	str += fmt.Sprintf("standard recovery phrase: %s\n", s.Standard)
-	str += fmt.Sprintf("address: %s", s.Address)
+	str += fmt.Sprintf("address: %s\n", s.Address)
+	str += fmt.Sprintf("quantum public key: %s", s.QuantumPK)
+	if s.SecretKey != "" {
+		str += fmt.Sprintf("\nsleeve secret key: %s", s.SecretKey)
+	}
 	if s.StandardDeriv != nil {
 		str += fmt.Sprintf("\nstandard derived addresses:\n")
 		for _, addr := range s.StandardDeriv {
@@ -141,12 +148,18 @@ func getJson(path string, sleeve *wallet.Sleeve) SleeveJson {
 			}
 		}
 	}
+	secretKey := ""
+	if showSecretKey {
+		secretKey = hex.EncodeToString(sleeve.GetSleeveSecretKey())
+	}
 	return SleeveJson{
 		Quantum:  sleeve.GetMnemonic(),
 		Pass:     passphrase,
 		Path:     path,
 		Standard: sleeve.GetOutputMnemonic(),
 		Address:  getAddress(sleeve),
+		QuantumPK: hex.EncodeToString(sleeve.GetQuantumPublicKey()),
+		SecretKey: secretKey,
 		StandardDeriv: derivs,
 	}
 }
@@ -0,0 +1,95 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"encoding/binary"
+	"github.com/xx-labs/sleeve/hasher"
+	"hash"
+)
+
+///////////////////////////////////////////////////////////////////////
+// ADDRESSES
+/*
+	Every hash computed by the scheme is domain separated by a 32 byte
+	address, composed of big endian words:
+	  Layer,         4 bytes
+	  Tree,          8 bytes
+	  Type,          4 bytes
+	  Key pair,      4 bytes
+	  Height,        4 bytes
+	  Index,         4 bytes
+	  Padding,       4 bytes
+
+	The key pair is the leaf of the tree that the address belongs to,
+	and height and index identify a node inside a tree
+*/
+type address [32]byte
+
+// Address types
+const (
+	addrTree uint32 = iota
+	addrWotsSeed
+	addrWotsPSeed
+	addrForsTree
+	addrForsRoots
+	addrForsPRF
+	addrMsgPRF
+)
+
+func (a *address) setLayer(l uint32) {
+	binary.BigEndian.PutUint32(a[0:4], l)
+}
+
+func (a *address) setTree(t uint64) {
+	binary.BigEndian.PutUint64(a[4:12], t)
+}
+
+// Set the type, clearing the type specific words
+func (a *address) setType(typ uint32) {
+	binary.BigEndian.PutUint32(a[12:16], typ)
+	for i := 16; i < len(a); i++ {
+		a[i] = 0
+	}
+}
+
+func (a *address) setKeyPair(kp uint32) {
+	binary.BigEndian.PutUint32(a[16:20], kp)
+}
+
+func (a *address) setHeight(h uint32) {
+	binary.BigEndian.PutUint32(a[20:24], h)
+}
+
+func (a *address) setIndex(i uint32) {
+	binary.BigEndian.PutUint32(a[24:28], i)
+}
+
+///////////////////////////////////////////////////////////////////////
+// HASH FUNCTIONS
+
+// Tweakable hash: H(pSeed || ADRS || msg...), truncated to n bytes
+// The output is appended to dst
+func (p *Params) thash(dst []byte, h hash.Hash, pSeed []byte, a *address, msg ...[]byte) []byte {
+	h.Reset()
+	h.Write(pSeed)
+	h.Write(a[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	l := len(dst)
+	return hasher.SumTo(h, dst)[:l+p.n]
+}
+
+// PRF: H(seed || ADRS), full output of the hash function
+// The output is appended to dst
+func prf(dst []byte, h hash.Hash, seed []byte, a *address) []byte {
+	h.Reset()
+	h.Write(seed)
+	h.Write(a[:])
+	return hasher.SumTo(h, dst)
+}
@@ -0,0 +1,98 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"hash"
+)
+
+///////////////////////////////////////////////////////////////////////
+// FORS
+/*
+	Forest Of Random Subsets (FORS) is a few-time signature scheme,
+	composed by k Merkle trees of height a. Each leaf of a tree is the
+	hash of an n byte secret key:
+	  sk   = PRF(SEED, ADRS)[:n]
+	  leaf = T(PSEED, ADRS, sk)
+
+	A message of k*a bits is signed by revealing, for each tree, the
+	secret key of the leaf indexed by the corresponding a bits, together
+	with its authentication path. The FORS public key is the hash of
+	the k roots, which is then signed by the hypertree
+
+	Each leaf of the bottom layer of the hypertree has its own FORS key,
+	identified by the tree and key pair (leaf) address fields. Leaves of
+	tree t have indexes t*2^a to (t+1)*2^a - 1
+*/
+
+// Get the FORS address of the given type, for the hypertree tree and leaf
+func forsAddress(typ uint32, tree uint64, leaf uint32) *address {
+	var a address
+	a.setTree(tree)
+	a.setType(typ)
+	a.setKeyPair(leaf)
+	return &a
+}
+
+// Sign the FORS indexes with the FORS key of the hypertree tree and leaf
+// The signature is appended to out, and the FORS public key is returned
+func (k *Key) forsSign(out []byte, h hash.Hash, indexes []uint32, tree uint64, leaf uint32) ([]byte, []byte) {
+	p := k.params
+	skAddr := forsAddress(addrForsPRF, tree, leaf)
+	treeAddr := forsAddress(addrForsTree, tree, leaf)
+	roots := make([]byte, 0, p.k*p.n)
+	leaves := make([][]byte, 1<<uint(p.a))
+	for t, idx := range indexes {
+		offset := uint32(t) << uint(p.a)
+
+		// 1. Compute the leaves of the tree, appending the secret key of the index
+		for j := range leaves {
+			skAddr.setIndex(offset + uint32(j))
+			prfOut := prf(nil, h, k.seed, skAddr)
+			sk := prfOut[:p.n]
+			if uint32(j) == idx {
+				out = append(out, sk...)
+			}
+			treeAddr.setHeight(0)
+			treeAddr.setIndex(offset + uint32(j))
+			leaves[j] = p.thash(nil, h, k.pSeed, treeAddr, sk)
+			secmem.Wipe(prfOut)
+		}
+
+		// 2. Append the authentication path and keep the root
+		root, auth := p.treeRoot(h, k.pSeed, treeAddr, leaves, idx, offset)
+		out = append(out, auth...)
+		roots = append(roots, root...)
+	}
+
+	// 3. Compress the roots into the FORS public key
+	return out, p.thash(nil, h, k.pSeed, forsAddress(addrForsRoots, tree, leaf), roots)
+}
+
+// Compute the FORS public key from a FORS signature of the indexes
+// The signature must have the correct size
+func (p *Params) forsPK(h hash.Hash, pSeed, signature []byte, indexes []uint32, tree uint64, leaf uint32) []byte {
+	treeAddr := forsAddress(addrForsTree, tree, leaf)
+	roots := make([]byte, 0, p.k*p.n)
+	size := (p.a + 1) * p.n
+	for t, idx := range indexes {
+		offset := uint32(t) << uint(p.a)
+		sig := signature[t*size : (t+1)*size]
+
+		// 1. Compute the leaf from the secret key
+		treeAddr.setHeight(0)
+		treeAddr.setIndex(offset + idx)
+		node := p.thash(nil, h, pSeed, treeAddr, sig[:p.n])
+
+		// 2. Climb the tree using the authentication path
+		roots = append(roots, p.climb(h, pSeed, treeAddr, node, sig[p.n:], idx, offset)...)
+	}
+
+	// 3. Compress the roots into the FORS public key
+	return p.thash(nil, h, pSeed, forsAddress(addrForsRoots, tree, leaf), roots)
+}
@@ -0,0 +1,42 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFors_SignAndPK(t *testing.T) {
+	params := testParams()
+	key := NewKeyFromSeed(params, getRandData(t, SeedSize), getRandData(t, SeedSize))
+	h := params.hash.Get()
+	defer params.hash.Put(h)
+
+	indexes := []uint32{0, 7, 3, 5}
+	sig, pk := key.forsSign(nil, h, indexes, 1, 2)
+
+	if len(sig) != params.forsSize() {
+		t.Fatalf("forsSign() should return signature of %d bytes, got %d", params.forsSize(), len(sig))
+	}
+
+	// Public key computed from signature should match
+	if !bytes.Equal(params.forsPK(h, key.pSeed, sig, indexes, 1, 2), pk) {
+		t.Fatalf("forsPK() should return the FORS public key of the signature")
+	}
+
+	// Different indexes shouldn't give the same public key
+	if bytes.Equal(params.forsPK(h, key.pSeed, sig, []uint32{1, 7, 3, 5}, 1, 2), pk) {
+		t.Fatalf("forsPK() shouldn't return the FORS public key for different indexes")
+	}
+
+	// FORS key of a different leaf should have a different public key
+	_, other := key.forsSign(nil, h, indexes, 1, 3)
+	if bytes.Equal(other, pk) {
+		t.Fatalf("forsSign() should use a different FORS key for each leaf")
+	}
+}
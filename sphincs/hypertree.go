@@ -0,0 +1,177 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"github.com/xx-labs/sleeve/wots"
+	"hash"
+)
+
+///////////////////////////////////////////////////////////////////////
+// HYPERTREE
+/*
+	The hypertree is composed by d layers of Merkle trees of height h/d,
+	where the leaves of each tree are WOTS+ public keys. Each WOTS+ key
+	of layer l signs the root of one tree of layer l-1, and the WOTS+
+	keys of layer 0 sign FORS public keys. The root of the single tree
+	of the top layer is the root of the hypertree
+
+	Trees are never stored, only the trees in the path of a signature
+	are computed when signing. The secret and public seeds of each
+	WOTS+ key are derived from the key seeds and its address:
+	  seed  = PRF(SEED,  ADRS)
+	  pSeed = PRF(PSEED, ADRS)
+	so the WOTS+ public seed is not included in the signature
+
+	Leaves and nodes are hashed with the tweakable hash:
+	  leaf = T(PSEED, ADRS, wots PK)
+	  node = T(PSEED, ADRS, left || right)
+*/
+
+// Get the WOTS+ key of the given layer, tree and leaf
+func (k *Key) wotsKey(h hash.Hash, layer uint32, tree uint64, leaf uint32) *wots.Key {
+	var a address
+	a.setLayer(layer)
+	a.setTree(tree)
+	a.setType(addrWotsSeed)
+	a.setKeyPair(leaf)
+	seed := prf(nil, h, k.seed, &a)
+	defer secmem.Wipe(seed)
+	return wots.NewKeyFromSeed(k.params.wots, seed[:SeedSize], k.params.wotsPSeed(h, k.pSeed, layer, tree, leaf))
+}
+
+// Get the public seed of the WOTS+ key of the given layer, tree and leaf
+func (p *Params) wotsPSeed(h hash.Hash, pSeed []byte, layer uint32, tree uint64, leaf uint32) []byte {
+	var a address
+	a.setLayer(layer)
+	a.setTree(tree)
+	a.setType(addrWotsPSeed)
+	a.setKeyPair(leaf)
+	return prf(nil, h, pSeed, &a)[:SeedSize]
+}
+
+// Get the leaf of the given layer and tree from its WOTS+ public key
+func (p *Params) treeLeaf(h hash.Hash, pSeed []byte, layer uint32, tree uint64, leaf uint32, wotsPK []byte) []byte {
+	var a address
+	a.setLayer(layer)
+	a.setTree(tree)
+	a.setType(addrTree)
+	a.setIndex(leaf)
+	return p.thash(nil, h, pSeed, &a, wotsPK)
+}
+
+// Compute the tree of the given layer, returning its root and the authentication path of leaf
+func (k *Key) subtree(h hash.Hash, layer uint32, tree uint64, leaf uint32) ([]byte, []byte) {
+	p := k.params
+	leaves := make([][]byte, 1<<uint(p.treeHeight()))
+	for i := range leaves {
+		wk := k.wotsKey(h, layer, tree, uint32(i))
+		leaves[i] = p.treeLeaf(h, k.pSeed, layer, tree, uint32(i), wk.ComputePK())
+		wk.Destroy()
+	}
+
+	var a address
+	a.setLayer(layer)
+	a.setTree(tree)
+	a.setType(addrTree)
+	return p.treeRoot(h, k.pSeed, &a, leaves, leaf, 0)
+}
+
+// Sign the n byte root with the hypertree, starting from the leaf of the layer 0 tree
+// The signature is appended to out
+func (k *Key) htSign(out []byte, h hash.Hash, root []byte, tree uint64, leaf uint32) []byte {
+	p := k.params
+	mask := uint64(1)<<uint(p.treeHeight()) - 1
+	for layer := uint32(0); int(layer) < p.d; layer++ {
+		// 1. Sign the root of the previous layer with the WOTS+ key of the leaf
+		wk := k.wotsKey(h, layer, tree, leaf)
+		sig := wk.Sign(root)
+		wk.Destroy()
+		out = append(out, sig[1+SeedSize:]...)
+
+		// 2. Append the authentication path of the leaf
+		var auth []byte
+		root, auth = k.subtree(h, layer, tree, leaf)
+		out = append(out, auth...)
+
+		// 3. Move to the parent tree
+		leaf = uint32(tree & mask)
+		tree >>= uint(p.treeHeight())
+	}
+	return out
+}
+
+// Compute the root of the hypertree from the n byte root signed in the signature
+// The signature must have the correct size
+// Returns an error if a WOTS+ signature can't be decoded
+func (p *Params) htRoot(h hash.Hash, pSeed, root, signature []byte, tree uint64, leaf uint32) ([]byte, error) {
+	mask := uint64(1)<<uint(p.treeHeight()) - 1
+	wotsSig := make([]byte, SeedSize+p.wotsSize())
+	for layer := uint32(0); int(layer) < p.d; layer++ {
+		layerSig := signature[int(layer)*p.layerSize() : int(layer+1)*p.layerSize()]
+
+		// 1. Decode the WOTS+ signature into the WOTS+ public key
+		copy(wotsSig, p.wotsPSeed(h, pSeed, layer, tree, leaf))
+		copy(wotsSig[SeedSize:], layerSig[:p.wotsSize()])
+		wotsPK := make([]byte, 0, wots.PKSize)
+		wotsPK, err := p.wots.Decode(wotsPK, root, wotsSig)
+		if err != nil {
+			return nil, err
+		}
+
+		// 2. Climb the tree using the authentication path
+		var a address
+		a.setLayer(layer)
+		a.setTree(tree)
+		a.setType(addrTree)
+		node := p.treeLeaf(h, pSeed, layer, tree, leaf, wotsPK)
+		root = p.climb(h, pSeed, &a, node, layerSig[p.wotsSize():], leaf, 0)
+
+		// 3. Move to the parent tree
+		leaf = uint32(tree & mask)
+		tree >>= uint(p.treeHeight())
+	}
+	return root, nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// MERKLE TREES
+
+// Compute the root of the tree over the leaves, and the authentication path of leaf idx
+// Node i at height z has index offset>>z + i in the address
+func (p *Params) treeRoot(h hash.Hash, pSeed []byte, a *address, leaves [][]byte, idx, offset uint32) ([]byte, []byte) {
+	var auth []byte
+	level := leaves
+	for z := uint(0); len(level) > 1; z++ {
+		auth = append(auth, level[(idx>>z)^1]...)
+		next := make([][]byte, len(level)/2)
+		a.setHeight(uint32(z + 1))
+		for i := range next {
+			a.setIndex(offset>>(z+1) + uint32(i))
+			next[i] = p.thash(nil, h, pSeed, a, level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	return level[0], auth
+}
+
+// Compute the root of the tree from leaf idx and its authentication path
+func (p *Params) climb(h hash.Hash, pSeed []byte, a *address, node, auth []byte, idx, offset uint32) []byte {
+	height := len(auth) / p.n
+	for z := uint(0); int(z) < height; z++ {
+		sibling := auth[int(z)*p.n : int(z+1)*p.n]
+		a.setHeight(uint32(z + 1))
+		a.setIndex((offset + idx) >> (z + 1))
+		if (idx>>z)&1 == 0 {
+			node = p.thash(nil, h, pSeed, a, node, sibling)
+		} else {
+			node = p.thash(nil, h, pSeed, a, sibling, node)
+		}
+	}
+	return node
+}
@@ -0,0 +1,133 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"fmt"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/wots"
+)
+
+// The size of public keys is fixed to 32 bytes
+const PKSize = 32
+
+// The size of secret and public seeds is fixed to 32 bytes
+const SeedSize = wots.SeedSize
+
+// The hash function used to compute the public key from the public seed and root
+const PKHash = hasher.SHA3_256
+
+// Maximum height of each tree of the hypertree
+const MaxTreeHeight = 16
+
+// Maximum height of the FORS trees
+const MaxForsHeight = 24
+
+// SPHINCS+ parameters //
+type Params struct {
+	// The size of tree nodes, FORS secret keys and WOTS+ ladder points
+	n int
+	// Total height of the hypertree
+	h int
+	// Number of layers of the hypertree
+	d int
+	// Height of each FORS tree
+	a int
+	// Number of FORS trees
+	k int
+	// The Winternitz parameter of the WOTS+ keys
+	w int
+	// The hash function used for all tweakable hashes and PRFs
+	hash hasher.Hasher
+	// The WOTS+ params used to sign the tree roots
+	wots *wots.Params
+}
+
+///////////////////////////////////////////////////////////////////////
+// Constructor
+
+// Creates SPHINCS+ params with given values of n, h, d, a, k, w and hash function
+// Returns nil if the params are invalid:
+//   - n must be between 16 and 32 bytes
+//   - the hash function must have at least 32 bytes of output, to derive WOTS+ seeds
+//   - d must divide h, and each tree can't be higher than MaxTreeHeight
+//   - the tree index, with h - h/d bits, must fit in 64 bits
+//   - a must be between 1 and MaxForsHeight, and k at least 1
+//   - w must be a valid WOTS+ Winternitz parameter, see wots.NewParamsW
+func NewParams(n, h, d, a, k, w int, hash hasher.Hasher) *Params {
+	if n < 16 || n > 32 || hash.Size() < SeedSize {
+		return nil
+	}
+	if d < 1 || h < d || h%d != 0 || h/d > MaxTreeHeight || h-h/d > 64 {
+		return nil
+	}
+	if a < 1 || a > MaxForsHeight || k < 1 {
+		return nil
+	}
+	// WOTS+ keys sign the n byte tree roots
+	wp := wots.NewParamsW(n, n, w, hash, hash)
+	if wp == nil {
+		return nil
+	}
+	return &Params{
+		n:    n,
+		h:    h,
+		d:    d,
+		a:    a,
+		k:    k,
+		w:    w,
+		hash: hash,
+		wots: wp,
+	}
+}
+
+///////////////////////////////////////////////////////////////////////
+// Stringer interface
+func (p *Params) String() string {
+	return fmt.Sprintf("N: %d, H: %d, D: %d, A: %d, K: %d, W: %d, HASH: %s",
+		p.n, p.h, p.d, p.a, p.k, p.w, p.hash)
+}
+
+///////////////////////////////////////////////////////////////////////
+// Comparison
+func (p *Params) Equal(other *Params) bool {
+	return p.n == other.n && p.h == other.h && p.d == other.d && p.a == other.a && p.k == other.k &&
+		p.hash == other.hash && p.wots.Equal(other.wots)
+}
+
+///////////////////////////////////////////////////////////////////////
+// Sizes
+
+// Get the size of a serialized signature, including the params encoding
+func (p *Params) SignatureSize() int {
+	return 1 + SeedSize + p.n + p.forsSize() + p.d*p.layerSize()
+}
+
+// Size of a FORS signature: k secret keys and authentication paths
+func (p *Params) forsSize() int {
+	return p.k * (p.a + 1) * p.n
+}
+
+// Size of the signature of one hypertree layer: WOTS+ ladder points and authentication path
+func (p *Params) layerSize() int {
+	return p.wotsSize() + p.treeHeight()*p.n
+}
+
+// Size of the WOTS+ ladder points
+func (p *Params) wotsSize() int {
+	return p.wots.SecurityEstimate().SignatureSize - 1 - SeedSize
+}
+
+// Height of each tree of the hypertree
+func (p *Params) treeHeight() int {
+	return p.h / p.d
+}
+
+// Size of the message digest: FORS indexes, tree index and leaf index
+func (p *Params) digestSize() int {
+	return (p.k*p.a+7)/8 + (p.h-p.treeHeight()+7)/8 + (p.treeHeight()+7)/8
+}
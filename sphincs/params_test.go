@@ -0,0 +1,191 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"math"
+	"testing"
+)
+
+func getRandData(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	n, err := rand.Read(data)
+
+	if err != nil {
+		t.Fatalf("Error reading random bytes: %s", err)
+	}
+
+	if n != size {
+		t.Fatalf("Reader only gave us %d bytes, expected %d", n, size)
+	}
+	return data
+}
+
+// Small params used to test signing, since the parameter sets are too slow for most tests
+func testParams() *Params {
+	return NewParams(16, 4, 2, 3, 4, 16, hasher.SHA2_256)
+}
+
+func TestParams_NewParams(t *testing.T) {
+	// Test n smaller than allowed
+	if NewParams(15, 4, 2, 3, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if n is smaller than 16")
+	}
+
+	// Test n larger than allowed
+	if NewParams(33, 4, 2, 3, 4, 16, hasher.SHA3_512) != nil {
+		t.Fatalf("NewParams() should return nil if n is larger than 32")
+	}
+
+	// Test hash size smaller than seed size
+	if NewParams(16, 4, 2, 3, 4, 16, hasher.SHA3_224) != nil {
+		t.Fatalf("NewParams() should return nil if hash size is smaller than %d", SeedSize)
+	}
+
+	// Test d not dividing h
+	if NewParams(16, 5, 2, 3, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if d doesn't divide h")
+	}
+
+	// Test d equal to 0
+	if NewParams(16, 4, 0, 3, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if d is 0")
+	}
+
+	// Test tree height larger than allowed
+	if NewParams(16, MaxTreeHeight+1, 1, 3, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if tree height is larger than %d", MaxTreeHeight)
+	}
+
+	// Test tree index larger than 64 bits
+	if NewParams(16, 68, 2, 3, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if tree index doesn't fit in 64 bits")
+	}
+
+	// Test FORS height of 0
+	if NewParams(16, 4, 2, 0, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if a is 0")
+	}
+
+	// Test FORS height larger than allowed
+	if NewParams(16, 4, 2, MaxForsHeight+1, 4, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if a is larger than %d", MaxForsHeight)
+	}
+
+	// Test no FORS trees
+	if NewParams(16, 4, 2, 3, 0, 16, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if k is 0")
+	}
+
+	// Test invalid Winternitz parameter
+	if NewParams(16, 4, 2, 3, 4, 3, hasher.SHA2_256) != nil {
+		t.Fatalf("NewParams() should return nil if w is invalid")
+	}
+
+	// Test valid params
+	if testParams() == nil {
+		t.Fatalf("NewParams() should return valid params")
+	}
+}
+
+func TestParams_Sizes(t *testing.T) {
+	// Sizes of SPHINCS+-SHA2-128s and 128f, plus the params encoding and public seed
+	expected := map[ParamsEncoding]int{
+		Small: 1 + SeedSize + 7856,
+		Fast:  1 + SeedSize + 17088,
+	}
+
+	for enc, size := range expected {
+		params := DecodeParams(enc)
+		if params.SignatureSize() != size {
+			t.Fatalf("SignatureSize() for %s should be %d, got %d", enc, size, params.SignatureSize())
+		}
+	}
+}
+
+func TestParams_Security(t *testing.T) {
+	// Security levels documented in security.go
+	for enc := Small; enc < ParamsEncodingLen; enc++ {
+		est := DecodeParams(enc).wots.SecurityEstimate()
+		if math.Abs(est.Classical-114.87) > 0.005 || est.PostQuantum != 64 {
+			t.Fatalf("SecurityEstimate() for %s should be 114.87 and 64 bits, got %.2f and %.2f",
+				enc, est.Classical, est.PostQuantum)
+		}
+	}
+}
+
+func TestParams_Digest(t *testing.T) {
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		params := DecodeParams(enc)
+		r := getRandData(t, params.n)
+		pSeed := getRandData(t, SeedSize)
+		msg := getRandData(t, 256)
+
+		digest := params.hashMsg(r, pSeed, msg)
+		if len(digest) != params.digestSize() {
+			t.Fatalf("hashMsg() should return %d bytes for %s, got %d", params.digestSize(), enc, len(digest))
+		}
+
+		indexes, tree, leaf := params.splitDigest(digest)
+		if len(indexes) != params.k {
+			t.Fatalf("splitDigest() should return %d indexes for %s, got %d", params.k, enc, len(indexes))
+		}
+		for _, idx := range indexes {
+			if idx >= 1<<uint(params.a) {
+				t.Fatalf("splitDigest() returned FORS index %d larger than %d bits for %s", idx, params.a, enc)
+			}
+		}
+		if bits := uint(params.h - params.treeHeight()); bits < 64 && tree >= 1<<bits {
+			t.Fatalf("splitDigest() returned tree index %d larger than %d bits for %s", tree, bits, enc)
+		}
+		if leaf >= 1<<uint(params.treeHeight()) {
+			t.Fatalf("splitDigest() returned leaf index %d larger than %d bits for %s", leaf, params.treeHeight(), enc)
+		}
+	}
+}
+
+func TestParams_SplitDigest(t *testing.T) {
+	// k = 4, a = 3: 12 bits of indexes in 2 bytes, 2 bits of tree in 1 byte and 2 bits of leaf in 1 byte
+	params := testParams()
+	digest := []byte{0x29, 0xcf, 0xfe, 0xff}
+
+	indexes, tree, leaf := params.splitDigest(digest)
+	expected := []uint32{1, 2, 3, 4}
+	for i := range expected {
+		if indexes[i] != expected[i] {
+			t.Fatalf("splitDigest() returned wrong index %d: expected %d, got %d", i, expected[i], indexes[i])
+		}
+	}
+	if tree != 2 {
+		t.Fatalf("splitDigest() returned wrong tree index: expected 2, got %d", tree)
+	}
+	if leaf != 3 {
+		t.Fatalf("splitDigest() returned wrong leaf index: expected 3, got %d", leaf)
+	}
+}
+
+func TestParams_Encoding(t *testing.T) {
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		params := DecodeParams(enc)
+		if params == nil {
+			t.Fatalf("DecodeParams() should return params for encoding %d", enc)
+		}
+		if EncodeParams(params) != enc {
+			t.Fatalf("EncodeParams() should return %s, got %s", enc, EncodeParams(params))
+		}
+	}
+
+	if DecodeParams(ParamsEncodingLen) != nil {
+		t.Fatalf("DecodeParams() should return nil for unknown encoding")
+	}
+
+	if EncodeParams(testParams()) != ParamsEncodingLen {
+		t.Fatalf("EncodeParams() should return ParamsEncodingLen for unknown params")
+	}
+}
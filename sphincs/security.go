@@ -0,0 +1,119 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"github.com/xx-labs/sleeve/hasher"
+)
+
+///////////////////////////////////////////////////////////////////////
+// PARAMETER SETS
+/*
+	The parameter sets use the same tree and FORS dimensions as the
+	SPHINCS+ 128s and 128f parameter sets, with n = 16 bytes and w = 16,
+	allowing up to 2^64 signatures per key. Unlike SPHINCS+, the WOTS+
+	ladders of the hypertree are the xx WOTS+ ladders, estimated with
+	the WOTS+ security estimate (see wots/estimate.go) at:
+	  Classical:    128 - log2(16^2 * 35) = 114.87 bits
+	  Post quantum: 64 bits
+	so the parameter sets don't reach the 128 bits of classical security
+	of SPHINCS+ 128s and 128f
+
+	64 bits of post quantum security is also below every WOTS+ security
+	level of the wots package, which start at 80 bits for Level0 and
+	reach 128 bits for Level3 and Consensus. Small and Fast should only
+	be used where 64 bits of post quantum security is acceptable
+
+	Small has smaller signatures, but signing is slower, since each
+	tree of the hypertree has 2^9 leaves. Fast signs faster, with
+	trees of 2^3 leaves, at the cost of larger signatures
+*/
+
+///////////////////////////////////////////////////////////////////////
+// SMALL
+// N = 16, H = 63, D = 7, A = 12, K = 14, W = 16
+// 114.87 bits classical, 64 bits post quantum: below the wots levels
+const (
+	smallN    = 16
+	smallH    = 63
+	smallD    = 7
+	smallA    = 12
+	smallK    = 14
+	smallW    = 16
+	smallHash = hasher.SHA2_256
+)
+
+var smallParams = NewParams(smallN, smallH, smallD, smallA, smallK, smallW, smallHash)
+
+///////////////////////////////////////////////////////////////////////
+// FAST
+// N = 16, H = 66, D = 22, A = 6, K = 33, W = 16
+// 114.87 bits classical, 64 bits post quantum: below the wots levels
+const (
+	fastN    = 16
+	fastH    = 66
+	fastD    = 22
+	fastA    = 6
+	fastK    = 33
+	fastW    = 16
+	fastHash = hasher.SHA2_256
+)
+
+var fastParams = NewParams(fastN, fastH, fastD, fastA, fastK, fastW, fastHash)
+
+///////////////////////////////////////////////////////////////////////
+// Params encoding
+type ParamsEncoding uint8
+
+// Both parameter sets have 64 bits of post quantum security, which is
+// below the security levels of the wots package
+const (
+	Small ParamsEncoding = iota
+	Fast
+)
+const (
+	ParamsEncodingLen = Fast + 1
+	DefaultParams     = Small
+)
+
+var paramsList = [ParamsEncodingLen]*Params{
+	smallParams,
+	fastParams,
+}
+
+// Get the parameter set from its encoding
+// Returns nil if the encoding is unknown
+func DecodeParams(enc ParamsEncoding) *Params {
+	if enc >= ParamsEncodingLen {
+		return nil
+	}
+	return paramsList[enc]
+}
+
+// Encode a parameter set
+// Returns ParamsEncodingLen if the params are unknown
+func EncodeParams(p *Params) ParamsEncoding {
+	for i, params := range paramsList {
+		if params.Equal(p) {
+			return ParamsEncoding(i)
+		}
+	}
+	// This will decode to nil
+	return ParamsEncodingLen
+}
+
+// Get the name of the parameter set
+func (enc ParamsEncoding) String() string {
+	switch enc {
+	case Small:
+		return "Small"
+	case Fast:
+		return "Fast"
+	default:
+		return "UNKNOWN PARAMS"
+	}
+}
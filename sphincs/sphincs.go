@@ -0,0 +1,315 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"io"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// SPHINCS+-STYLE STATELESS SIGNATURES
+/*
+	WOTS+ keys can only sign one message, and the XMSS trees of the xmss
+	package require the index of the next unused key to be persisted.
+	This package implements a stateless hash-based signature scheme,
+	following the SPHINCS+ design: a FORS few-time key is selected
+	pseudorandomly from the message, and signed by a hypertree of WOTS+
+	keys (see fors.go and hypertree.go). The same key can sign up to
+	2^64 messages without keeping any state
+
+	Signing is deterministic. The message is hashed as:
+	  R      = PRF(SEED, ADRS || msg)[:n]
+	  digest = H_msg(R, PSEED, msg)
+	and the digest is split into the FORS indexes, the tree of the
+	bottom layer of the hypertree, and the leaf of that tree
+
+	The public key is PKHash(PSEED || root), where root is the root of
+	the hypertree. Signatures are composed by
+	  ParamsEncoding,  1 byte
+	  Public Seed,     32 bytes
+	  R,               n bytes
+	  FORS signature,  k*(a+1)*n bytes
+	  HT signature,    d*(WOTS+ ladder points + h/d*n) bytes
+*/
+
+///////////////////////////////////////////////////////////////////////
+// Errors
+var (
+	errWrongSigLen        = errors.New("signature has incorrect length")
+	errInvalidOutputSlice = errors.New("output slice is invalid: should have length 0 and capacity of 32 bytes")
+	errWrongPubKeySize    = errors.New("public key has incorrect length: should be 32 bytes")
+	errInvalidMsgOrSig    = errors.New("message or signature is empty")
+	errDecodingParams     = errors.New("couldn't decode SPHINCS+ params")
+)
+
+// SPHINCS+ KEY //
+type Key struct {
+	// The secret seed, used to derive all secret keys and R
+	seed []byte
+	// The public seed, used in all tweakable hashes
+	pSeed []byte
+	// The public key, computed once
+	pk   []byte
+	once sync.Once
+	// The params of this key
+	params *Params
+	// Set by Destroy
+	destroyed bool
+}
+
+///////////////////////////////////////////////////////////////////////
+// Constructors
+
+// Creates a SPHINCS+ key with given params, and uses csprng to read
+// random values for the seed and public seed
+func NewKey(params *Params, csprng io.Reader) *Key {
+	seed := make([]byte, SeedSize)
+	pSeed := make([]byte, SeedSize)
+	if n, err := csprng.Read(seed); err != nil || n != SeedSize {
+		return nil
+	}
+	if n, err := csprng.Read(pSeed); err != nil || n != SeedSize {
+		return nil
+	}
+	return NewKeyFromSeed(params, seed, pSeed)
+}
+
+// Creates a SPHINCS+ key with given params, and given secret and public seeds
+func NewKeyFromSeed(params *Params, seed []byte, pSeed []byte) *Key {
+	if params == nil || len(seed) != SeedSize || len(pSeed) != SeedSize {
+		return nil
+	}
+	k := &Key{
+		seed:   make([]byte, SeedSize),
+		pSeed:  make([]byte, SeedSize),
+		params: params,
+	}
+	copy(k.seed, seed)
+	copy(k.pSeed, pSeed)
+	return k
+}
+
+///////////////////////////////////////////////////////////////////////
+// Get the Public Key
+// Returns nil if not computed yet
+func (k *Key) GetPK() []byte {
+	return k.pk
+}
+
+///////////////////////////////////////////////////////////////////////
+// DESTROY
+// Wipe the secret and public seeds of the key
+// After this, Sign and ComputePK return nil
+func (k *Key) Destroy() {
+	if k.destroyed {
+		return
+	}
+	secmem.Wipe(k.seed)
+	secmem.Wipe(k.pSeed)
+	k.seed = nil
+	k.pSeed = nil
+	k.pk = nil
+	k.destroyed = true
+}
+
+///////////////////////////////////////////////////////////////////////
+// COMPUTE PK
+// Compute the PK from this key's seeds, which requires computing the
+// top tree of the hypertree
+// If PK was already computed, return it
+// Returns nil if the key was destroyed
+func (k *Key) ComputePK() []byte {
+	if k.destroyed {
+		return nil
+	}
+	k.once.Do(func() {
+		h := k.params.hash.GetSecret()
+		defer k.params.hash.PutSecret(h)
+		top := uint32(k.params.d - 1)
+		root, _ := k.subtree(h, top, 0, 0)
+		k.pk = computePK(k.pSeed, root)
+	})
+	return k.pk
+}
+
+///////////////////////////////////////////////////////////////////////
+// SIGN
+// Signs an arbitrary length message using the SPHINCS+ key
+// Returns the signature, see the package description for its layout
+// The key can be used to sign any number of messages
+// Returns nil if the key was destroyed
+func (k *Key) Sign(msg []byte) []byte {
+	if k.destroyed {
+		return nil
+	}
+	p := k.params
+	pk := k.ComputePK()
+	h := p.hash.GetSecret()
+	defer p.hash.PutSecret(h)
+
+	// 1. Compute the randomizer and message digest
+	var a address
+	a.setType(addrMsgPRF)
+	h.Reset()
+	h.Write(k.seed)
+	h.Write(a[:])
+	h.Write(msg)
+	r := h.Sum(nil)[:p.n]
+	indexes, tree, leaf := p.splitDigest(p.hashMsg(r, k.pSeed, msg))
+
+	// 2. Header
+	signature := make([]byte, 0, p.SignatureSize())
+	signature = append(signature, byte(EncodeParams(p)))
+	signature = append(signature, k.pSeed...)
+	signature = append(signature, r...)
+
+	// 3. Sign digest with FORS, and FORS public key with the hypertree
+	signature, forsPK := k.forsSign(signature, h, indexes, tree, leaf)
+	signature = k.htSign(signature, h, forsPK, tree, leaf)
+
+	// 4. Make sure the hypertree root matches the public key
+	root, err := p.htRoot(h, k.pSeed, forsPK, signature[len(signature)-p.d*p.layerSize():], tree, leaf)
+	if err != nil || !bytes.Equal(computePK(k.pSeed, root), pk) {
+		return nil
+	}
+	return signature
+}
+
+///////////////////////////////////////////////////////////////////////
+// Decode a signature, i.e., compute the public key from the message and signature
+// The signature doesn't include the params encoding
+func (p *Params) Decode(out, msg, signature []byte) ([]byte, error) {
+	// Ensure signature has correct size
+	if len(signature) != p.SignatureSize()-1 {
+		return nil, errWrongSigLen
+	}
+
+	// Ensure output slice is well formed
+	if len(out) != 0 || cap(out) != PKSize {
+		return nil, errInvalidOutputSlice
+	}
+
+	h := p.hash.Get()
+	defer p.hash.Put(h)
+
+	// 1. Get public seed and randomizer, and compute the message digest
+	pSeed := signature[:SeedSize]
+	r := signature[SeedSize : SeedSize+p.n]
+	indexes, tree, leaf := p.splitDigest(p.hashMsg(r, pSeed, msg))
+	signature = signature[SeedSize+p.n:]
+
+	// 2. Compute FORS public key, and the hypertree root from it
+	forsPK := p.forsPK(h, pSeed, signature[:p.forsSize()], indexes, tree, leaf)
+	root, err := p.htRoot(h, pSeed, forsPK, signature[p.forsSize():], tree, leaf)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Compute public key
+	return append(out, computePK(pSeed, root)...), nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// Verify a signature
+// The signature doesn't include the params encoding
+func (p *Params) Verify(msg, signature, pubkey []byte) (bool, error) {
+	// Ensure pubkey has correct size
+	if len(pubkey) != PKSize {
+		return false, errWrongPubKeySize
+	}
+	// Decode signature
+	pk := make([]byte, 0, PKSize)
+	pk, err := p.Decode(pk, msg, signature)
+	// Compare public key
+	return bytes.Equal(pk, pubkey), err
+}
+
+// Verify a signature, using the params encoded in the signature
+func Verify(msg, signature, pubkey []byte) (bool, error) {
+	// 1. Return if msg or signature is empty
+	if len(msg) == 0 || len(signature) == 0 {
+		return false, errInvalidMsgOrSig
+	}
+	// 2. Decode params
+	params := DecodeParams(ParamsEncoding(signature[0]))
+	if params == nil {
+		return false, errDecodingParams
+	}
+	// 3. Verify signature
+	return params.Verify(msg, signature[1:], pubkey)
+}
+
+///////////////////////////////////////////////////////////////////////
+// PRIVATE
+
+// Compute the public key from the public seed and hypertree root
+func computePK(pSeed, root []byte) []byte {
+	h := PKHash.Get()
+	defer PKHash.Put(h)
+	h.Write(pSeed)
+	h.Write(root)
+	return h.Sum(nil)
+}
+
+// Hash the message into the digest
+// seed = H(R || PSEED || msg), digest = H(seed || 0) || H(seed || 1) || ...
+func (p *Params) hashMsg(r, pSeed, msg []byte) []byte {
+	h := p.hash.Get()
+	defer p.hash.Put(h)
+	h.Write(r)
+	h.Write(pSeed)
+	h.Write(msg)
+	seed := h.Sum(nil)
+
+	digest := make([]byte, 0, p.digestSize()+h.Size())
+	var counter [4]byte
+	for i := uint32(0); len(digest) < p.digestSize(); i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		digest = h.Sum(digest)
+	}
+	return digest[:p.digestSize()]
+}
+
+// Split the digest into the FORS indexes, the tree of the bottom layer and the leaf of that tree
+// Bits are taken from the most significant bits of each byte first
+func (p *Params) splitDigest(digest []byte) ([]uint32, uint64, uint32) {
+	// 1. FORS indexes, a bits each
+	indexes := make([]uint32, p.k)
+	for t := range indexes {
+		for b := t * p.a; b < (t+1)*p.a; b++ {
+			indexes[t] = indexes[t]<<1 | uint32(digest[b/8]>>uint(7-b%8))&1
+		}
+	}
+	digest = digest[(p.k*p.a+7)/8:]
+
+	// 2. Tree index, h - h/d bits
+	treeBits := p.h - p.treeHeight()
+	tree := uint64(0)
+	for _, b := range digest[:(treeBits+7)/8] {
+		tree = tree<<8 | uint64(b)
+	}
+	if treeBits < 64 {
+		tree &= uint64(1)<<uint(treeBits) - 1
+	}
+	digest = digest[(treeBits+7)/8:]
+
+	// 3. Leaf index, h/d bits
+	leaf := uint32(0)
+	for _, b := range digest[:(p.treeHeight()+7)/8] {
+		leaf = leaf<<8 | uint32(b)
+	}
+	leaf &= uint32(1)<<uint(p.treeHeight()) - 1
+	return indexes, tree, leaf
+}
@@ -0,0 +1,43 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+const MsgSize = 256
+
+func BenchmarkSign(b *testing.B) {
+	msg := make([]byte, MsgSize)
+	_, _ = rand.Read(msg)
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		key := NewKey(DecodeParams(enc), rand.Reader)
+		key.ComputePK()
+		b.Run(enc.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = key.Sign(msg)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	msg := make([]byte, MsgSize)
+	_, _ = rand.Read(msg)
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		key := NewKey(DecodeParams(enc), rand.Reader)
+		sig := key.Sign(msg)
+		pk := key.ComputePK()
+		b.Run(enc.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = Verify(msg, sig, pk)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package sphincs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errInvalidMsgOrSig
+}
+
+func TestNewKey(t *testing.T) {
+	if NewKey(nil, rand.Reader) != nil {
+		t.Fatalf("NewKey() should return nil for nil params")
+	}
+
+	if NewKey(testParams(), errReader{}) != nil {
+		t.Fatalf("NewKey() should return nil if reading from csprng fails")
+	}
+
+	if NewKeyFromSeed(testParams(), make([]byte, SeedSize-1), make([]byte, SeedSize)) != nil {
+		t.Fatalf("NewKeyFromSeed() should return nil for invalid seed size")
+	}
+
+	if NewKeyFromSeed(testParams(), make([]byte, SeedSize), make([]byte, SeedSize+1)) != nil {
+		t.Fatalf("NewKeyFromSeed() should return nil for invalid public seed size")
+	}
+
+	key := NewKey(testParams(), rand.Reader)
+	if key == nil {
+		t.Fatalf("NewKey() should return a valid key")
+	}
+
+	if key.GetPK() != nil {
+		t.Fatalf("GetPK() should return nil before the public key is computed")
+	}
+
+	pk := key.ComputePK()
+	if len(pk) != PKSize {
+		t.Fatalf("ComputePK() should return %d bytes, got %d", PKSize, len(pk))
+	}
+
+	if !bytes.Equal(key.GetPK(), pk) {
+		t.Fatalf("GetPK() should return the computed public key")
+	}
+}
+
+func TestKey_SignAndVerify(t *testing.T) {
+	params := testParams()
+	key := NewKey(params, rand.Reader)
+	pk := key.ComputePK()
+
+	// Sign many messages with the same key, so that several leaves are used
+	for i := 0; i < 16; i++ {
+		msg := getRandData(t, 256)
+		sig := key.Sign(msg)
+
+		if len(sig) != params.SignatureSize() {
+			t.Fatalf("Sign() should return signature of %d bytes, got %d", params.SignatureSize(), len(sig))
+		}
+
+		ok, err := params.Verify(msg, sig[1:], pk)
+		if err != nil {
+			t.Fatalf("Verify() returned error for valid signature: %s", err)
+		}
+		if !ok {
+			t.Fatalf("Verify() should return true for valid signature")
+		}
+	}
+}
+
+func TestKey_SignDeterministic(t *testing.T) {
+	params := testParams()
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+	msg := getRandData(t, 256)
+
+	sig := NewKeyFromSeed(params, seed, pSeed).Sign(msg)
+	if !bytes.Equal(NewKeyFromSeed(params, seed, pSeed).Sign(msg), sig) {
+		t.Fatalf("Sign() should return the same signature for the same key and message")
+	}
+
+	if bytes.Equal(NewKeyFromSeed(params, seed, pSeed).Sign(getRandData(t, 256)), sig) {
+		t.Fatalf("Sign() should return different signatures for different messages")
+	}
+}
+
+func TestKey_Destroy(t *testing.T) {
+	key := NewKey(testParams(), rand.Reader)
+	key.ComputePK()
+	seed := key.seed
+	pSeed := key.pSeed
+
+	key.Destroy()
+
+	if !bytes.Equal(seed, make([]byte, SeedSize)) || !bytes.Equal(pSeed, make([]byte, SeedSize)) {
+		t.Fatalf("Destroy() should wipe the seeds")
+	}
+
+	if key.GetPK() != nil || key.ComputePK() != nil {
+		t.Fatalf("ComputePK() should return nil after Destroy()")
+	}
+
+	if key.Sign(getRandData(t, 256)) != nil {
+		t.Fatalf("Sign() should return nil after Destroy()")
+	}
+
+	// Destroy twice should be fine
+	key.Destroy()
+}
+
+func TestParams_VerifyInvalid(t *testing.T) {
+	params := testParams()
+	key := NewKey(params, rand.Reader)
+	pk := key.ComputePK()
+	msg := getRandData(t, 256)
+	sig := key.Sign(msg)[1:]
+
+	// Test wrong public key size
+	if _, err := params.Verify(msg, sig, pk[1:]); err != errWrongPubKeySize {
+		t.Fatalf("Verify() should return error for wrong public key size")
+	}
+
+	// Test wrong signature size
+	if _, err := params.Verify(msg, sig[1:], pk); err != errWrongSigLen {
+		t.Fatalf("Verify() should return error for wrong signature size")
+	}
+
+	// Test wrong message
+	if ok, _ := params.Verify(getRandData(t, 256), sig, pk); ok {
+		t.Fatalf("Verify() should return false for wrong message")
+	}
+
+	// Test wrong public key
+	if ok, _ := params.Verify(msg, sig, NewKey(params, rand.Reader).ComputePK()); ok {
+		t.Fatalf("Verify() should return false for wrong public key")
+	}
+
+	// Test tampering with each part of the signature
+	parts := map[string]int{
+		"public seed":    0,
+		"randomizer":     SeedSize,
+		"FORS signature": SeedSize + params.n,
+		"HT signature":   SeedSize + params.n + params.forsSize(),
+		"last byte":      len(sig) - 1,
+	}
+	for name, offset := range parts {
+		tampered := make([]byte, len(sig))
+		copy(tampered, sig)
+		tampered[offset] ^= 0x01
+		if ok, _ := params.Verify(msg, tampered, pk); ok {
+			t.Fatalf("Verify() should return false when tampering with %s", name)
+		}
+	}
+}
+
+func TestParams_DecodeInvalidOutput(t *testing.T) {
+	params := testParams()
+	key := NewKey(params, rand.Reader)
+	msg := getRandData(t, 256)
+	sig := key.Sign(msg)[1:]
+
+	if _, err := params.Decode(make([]byte, 1, PKSize), msg, sig); err != errInvalidOutputSlice {
+		t.Fatalf("Decode() should return error for output slice with non zero length")
+	}
+
+	if _, err := params.Decode(make([]byte, 0, PKSize-1), msg, sig); err != errInvalidOutputSlice {
+		t.Fatalf("Decode() should return error for output slice with wrong capacity")
+	}
+
+	out, err := params.Decode(make([]byte, 0, PKSize), msg, sig)
+	if err != nil {
+		t.Fatalf("Decode() returned error for valid signature: %s", err)
+	}
+	if !bytes.Equal(out, key.ComputePK()) {
+		t.Fatalf("Decode() should return the public key")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if _, err := Verify(nil, []byte{0}, make([]byte, PKSize)); err != errInvalidMsgOrSig {
+		t.Fatalf("Verify() should return error for empty message")
+	}
+
+	if _, err := Verify([]byte{0}, nil, make([]byte, PKSize)); err != errInvalidMsgOrSig {
+		t.Fatalf("Verify() should return error for empty signature")
+	}
+
+	if _, err := Verify([]byte{0}, []byte{byte(ParamsEncodingLen)}, make([]byte, PKSize)); err != errDecodingParams {
+		t.Fatalf("Verify() should return error for unknown params")
+	}
+
+	encodings := []ParamsEncoding{Fast}
+	if !testing.Short() {
+		encodings = append(encodings, Small)
+	}
+
+	for _, enc := range encodings {
+		key := NewKey(DecodeParams(enc), rand.Reader)
+		pk := key.ComputePK()
+		msg := getRandData(t, 256)
+		sig := key.Sign(msg)
+
+		if sig[0] != byte(enc) {
+			t.Fatalf("Sign() should encode params %s in the signature", enc)
+		}
+
+		ok, err := Verify(msg, sig, pk)
+		if err != nil {
+			t.Fatalf("Verify() returned error for valid %s signature: %s", enc, err)
+		}
+		if !ok {
+			t.Fatalf("Verify() should return true for valid %s signature", enc)
+		}
+
+		sig[len(sig)-1] ^= 0x01
+		if ok, _ := Verify(msg, sig, pk); ok {
+			t.Fatalf("Verify() should return false for tampered %s signature", enc)
+		}
+	}
+}
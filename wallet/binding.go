@@ -0,0 +1,82 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/wots"
+)
+
+///////////////////////////////////////////////////////////////////////
+// SLEEVE BINDING
+/*
+	The Sleeve output entropy is computed as SHA3_256(SK || PK), where
+	SK is the sleeve secret key and PK is the WOTS+ public key.
+
+	Revealing SK and PK together with the output mnemonic proves that the
+	output wallet is committed to the WOTS+ key, which is the basis for
+	redeeming a non quantum secure wallet into the quantum secure one.
+	This verification never needs the Sleeve (quantum) mnemonic.
+*/
+type BindingProof struct {
+	// Sleeve secret key
+	SecretKey []byte `json:"SleeveSecretKey"`
+	// WOTS+ public key
+	PublicKey []byte `json:"QuantumPublicKey"`
+	// Output mnemonic, committed to the WOTS+ public key
+	Output string `json:"OutputMnemonic"`
+}
+
+///////////////////////////////////////////////////////////////////////
+// Errors
+var (
+	errBindingSecretKeySize = errors.New("sleeve secret key has incorrect length")
+	errBindingPubKeySize    = errors.New(fmt.Sprintf("WOTS+ public key has incorrect length: should be %d bytes", wots.PKSize))
+)
+
+// Get the binding proof of this Sleeve
+// WARNING: The proof contains the sleeve secret key, so only
+// share it when redeeming the output wallet
+func (s *Sleeve) GetBindingProof() BindingProof {
+	return BindingProof{
+		SecretKey: s.GetSleeveSecretKey(),
+		PublicKey: s.GetQuantumPublicKey(),
+		Output:    s.GetOutputMnemonic(),
+	}
+}
+
+// Verify the binding proof
+func (b BindingProof) Verify() (bool, error) {
+	return VerifySleeveBinding(b.SecretKey, b.PublicKey, b.Output)
+}
+
+// Verify that the output mnemonic is committed to the given WOTS+ public key,
+// by recomputing SHA3_256(secretKey || wotsPK) and comparing it with the
+// output mnemonic entropy
+// Returns an error if any of the arguments is malformed
+func VerifySleeveBinding(secretKey, wotsPK []byte, outputMnemonic string) (bool, error) {
+	// 1. Validate key sizes
+	if len(secretKey) != hasher.SHA3_256.Size() {
+		return false, errBindingSecretKeySize
+	}
+	if len(wotsPK) != wots.PKSize {
+		return false, errBindingPubKeySize
+	}
+
+	// 2. Get entropy from output mnemonic (validates the mnemonic)
+	ent, err := bip39.EntropyFromMnemonic(outputMnemonic)
+	if err != nil {
+		return false, err
+	}
+
+	// 3. Recompute output entropy and compare
+	return bytes.Equal(sleeveOutput(secretKey, wotsPK), ent), nil
+}
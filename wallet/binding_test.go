@@ -0,0 +1,110 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"github.com/xx-labs/sleeve/wots"
+	"testing"
+)
+
+func TestVerifySleeveBinding(t *testing.T) {
+	sleeve, err := NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+
+	if err != nil {
+		t.Fatalf("NewSleeveFromMnemonic() shouldn't return error in valid generation")
+	}
+
+	// Test valid binding
+	valid, err := VerifySleeveBinding(sleeve.GetSleeveSecretKey(), sleeve.GetQuantumPublicKey(), expectedOutputMnemonic)
+
+	if err != nil || !valid {
+		t.Fatalf("VerifySleeveBinding() should return true for valid binding. Got (%v, %v)", valid, err)
+	}
+
+	// Test binding with a different WOTS+ public key
+	pk := sleeve.GetQuantumPublicKey()
+	pk[0] ^= 0x01
+	valid, err = VerifySleeveBinding(sleeve.GetSleeveSecretKey(), pk, expectedOutputMnemonic)
+
+	if err != nil || valid {
+		t.Fatalf("VerifySleeveBinding() should return false when WOTS+ public key is different")
+	}
+
+	// Test binding with a different secret key
+	sk := sleeve.GetSleeveSecretKey()
+	sk[0] ^= 0x01
+	valid, err = VerifySleeveBinding(sk, sleeve.GetQuantumPublicKey(), expectedOutputMnemonic)
+
+	if err != nil || valid {
+		t.Fatalf("VerifySleeveBinding() should return false when sleeve secret key is different")
+	}
+
+	// Test binding with another output mnemonic
+	other, _ := NewSleeve(rand.Reader, "", DefaultGenSpec())
+	valid, err = VerifySleeveBinding(sleeve.GetSleeveSecretKey(), sleeve.GetQuantumPublicKey(), other.GetOutputMnemonic())
+
+	if err != nil || valid {
+		t.Fatalf("VerifySleeveBinding() should return false when output mnemonic is different")
+	}
+
+	// Test wrong inputs
+	_, err = VerifySleeveBinding(sk[1:], sleeve.GetQuantumPublicKey(), expectedOutputMnemonic)
+
+	if err == nil {
+		t.Fatalf("VerifySleeveBinding() should return error when secret key has incorrect size")
+	}
+
+	_, err = VerifySleeveBinding(sleeve.GetSleeveSecretKey(), pk[:wots.PKSize-1], expectedOutputMnemonic)
+
+	if err == nil {
+		t.Fatalf("VerifySleeveBinding() should return error when WOTS+ public key has incorrect size")
+	}
+
+	_, err = VerifySleeveBinding(sleeve.GetSleeveSecretKey(), sleeve.GetQuantumPublicKey(), "xx network sleeve")
+
+	if err == nil {
+		t.Fatalf("VerifySleeveBinding() should return error when output mnemonic is invalid")
+	}
+}
+
+func TestBindingProof(t *testing.T) {
+	sleeve, err := NewSleeve(rand.Reader, "", DefaultGenSpec())
+
+	if err != nil {
+		t.Fatalf("NewSleeve() shouldn't return error in valid generation")
+	}
+
+	// Test proof from sleeve verifies
+	proof := sleeve.GetBindingProof()
+	valid, err := proof.Verify()
+
+	if err != nil || !valid {
+		t.Fatalf("BindingProof.Verify() should return true for proof generated by Sleeve. Got (%v, %v)", valid, err)
+	}
+
+	// Test proof survives JSON encoding
+	data, err := json.Marshal(proof)
+
+	if err != nil {
+		t.Fatalf("Error marshalling binding proof to JSON: %s", err)
+	}
+
+	var decoded BindingProof
+	err = json.Unmarshal(data, &decoded)
+
+	if err != nil {
+		t.Fatalf("Error unmarshalling binding proof from JSON: %s", err)
+	}
+
+	valid, err = decoded.Verify()
+
+	if err != nil || !valid {
+		t.Fatalf("BindingProof.Verify() should return true after JSON round trip. Got (%v, %v)", valid, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/internal/secmem"
 	"math/big"
 )
 
@@ -53,6 +54,7 @@ func NewMasterNode(seed []byte) (*Node, error) {
 	// Validate Private Key
 	err := validatePrivateKey(aux[:keySize])
 	if err != nil {
+		secmem.Wipe(aux)
 		return nil, err
 	}
 
@@ -64,6 +66,24 @@ func NewMasterNode(seed []byte) (*Node, error) {
 	return node, nil
 }
 
+// Copy the node, so that child derivations don't modify the original
+func (n *Node) Copy() *Node {
+	c := &Node{
+		Key:  make([]byte, len(n.Key)),
+		Code: make([]byte, len(n.Code)),
+	}
+	copy(c.Key, n.Key)
+	copy(c.Code, n.Code)
+	return c
+}
+
+// Wipe the node key and chain code
+// The node can't be used for derivations after this
+func (n *Node) Wipe() {
+	secmem.Wipe(n.Key)
+	secmem.Wipe(n.Code)
+}
+
 // Compute the hardened child node with given index
 // Place child Key and Code directly in Node (mutate)
 // Only hard derivations allowed, so idx must be >= 2^31
@@ -85,10 +105,13 @@ func (n *Node) ComputeHardenedChild(idx uint32) error {
 	h.Write(n.Key)
 	h.Write(idxBytes)
 	aux := h.Sum(nil)
+	defer secmem.Wipe(aux)
 
 	// aux[:32] + key (mod N)
 	keyInt := big.NewInt(0).SetBytes(n.Key)
 	auxInt := big.NewInt(0).SetBytes(aux[:keySize])
+	defer secmem.WipeBigInt(keyInt)
+	defer secmem.WipeBigInt(auxInt)
 	keyInt.Add(auxInt, keyInt)
 	keyInt.Mod(keyInt, N)
 
@@ -108,6 +131,7 @@ func (n *Node) ComputeHardenedChild(idx uint32) error {
 	// Place child Key and Code directly in Node (mutate)
 	copy(n.Key, b)
 	copy(n.Code, aux[keySize:])
+	secmem.Wipe(b)
 
 	return nil
 }
@@ -115,6 +139,7 @@ func (n *Node) ComputeHardenedChild(idx uint32) error {
 // Validate Private Key
 func validatePrivateKey(keyBytes []byte) error {
 	key := big.NewInt(0).SetBytes(keyBytes)
+	defer secmem.WipeBigInt(key)
 	err := validateKeyNotZero(key)
 	if err != nil {
 		return err
@@ -246,3 +246,20 @@ func TestLeadingZero(t *testing.T) {
 		t.Errorf("Failed TestLeadingZero. Got %d hardened child code %x, expected %x", leadingZeroIdx, actual.Code, expectedCode)
 	}
 }
+
+func TestNode_Wipe(t *testing.T) {
+	seed := make([]byte, 64)
+	_, _ = rand.Read(seed)
+
+	n, err := NewMasterNode(seed)
+
+	if err != nil {
+		t.Fatalf("NewMasterNode() shouldn't return error for valid seed")
+	}
+
+	n.Wipe()
+
+	if !bytes.Equal(n.Key, make([]byte, keySize)) || !bytes.Equal(n.Code, make([]byte, keySize)) {
+		t.Fatalf("Node.Wipe() didn't zero key and chain code")
+	}
+}
@@ -43,16 +43,42 @@ func ComputeNode(seed []byte, path Path) (*Node, error) {
 		return nil, errors.New("ComputeNode: path has wrong length")
 	}
 
+	// Compute account node
+	n, err := ComputeAccountNode(seed, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute nonce child
+	err = n.ComputeHardenedChild(path[pathSize-1])
+	if err != nil {
+		n.Wipe()
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// Compute BIP32 account node from seed and path
+// The account node is the parent of all nonce nodes, i.e., m/44'/1955'/account'/params'
+// The nonce index of the path is ignored
+func ComputeAccountNode(seed []byte, path Path) (*Node, error) {
+	// Check Path Size
+	if len(path) != pathSize {
+		return nil, errors.New("ComputeAccountNode: path has wrong length")
+	}
+
 	// Create Master node
 	n, err := NewMasterNode(seed)
 	if err != nil {
 		return nil, err
 	}
 
-	// Iterate path and Compute children
-	for _, idx := range path {
+	// Iterate path (excluding nonce) and Compute children
+	for _, idx := range path[:pathSize-1] {
 		err := n.ComputeHardenedChild(idx)
 		if err != nil {
+			n.Wipe()
 			return nil, err
 		}
 	}
@@ -60,6 +86,25 @@ func ComputeNode(seed []byte, path Path) (*Node, error) {
 	return n, nil
 }
 
+// Compute the BIP32 nonce node from the account node
+// The account node is not modified
+func ComputeNonceNode(account *Node, nonce uint32) (*Node, error) {
+	// Check nonce index
+	if nonce >= firstHardened {
+		return nil, errors.New("ComputeNonceNode: invalid nonce")
+	}
+
+	// Copy account node and compute nonce child
+	n := account.Copy()
+	err := n.ComputeHardenedChild(nonce | firstHardened)
+	if err != nil {
+		n.Wipe()
+		return nil, err
+	}
+
+	return n, nil
+}
+
 func (p Path) String() string {
 	str := "m"
 	for _, val := range p {
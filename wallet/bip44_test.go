@@ -7,6 +7,7 @@
 package wallet
 
 import (
+	"bytes"
 	"crypto/rand"
 	"testing"
 )
@@ -111,3 +112,54 @@ func TestComputeNode(t *testing.T) {
 		t.Fatalf("ComputeNode() should not return error for valid seed and path")
 	}
 }
+
+func TestComputeNonceNode(t *testing.T) {
+	seed := make([]byte, 64)
+	_, _ = rand.Read(seed)
+
+	// Test wrong path size
+	_, err := ComputeAccountNode(seed, []uint32{0, 0, 0})
+
+	if err == nil {
+		t.Fatalf("ComputeAccountNode() should return error when path has incorrect size")
+	}
+
+	// Compute account node
+	path, _ := NewPath(7, 1, 0)
+	account, err := ComputeAccountNode(seed, path)
+
+	if err != nil {
+		t.Fatalf("ComputeAccountNode() should not return error for valid seed and path")
+	}
+
+	accountKey := append([]byte{}, account.Key...)
+	accountCode := append([]byte{}, account.Code...)
+
+	// Test invalid nonce
+	_, err = ComputeNonceNode(account, firstHardened)
+
+	if err == nil {
+		t.Fatalf("ComputeNonceNode() should return error when nonce is too large")
+	}
+
+	// Test nonce nodes are the same as the ones computed with the full path
+	for nonce := uint32(0); nonce < 3; nonce++ {
+		n, err := ComputeNonceNode(account, nonce)
+
+		if err != nil {
+			t.Fatalf("ComputeNonceNode() should not return error for valid nonce")
+		}
+
+		path, _ = NewPath(7, 1, nonce)
+		expected, _ := ComputeNode(seed, path)
+
+		if !bytes.Equal(n.Key, expected.Key) || !bytes.Equal(n.Code, expected.Code) {
+			t.Fatalf("ComputeNonceNode() returned different node than ComputeNode() for nonce %d", nonce)
+		}
+	}
+
+	// Test account node was not modified
+	if !bytes.Equal(account.Key, accountKey) || !bytes.Equal(account.Code, accountCode) {
+		t.Fatalf("ComputeNonceNode() modified the account node")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"github.com/tyler-smith/go-bip39"
 	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/internal/secmem"
 	"github.com/xx-labs/sleeve/wots"
 	"io"
 	"strings"
@@ -57,11 +58,29 @@ const MnemonicWords = 24
 type Sleeve struct {
 	// Sleeve mnemonic: used to recover a Sleeve wallet
 	// User must store this safely for future use
-	mnemonic  string
+	mnemonic []byte
 	// Output mnemonic: used to generate/recover any non quantum secure wallets
 	// User must store this safely, but in case of loss, it can be
 	// regenerated from the Sleeve mnemonic
-	output    string
+	output []byte
+	// WOTS+ public key: the quantum secure commitment embedded in the output
+	// Can be shared at anytime without compromising the output mnemonic
+	pk []byte
+	// Sleeve secret key: used together with the WOTS+ public key to derive the output
+	// Revealing it allows anyone to link the WOTS+ public key to the output wallet
+	secretKey []byte
+	// WOTS+ key: the quantum secure key embedded in the Sleeve (nonce 0)
+	// Owned by the Sleeve and never returned, so it can only be used through SignQuantum
+	quantumKey *wots.Key
+	// BIP32 account node, m/44'/1955'/account'/params', used to derive
+	// the sequence of nonce indexed WOTS+ keys
+	account *Node
+	// WOTS+ params used for all keys of this Sleeve
+	params *wots.Params
+	// Store where the use of the WOTS+ keys is recorded, see SetStateStore
+	store wots.StateStore
+	// Flag to tell if the secret memory is locked
+	locked bool
 }
 
 // Generation spec for a Sleeve wallet
@@ -85,8 +104,14 @@ func NewGenSpec(account uint32, params wots.ParamsEncoding) GenSpec {
 	}
 }
 
+// Get the path of the Sleeve WOTS+ key, which always uses nonce 0
 func (g GenSpec) PathFromSpec() (Path, error) {
-	return NewPath(g.account, uint32(g.params), 0)
+	return g.PathWithNonce(0)
+}
+
+// Get the path of the WOTS+ key with the given nonce
+func (g GenSpec) PathWithNonce(nonce uint32) (Path, error) {
+	return NewPath(g.account, uint32(g.params), nonce)
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -147,13 +172,212 @@ func NewSleeveFromMnemonic(mnemonic, passphrase string, spec GenSpec) (*Sleeve,
 // GETTERS
 
 // Get the Sleeve's quantum secure mnemonic
+// NOTE: The returned string is a copy that can't be wiped by Destroy
 func (s *Sleeve) GetMnemonic() string {
-	return s.mnemonic
+	return string(s.mnemonic)
 }
 
 // Get the Sleeve's standard mnemonic (output)
+// NOTE: The returned string is a copy that can't be wiped by Destroy
 func (s *Sleeve) GetOutputMnemonic() string {
-	return s.output
+	return string(s.output)
+}
+
+// Get the Sleeve's WOTS+ public key (quantum secure commitment)
+func (s *Sleeve) GetQuantumPublicKey() []byte {
+	pk := make([]byte, len(s.pk))
+	copy(pk, s.pk)
+	return pk
+}
+
+// Get the Sleeve's secret key
+// WARNING: Only reveal this key when redeeming the output wallet into the
+// WOTS+ quantum secure wallet. Once it is known together with the WOTS+
+// public key, the output wallet is publicly linked to the quantum key
+func (s *Sleeve) GetSleeveSecretKey() []byte {
+	sk := make([]byte, len(s.secretKey))
+	copy(sk, s.secretKey)
+	return sk
+}
+
+///////////////////////////////////////////////////////////////////////
+// ONE-TIME USE
+/*
+	A WOTS+ key is broken if it signs two different messages, so every
+	signature of the Sleeve's WOTS+ keys is recorded in a wots.StateStore
+	before it is released, see wots.StatefulKey. Signing a different
+	message with the same key returns a *wots.KeyReuseError
+
+	By default, a Sleeve records signatures in memory, which only protects
+	the keys while the Sleeve is alive: a Sleeve created again from the
+	same mnemonic doesn't know about them. Wallets must set a durable
+	store, e.g., wots.NewFileStateStore, with SetStateStore
+*/
+
+// Set the store where the use of the Sleeve's WOTS+ keys is recorded
+// Does nothing if the store is nil
+func (s *Sleeve) SetStateStore(store wots.StateStore) {
+	if store != nil {
+		s.store = store
+	}
+}
+
+// Sign a message using the Sleeve's WOTS+ key
+// The key is built from the BIP32 node of the generation spec path,
+// using the node key and chain code as secret and public seeds
+// The resulting signature can be verified with wots.Verify
+// against the Sleeve's quantum public key
+// Returns an error if the Sleeve was destroyed, and a *wots.KeyReuseError
+// if the key was already used to sign a different message
+func (s *Sleeve) SignQuantum(msg []byte) ([]byte, error) {
+	if s.account == nil {
+		return nil, errSleeveDestroyed
+	}
+	return wots.NewStatefulKey(s.quantumKey, s.store).Sign(msg)
+}
+
+///////////////////////////////////////////////////////////////////////
+// NONCE INDEXED KEYS
+/*
+	Each WOTS+ key can only sign ONE message. In order to sign multiple
+	times over the lifetime of an account, a sequence of WOTS+ keys is
+	derived from the account node, using the nonce level of the path
+	m/44'/1955'/account'/params'/nonce'
+
+	The Sleeve output is always committed to the key with nonce 0, so
+	QuantumKeyAt(0) returns the same key used by SignQuantum
+
+	QuantumKeyAt derives a new key on each call, which is owned by the
+	caller and should be destroyed after use. QuantumPublicKeyAt and
+	SignQuantumAt destroy the keys they derive
+
+	SignQuantumAt records its signatures in the Sleeve's state store, like
+	SignQuantum, so signing a second message with the same nonce returns
+	a *wots.KeyReuseError. Keys returned by QuantumKeyAt are NOT tracked,
+	and callers signing with them must enforce one-time use themselves,
+	e.g., with wots.NewStatefulKey
+*/
+
+// Get a new WOTS+ key with the given nonce
+// The caller owns the key, and should call Destroy on it when done
+func (s *Sleeve) QuantumKeyAt(nonce uint32) (*wots.Key, error) {
+	if s.account == nil {
+		return nil, errSleeveDestroyed
+	}
+
+	// Derive nonce node from account node
+	node, err := ComputeNonceNode(s.account, nonce)
+	if err != nil {
+		return nil, err
+	}
+	defer node.Wipe()
+
+	return wots.NewKeyFromSeed(s.params, node.Key, node.Code), nil
+}
+
+// Get the WOTS+ public key with the given nonce
+func (s *Sleeve) QuantumPublicKeyAt(nonce uint32) ([]byte, error) {
+	key, err := s.QuantumKeyAt(nonce)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	pk := make([]byte, wots.PKSize)
+	copy(pk, key.ComputePK())
+	return pk, nil
+}
+
+// Sign a message using the WOTS+ key with the given nonce
+// Returns a *wots.KeyReuseError if the key with the given nonce was already
+// used to sign a different message
+func (s *Sleeve) SignQuantumAt(nonce uint32, msg []byte) ([]byte, error) {
+	key, err := s.QuantumKeyAt(nonce)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	return wots.NewStatefulKey(key, s.store).Sign(msg)
+}
+
+///////////////////////////////////////////////////////////////////////
+// KEY MATERIAL LIFETIME
+/*
+	A Sleeve keeps its mnemonics, secret key, BIP32 account node and
+	WOTS+ key in memory for its whole life. Destroy wipes all of them,
+	and unlocks the memory locked by LockMemory, after which the Sleeve
+	can't be used anymore
+
+	Strings can't be wiped in Go, so the mnemonic passed to the
+	constructors and the strings returned by GetMnemonic and
+	GetOutputMnemonic are NOT wiped. Callers handling secrets
+	should keep those strings alive for as short as possible
+*/
+
+var errSleeveDestroyed = errors.New("sleeve was destroyed")
+
+// Wipe all the secret material of the Sleeve
+// After this, the mnemonic getters return empty strings, and no
+// WOTS+ keys can be derived or used for signing
+func (s *Sleeve) Destroy() {
+	if s.account == nil {
+		return
+	}
+
+	// 1. Wipe secrets
+	secrets := s.secrets()
+	secmem.WipeAll(secrets)
+	s.quantumKey.Destroy()
+
+	// 2. Unlock memory, the WOTS+ key unlocks its own
+	if s.locked {
+		unlockAll(secrets)
+	}
+
+	// 3. Drop references
+	s.mnemonic = nil
+	s.output = nil
+	s.secretKey = nil
+	s.account = nil
+	s.quantumKey = nil
+	s.locked = false
+}
+
+// Lock the memory holding the Sleeve's secrets, preventing it from being
+// swapped to disk (Linux only). The WOTS+ key memory is also locked
+// Locked memory is unlocked by Destroy
+// Returns secmem.ErrLockNotSupported on platforms other than Linux
+func (s *Sleeve) LockMemory() error {
+	if s.account == nil {
+		return errSleeveDestroyed
+	}
+	if s.locked {
+		return nil
+	}
+	secrets := s.secrets()
+	for i, b := range secrets {
+		if err := secmem.Lock(b); err != nil {
+			unlockAll(secrets[:i])
+			return err
+		}
+	}
+	if err := s.quantumKey.LockMemory(); err != nil {
+		unlockAll(secrets)
+		return err
+	}
+	s.locked = true
+	return nil
+}
+
+// Get the buffers holding the Sleeve's secrets, except for the WOTS+ key
+func (s *Sleeve) secrets() [][]byte {
+	return [][]byte{s.mnemonic, s.output, s.secretKey, s.account.Key, s.account.Code}
+}
+
+// Unlock the buffers, ignoring errors
+func unlockAll(buffers [][]byte) {
+	for _, b := range buffers {
+		_ = secmem.Unlock(b)
+	}
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -167,6 +391,7 @@ func generateSleeveFromMnemonic(mnemonic, passphrase string, spec GenSpec) (*Sle
 	if err != nil {
 		return nil, err
 	}
+	defer secmem.Wipe(seed)
 
 	// 2. Get path and wots params from GenSpec
 	path, err := spec.PathFromSpec()
@@ -179,21 +404,34 @@ func generateSleeveFromMnemonic(mnemonic, passphrase string, spec GenSpec) (*Sle
 	}
 
 	// 3. Derive seeds using BIP32 and path
-	node, err := ComputeNode(seed, path)
+	account, err := ComputeAccountNode(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := ComputeNonceNode(account, 0)
 	if err != nil {
+		account.Wipe()
 		return nil, err
 	}
+	defer node.Wipe()
 
 	// 4. Generate sleeve
-	out := generateSleeve(node.Key, node.Code, params)
+	out, wotsKey, secretKey := generateSleeve(node.Key, node.Code, params)
+	defer secmem.Wipe(out)
 
 	// 5. Encode output into BIP39 mnemonic
 	outMnem, _ := bip39.NewMnemonic(out)
 
 	// 6. Create sleeve
 	s := &Sleeve{
-		mnemonic:  mnemonic,
-		output:    outMnem,
+		mnemonic:   []byte(mnemonic),
+		output:     []byte(outMnem),
+		pk:         wotsKey.GetPK(),
+		secretKey:  secretKey,
+		quantumKey: wotsKey,
+		account:    account,
+		params:     params,
+		store:      wots.NewMemoryStateStore(),
 	}
 	return s, nil
 }
@@ -201,8 +439,8 @@ func generateSleeveFromMnemonic(mnemonic, passphrase string, spec GenSpec) (*Sle
 // Generate a Sleeve
 // Takes secret seed and public seed as input
 // Generates WOTS+ key from the seeds and also a sleeve secret key
-// Returns the sleeve output entropy
-func generateSleeve(secretSeed, publicSeed []byte, params *wots.Params) []byte {
+// Returns the sleeve output entropy, the WOTS+ key and the sleeve secret key
+func generateSleeve(secretSeed, publicSeed []byte, params *wots.Params) ([]byte, *wots.Key, []byte) {
 	// 1. Generate WOTS+ key from seed and public seed
 	wotsKey := wots.NewKeyFromSeed(params, secretSeed, publicSeed)
 
@@ -210,6 +448,25 @@ func generateSleeve(secretSeed, publicSeed []byte, params *wots.Params) []byte {
 	pk := wotsKey.ComputePK()
 
 	// 3. Derive Sleeve secret key and return output
-	secretKey := hasher.SHA3_256.Hash(append([]byte("xx network sleeve"), secretSeed...))
-	return hasher.SHA3_256.Hash(append(secretKey, pk...))
+	secretKey := sleeveSecretKey(secretSeed)
+	out := sleeveOutput(secretKey, pk)
+	return out, wotsKey, secretKey
+}
+
+// Derive the sleeve secret key from the WOTS+ secret seed
+// SK = SHA3_256("xx network sleeve" || secretSeed)
+func sleeveSecretKey(secretSeed []byte) []byte {
+	data := append([]byte("xx network sleeve"), secretSeed...)
+	defer secmem.Wipe(data)
+	return hasher.SHA3_256.Hash(data)
+}
+
+// Compute the sleeve output entropy from the sleeve secret key and WOTS+ public key
+// Output = SHA3_256(SK || PK)
+func sleeveOutput(secretKey, pk []byte) []byte {
+	data := make([]byte, 0, len(secretKey)+len(pk))
+	data = append(data, secretKey...)
+	data = append(data, pk...)
+	defer secmem.Wipe(data)
+	return hasher.SHA3_256.Hash(data)
 }
@@ -21,7 +21,8 @@ func generateWOTSKey(seed, pSeed []byte) {
 }
 
 func generateSleeveECDSA(seed, pSeed []byte) {
-	generateECDSAFromPriv(generateSleeve(seed, pSeed, wots.DecodeParams(wots.DefaultParams)))
+	out, _, _ := generateSleeve(seed, pSeed, wots.DecodeParams(wots.DefaultParams))
+	generateECDSAFromPriv(out)
 }
 
 func BenchmarkSleeve_GenerateECDSA(b *testing.B) {
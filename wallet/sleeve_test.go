@@ -155,6 +155,21 @@ func TestSleeve_Getters(t *testing.T) {
 	if sleeve.GetOutputMnemonic() == "" {
 		t.Fatalf("GetOutputMnemonic() returned empty string after Sleeve generation")
 	}
+
+	if len(sleeve.GetQuantumPublicKey()) != wots.PKSize {
+		t.Fatalf("GetQuantumPublicKey() returned public key of wrong size after Sleeve generation")
+	}
+
+	if len(sleeve.GetSleeveSecretKey()) != hasher.SHA3_256.Size() {
+		t.Fatalf("GetSleeveSecretKey() returned secret key of wrong size after Sleeve generation")
+	}
+
+	// Make sure getters return copies
+	sleeve.GetQuantumPublicKey()[0] ^= 0xFF
+	sleeve.GetSleeveSecretKey()[0] ^= 0xFF
+	if !bytes.Equal(sleeve.GetQuantumPublicKey(), sleeve.pk) || !bytes.Equal(sleeve.GetSleeveSecretKey(), sleeve.secretKey) {
+		t.Fatalf("Sleeve getters should return copies of the keys")
+	}
 }
 
 // Test vector taken from https://github.com/trezor/python-mnemonic/blob/master/vectors.json
@@ -212,6 +227,18 @@ func TestSleeve_Consistency(t *testing.T) {
 		t.Fatalf("Consistency violation! GetOutputMnemonic() returned wrong output mnemonic."+
 			" Got: %s\nExpected: %s\n", sleeve.GetOutputMnemonic(), outMnem)
 	}
+
+	// Compare WOTS+ public key
+	if !bytes.Equal(sleeve.GetQuantumPublicKey(), pk) {
+		t.Fatalf("Consistency violation! GetQuantumPublicKey() returned wrong public key."+
+			" Got: %x\nExpected: %x\n", sleeve.GetQuantumPublicKey(), pk)
+	}
+
+	// Compare sleeve secret key
+	if !bytes.Equal(sleeve.GetSleeveSecretKey(), key) {
+		t.Fatalf("Consistency violation! GetSleeveSecretKey() returned wrong secret key."+
+			" Got: %x\nExpected: %x\n", sleeve.GetSleeveSecretKey(), key)
+	}
 }
 
 const (
@@ -238,3 +265,236 @@ func TestSleeve_WOTSTestVector(t *testing.T) {
 			pk, expectedPk)
 	}
 }
+
+func TestSleeve_SignQuantum(t *testing.T) {
+	sleeve, err := NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+
+	if err != nil {
+		t.Fatalf("NewSleeveFromMnemonic() shouldn't return error in valid generation")
+	}
+
+	// Sign a message with the Sleeve's WOTS+ key and verify it against the quantum public key
+	msg := []byte("xx network quantum secure transaction")
+	sig, err := sleeve.SignQuantum(msg)
+
+	if err != nil {
+		t.Fatalf("Sleeve.SignQuantum() shouldn't return error for valid sleeve: %s", err)
+	}
+
+	valid, err := wots.Verify(msg, sig, sleeve.GetQuantumPublicKey())
+
+	if err != nil || !valid {
+		t.Fatalf("Sleeve.SignQuantum() + wots.Verify() are not consistent! Got (%v, %v)", valid, err)
+	}
+
+	// Make sure signature doesn't verify for a different message
+	valid, _ = wots.Verify([]byte("xx network forged transaction"), sig, sleeve.GetQuantumPublicKey())
+
+	if valid {
+		t.Fatalf("wots.Verify() should return false when message is different")
+	}
+
+	// Manually rebuild the WOTS+ key from the BIP32 node and compare signatures
+	// Path = m/44'/1955'/0'/0'/0'
+	n, _ := ComputeNode(bip39.NewSeed(testVectorMnemonic, ""), []uint32{0x8000002C, 0x800007A3, 0x80000000, 0x80000000, 0x80000000})
+	wotsKey := wots.NewKeyFromSeed(wots.DecodeParams(wots.DefaultParams), n.Key, n.Code)
+
+	if !bytes.Equal(wotsKey.Sign(msg), sig) {
+		t.Fatalf("Sleeve.SignQuantum() returned a different signature than the WOTS+ key derived from the BIP32 node")
+	}
+
+	if !bytes.Equal(sleeve.GetQuantumPublicKey(), wotsKey.ComputePK()) {
+		t.Fatalf("Sleeve has a different public key than the WOTS+ key derived from the BIP32 node")
+	}
+}
+
+func TestSleeve_SignQuantum_OneTime(t *testing.T) {
+	sleeve, _ := NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+	store := wots.NewMemoryStateStore()
+	sleeve.SetStateStore(store)
+	sleeve.SetStateStore(nil)
+
+	// The same message can be signed again
+	msg := []byte("xx network quantum secure transaction")
+	sig, err := sleeve.SignQuantum(msg)
+	if err != nil {
+		t.Fatalf("Sleeve.SignQuantum() shouldn't return error on first use: %s", err)
+	}
+	if sig2, err := sleeve.SignQuantum(msg); err != nil || !bytes.Equal(sig, sig2) {
+		t.Fatalf("Sleeve.SignQuantum() should allow signing the same message again. Got error: %v", err)
+	}
+
+	// A different message is refused
+	var reuse *wots.KeyReuseError
+	if sig2, err := sleeve.SignQuantum([]byte("xx network second transaction")); sig2 != nil || !errors.As(err, &reuse) {
+		t.Fatalf("Sleeve.SignQuantum() should return KeyReuseError for a second message. Got: %v", err)
+	}
+
+	// Also by a new Sleeve sharing the store
+	sleeve, _ = NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+	sleeve.SetStateStore(store)
+	if _, err = sleeve.SignQuantum([]byte("xx network second transaction")); !errors.As(err, &reuse) {
+		t.Fatalf("Sleeve.SignQuantum() should return KeyReuseError for a key used by another Sleeve. Got: %v", err)
+	}
+}
+
+func TestSleeve_QuantumKeyAt(t *testing.T) {
+	spec := NewGenSpec(3, wots.Level1)
+	sleeve, err := NewSleeveFromMnemonic(testVectorMnemonic, "", spec)
+
+	if err != nil {
+		t.Fatalf("NewSleeveFromMnemonic() shouldn't return error in valid generation")
+	}
+
+	// Test nonce 0 is the Sleeve's key
+	key, err := sleeve.QuantumKeyAt(0)
+
+	if err != nil || !bytes.Equal(key.ComputePK(), sleeve.GetQuantumPublicKey()) {
+		t.Fatalf("QuantumKeyAt(0) should return the Sleeve's WOTS+ key")
+	}
+
+	// Destroying the returned key doesn't affect the Sleeve
+	key.Destroy()
+
+	if _, err = sleeve.SignQuantum([]byte("xx network")); err != nil {
+		t.Fatalf("SignQuantum() should work after destroying the key returned by QuantumKeyAt(0): %s", err)
+	}
+
+	pk, _ := sleeve.QuantumPublicKeyAt(0)
+
+	if !bytes.Equal(pk, sleeve.GetQuantumPublicKey()) {
+		t.Fatalf("QuantumPublicKeyAt(0) should return the Sleeve's WOTS+ public key")
+	}
+
+	// Test nonce keys match manual derivation and are all different
+	seed := bip39.NewSeed(testVectorMnemonic, "")
+	seen := map[string]bool{hex.EncodeToString(pk): true}
+	for nonce := uint32(1); nonce < 4; nonce++ {
+		pk, err = sleeve.QuantumPublicKeyAt(nonce)
+
+		if err != nil {
+			t.Fatalf("QuantumPublicKeyAt() shouldn't return error for valid nonce")
+		}
+
+		path, _ := spec.PathWithNonce(nonce)
+		n, _ := ComputeNode(seed, path)
+		expected := wots.NewKeyFromSeed(wots.DecodeParams(wots.Level1), n.Key, n.Code).ComputePK()
+
+		if !bytes.Equal(pk, expected) {
+			t.Fatalf("QuantumPublicKeyAt(%d) returned wrong public key. Got: %x\nExpected: %x\n", nonce, pk, expected)
+		}
+
+		if seen[hex.EncodeToString(pk)] {
+			t.Fatalf("QuantumPublicKeyAt(%d) returned a repeated public key", nonce)
+		}
+		seen[hex.EncodeToString(pk)] = true
+
+		// Test signing with nonce key
+		msg := []byte("xx network quantum secure transaction")
+		sig, err := sleeve.SignQuantumAt(nonce, msg)
+
+		if err != nil {
+			t.Fatalf("SignQuantumAt() shouldn't return error for valid nonce")
+		}
+
+		valid, err := wots.Verify(msg, sig, pk)
+
+		if err != nil || !valid {
+			t.Fatalf("SignQuantumAt() + wots.Verify() are not consistent for nonce %d", nonce)
+		}
+	}
+
+	// Test nonce keys can only sign one message, also with SignQuantumAt(0)
+	var reuse *wots.KeyReuseError
+	for nonce := uint32(0); nonce < 4; nonce++ {
+		if _, err = sleeve.SignQuantumAt(nonce, []byte("xx network second transaction")); !errors.As(err, &reuse) {
+			t.Fatalf("SignQuantumAt(%d) should return KeyReuseError for a second message. Got: %v", nonce, err)
+		}
+	}
+
+	// Test invalid nonce
+	_, err = sleeve.QuantumKeyAt(firstHardened)
+
+	if err == nil {
+		t.Fatalf("QuantumKeyAt() should return error when nonce is too large")
+	}
+
+	_, err = sleeve.QuantumPublicKeyAt(firstHardened)
+
+	if err == nil {
+		t.Fatalf("QuantumPublicKeyAt() should return error when nonce is too large")
+	}
+
+	_, err = sleeve.SignQuantumAt(firstHardened, []byte("xx network"))
+
+	if err == nil {
+		t.Fatalf("SignQuantumAt() should return error when nonce is too large")
+	}
+}
+
+func TestSleeve_Destroy(t *testing.T) {
+	sleeve, err := NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+
+	if err != nil {
+		t.Fatalf("NewSleeveFromMnemonic() shouldn't return error in valid generation")
+	}
+
+	// Keep references to the secret memory
+	secrets := [][]byte{sleeve.mnemonic, sleeve.output, sleeve.secretKey, sleeve.account.Key, sleeve.account.Code}
+
+	sleeve.Destroy()
+
+	for _, b := range secrets {
+		if !bytes.Equal(b, make([]byte, len(b))) {
+			t.Fatalf("Sleeve.Destroy() didn't wipe all secrets")
+		}
+	}
+
+	if sleeve.GetMnemonic() != "" || sleeve.GetOutputMnemonic() != "" {
+		t.Fatalf("Sleeve mnemonic getters should return empty strings after Destroy()")
+	}
+
+	if sig, err := sleeve.SignQuantum([]byte("msg")); sig != nil || err != errSleeveDestroyed {
+		t.Fatalf("SignQuantum() should return errSleeveDestroyed after Destroy()")
+	}
+
+	for _, nonce := range []uint32{0, 1} {
+		if key, err := sleeve.QuantumKeyAt(nonce); key != nil || err != errSleeveDestroyed {
+			t.Fatalf("QuantumKeyAt(%d) should return errSleeveDestroyed after Destroy()", nonce)
+		}
+		if sig, err := sleeve.SignQuantumAt(nonce, []byte("msg")); sig != nil || err != errSleeveDestroyed {
+			t.Fatalf("SignQuantumAt(%d) should return errSleeveDestroyed after Destroy()", nonce)
+		}
+		if pk, err := sleeve.QuantumPublicKeyAt(nonce); pk != nil || err != errSleeveDestroyed {
+			t.Fatalf("QuantumPublicKeyAt(%d) should return errSleeveDestroyed after Destroy()", nonce)
+		}
+	}
+
+	if err := sleeve.LockMemory(); err == nil {
+		t.Fatalf("LockMemory() should return error after Destroy()")
+	}
+}
+
+func TestSleeve_LockMemory(t *testing.T) {
+	sleeve, _ := NewSleeveFromMnemonic(testVectorMnemonic, "", DefaultGenSpec())
+	if err := sleeve.LockMemory(); err != nil {
+		t.Skipf("Sleeve.LockMemory() is not available: %s", err)
+	}
+	if !sleeve.locked {
+		t.Fatalf("Sleeve.LockMemory() should set the locked flag")
+	}
+
+	// Locking again does nothing
+	if err := sleeve.LockMemory(); err != nil {
+		t.Fatalf("Sleeve.LockMemory() shouldn't return error when already locked: %s", err)
+	}
+
+	// Destroy unlocks the memory and drops the WOTS+ key
+	sleeve.Destroy()
+	if sleeve.locked || sleeve.quantumKey != nil {
+		t.Fatalf("Sleeve.Destroy() should unlock the memory and drop the WOTS+ key")
+	}
+
+	// Destroying again does nothing
+	sleeve.Destroy()
+}
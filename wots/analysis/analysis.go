@@ -0,0 +1,265 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/wots"
+	"io"
+)
+
+///////////////////////////////////////////////////////////////////////
+// WOTS+ KEY REUSE ANALYSIS
+/*
+	A WOTS+ signature reveals the ladder value at the signed position of
+	each ladder. Anyone can walk a ladder further, so the value at position
+	x gives the values at all positions from x to w-1. One signature is
+	safe, since the checksum ladders (or the target sum) make any other
+	message need a lower position on some ladder
+
+	When a key signs more than once, the lowest signed position of each
+	ladder is exposed, and any message whose positions are all at or above
+	the exposed ones can be forged without the secret key
+
+	Analyze computes the exposed positions from the leaked signatures of
+	a public key. TestMessage checks if a target message can be forged,
+	and Sample estimates the fraction of random messages that can be forged
+
+	With randomized message hashing or a target sum, the forger chooses the
+	randomizer or counter of the forged signature, so each message can be
+	tried many times with different positions. Each try takes one or two
+	hashes, so the number of tries models the work of the forger
+*/
+
+// A signed message, with the serialized signature including the params encoding
+type Leak struct {
+	Msg       []byte
+	Signature []byte
+}
+
+// The exposed ladder positions of a WOTS+ public key
+type Exposure struct {
+	// Params of the signatures
+	params *wots.Params
+	enc    wots.ParamsEncoding
+	// Public seed of the key
+	pSeed []byte
+	// Size of the randomizer and counter chosen by the forger
+	free int
+	// Lowest signed position of each ladder
+	exposed []byte
+	// Number of leaked signatures
+	signatures int
+}
+
+// The result of trying to forge a message
+type Forgery struct {
+	// True if a signature of the message can be forged
+	Forgeable bool
+	// Tries until the message could be forged, or all tries if it couldn't
+	Tries int
+	// Ladders whose position is below the exposed one, in the try with the fewest of them
+	// Nil if no try was a valid signature position, e.g., no try reached the target sum
+	Blocked []int
+}
+
+// The result of trying to forge random messages
+type Sample struct {
+	// Number of random messages
+	Messages int
+	// Number of messages that could be forged
+	Forgeable int
+}
+
+var errNoLeaks = errors.New("no leaked signatures to analyze")
+
+///////////////////////////////////////////////////////////////////////
+// ANALYSIS
+
+// Compute the exposed ladder positions of the public key from its leaked signatures
+// Returns an error if a signature can't be parsed or doesn't verify with the public key
+func Analyze(pubkey []byte, leaks []Leak) (*Exposure, error) {
+	// 1. Check input
+	if len(leaks) == 0 {
+		return nil, errNoLeaks
+	}
+
+	var e *Exposure
+	for i, leak := range leaks {
+		// 2. Parse and verify signature
+		sig, err := wots.ParseSignature(leak.Signature)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("error parsing signature %d: %s", i, err))
+		}
+		if ok, err := sig.Verify(leak.Msg, pubkey); !ok {
+			if err == nil {
+				err = errors.New("invalid signature")
+			}
+			return nil, errors.New(fmt.Sprintf("signature %d doesn't verify with the public key: %s", i, err))
+		}
+
+		// 3. Get signed positions
+		positions, err := sig.Positions(leak.Msg)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("error getting positions of signature %d: %s", i, err))
+		}
+
+		// 4. Keep the lowest position of each ladder
+		if e == nil {
+			e = &Exposure{
+				params:  sig.Params(),
+				enc:     sig.Encoding(),
+				pSeed:   sig.PublicSeed(),
+				free:    len(sig.Randomizer()) + len(sig.Counter()),
+				exposed: positions,
+			}
+		} else if sig.Encoding() != e.enc {
+			return nil, errors.New(fmt.Sprintf("signature %d has params %s, expected %s", i, sig.Encoding(), e.enc))
+		}
+		for j, pos := range positions {
+			if pos < e.exposed[j] {
+				e.exposed[j] = pos
+			}
+		}
+		e.signatures++
+	}
+	return e, nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// GETTERS
+
+// Get the params of the leaked signatures
+func (e *Exposure) Params() *wots.Params {
+	return e.params
+}
+
+// Get the params encoding of the leaked signatures
+func (e *Exposure) Encoding() wots.ParamsEncoding {
+	return e.enc
+}
+
+// Get the number of leaked signatures
+func (e *Exposure) Signatures() int {
+	return e.signatures
+}
+
+// Get the lowest exposed position of each ladder
+// The values of ladder i are exposed from Positions()[i] to the end of the ladder
+func (e *Exposure) Positions() []int {
+	positions := make([]int, len(e.exposed))
+	for i, pos := range e.exposed {
+		positions[i] = int(pos)
+	}
+	return positions
+}
+
+// Get if the forger chooses part of the signature, i.e., the randomizer or counter,
+// so each message can be tried more than once
+func (e *Exposure) Grindable() bool {
+	return e.free > 0
+}
+
+///////////////////////////////////////////////////////////////////////
+// FORGERY
+
+// Try to forge a signature of the message, with at most tries different
+// randomizers or counters, read from rng
+// For params where the forger can't choose any part of the signature, only one try is made,
+// and rng isn't used
+func (e *Exposure) TestMessage(msg []byte, tries int, rng io.Reader) (Forgery, error) {
+	if !e.Grindable() || tries < 1 {
+		tries = 1
+	}
+	var result Forgery
+	for result.Tries < tries {
+		result.Tries++
+
+		// 1. Get the positions for this try
+		positions, err := e.positions(msg, rng)
+		if err != nil {
+			return Forgery{}, err
+		}
+		if positions == nil {
+			continue
+		}
+
+		// 2. Check positions against the exposed ones
+		blocked := e.blocked(positions)
+		if result.Blocked == nil || len(blocked) < len(result.Blocked) {
+			result.Blocked = blocked
+		}
+		if len(blocked) == 0 {
+			result.Forgeable = true
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// Try to forge signatures of random messages of 32 bytes, read from rng,
+// with at most tries tries per message, see TestMessage
+func (e *Exposure) Sample(messages, tries int, rng io.Reader) (Sample, error) {
+	result := Sample{Messages: messages}
+	msg := make([]byte, 32)
+	for i := 0; i < messages; i++ {
+		if _, err := io.ReadFull(rng, msg); err != nil {
+			return Sample{}, err
+		}
+		forgery, err := e.TestMessage(msg, tries, rng)
+		if err != nil {
+			return Sample{}, err
+		}
+		if forgery.Forgeable {
+			result.Forgeable++
+		}
+	}
+	return result, nil
+}
+
+// Get the fraction of random messages that could be forged
+func (s Sample) Rate() float64 {
+	if s.Messages == 0 {
+		return 0
+	}
+	return float64(s.Forgeable) / float64(s.Messages)
+}
+
+// Get the positions of a signature of msg, with the randomizer and counter read from rng
+// Returns nil positions if the counter doesn't reach the target sum
+func (e *Exposure) positions(msg []byte, rng io.Reader) ([]byte, error) {
+	// Signature with the public seed of the key, and any ladder points
+	// The randomizer and counter follow the public seed, see wots.Signature
+	data := make([]byte, wots.SignatureSize(e.enc))
+	data[0] = byte(e.enc)
+	copy(data[1:], e.pSeed)
+	if _, err := io.ReadFull(rng, data[1+wots.SeedSize:1+wots.SeedSize+e.free]); err != nil {
+		return nil, err
+	}
+	sig, err := wots.ParseSignature(data)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := sig.Positions(msg)
+	if err != nil {
+		// Only the target sum can't be reached
+		return nil, nil
+	}
+	return positions, nil
+}
+
+// Get the ladders whose position is below the exposed one
+func (e *Exposure) blocked(positions []byte) []int {
+	blocked := make([]int, 0)
+	for i, pos := range positions {
+		if pos < e.exposed[i] {
+			blocked = append(blocked, i)
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,206 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package analysis
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/wots"
+	mrand "math/rand"
+	"testing"
+)
+
+// Small params, so that forgeries are likely after a few signatures
+const (
+	testEncoding          wots.ParamsEncoding = 0xA0
+	testTargetSumEncoding wots.ParamsEncoding = 0xA1
+)
+
+func init() {
+	security := wots.SecurityLevels{Classical: 16, PostQuantum: 8}
+	if err := wots.RegisterParams(testEncoding, wots.NewParamsW(32, 2, 4, hasher.BLAKE3_256, hasher.SHA3_256),
+		"AnalysisTest", security); err != nil {
+		panic(err)
+	}
+	if err := wots.RegisterParams(testTargetSumEncoding,
+		wots.NewParamsTargetSum(32, 2, 4, hasher.BLAKE3_256, hasher.SHA3_256),
+		"AnalysisTestTargetSum", security); err != nil {
+		panic(err)
+	}
+}
+
+// Sign n random messages with the key
+func leakSignatures(t *testing.T, key *wots.Key, n int) []Leak {
+	leaks := make([]Leak, n)
+	for i := range leaks {
+		msg := make([]byte, 32)
+		_, _ = rand.Read(msg)
+		leaks[i] = Leak{Msg: msg, Signature: key.Sign(msg)}
+		if leaks[i].Signature == nil {
+			t.Fatalf("Key.Sign() returned nil signature")
+		}
+	}
+	return leaks
+}
+
+func TestAnalyze(t *testing.T) {
+	key := wots.NewKey(wots.DecodeParams(testEncoding), rand.Reader)
+	pk := key.ComputePK()
+	leaks := leakSignatures(t, key, 3)
+
+	e, err := Analyze(pk, leaks)
+	if err != nil {
+		t.Fatalf("Analyze() returned error: %s", err)
+	}
+
+	if e.Signatures() != 3 || e.Encoding() != testEncoding || !e.Params().Equal(wots.DecodeParams(testEncoding)) {
+		t.Fatalf("Analyze() returned wrong signatures or params")
+	}
+
+	if e.Grindable() {
+		t.Fatalf("Grindable() should return false for params with checksum ladders")
+	}
+
+	// Exposed positions are the lowest signed positions
+	exposed := e.Positions()
+	for i := range exposed {
+		lowest := -1
+		for _, leak := range leaks {
+			sig, _ := wots.ParseSignature(leak.Signature)
+			positions, _ := sig.Positions(leak.Msg)
+			if lowest == -1 || int(positions[i]) < lowest {
+				lowest = int(positions[i])
+			}
+		}
+		if exposed[i] != lowest {
+			t.Fatalf("Positions() returned %d for ladder %d, expected %d", exposed[i], i, lowest)
+		}
+	}
+}
+
+func TestAnalyze_Errors(t *testing.T) {
+	key := wots.NewKey(wots.DecodeParams(testEncoding), rand.Reader)
+	pk := key.ComputePK()
+	leaks := leakSignatures(t, key, 2)
+
+	if _, err := Analyze(pk, nil); err != errNoLeaks {
+		t.Fatalf("Analyze() should return error when there are no leaks")
+	}
+
+	if _, err := Analyze(pk, []Leak{{Msg: leaks[0].Msg, Signature: leaks[0].Signature[1:]}}); err == nil {
+		t.Fatalf("Analyze() should return error for invalid signature")
+	}
+
+	if _, err := Analyze(pk, []Leak{{Msg: leaks[1].Msg, Signature: leaks[0].Signature}}); err == nil {
+		t.Fatalf("Analyze() should return error for signature of a different message")
+	}
+
+	other := wots.NewKey(wots.DecodeParams(testEncoding), rand.Reader)
+	if _, err := Analyze(other.ComputePK(), leaks); err == nil {
+		t.Fatalf("Analyze() should return error for signatures of a different key")
+	}
+}
+
+func TestExposure_TestMessage(t *testing.T) {
+	key := wots.NewKey(wots.DecodeParams(testEncoding), rand.Reader)
+	leaks := leakSignatures(t, key, 4)
+	e, err := Analyze(key.ComputePK(), leaks)
+	if err != nil {
+		t.Fatalf("Analyze() returned error: %s", err)
+	}
+
+	// Signed messages can always be forged
+	for _, leak := range leaks {
+		forgery, err := e.TestMessage(leak.Msg, 10, nil)
+		if err != nil || !forgery.Forgeable || forgery.Tries != 1 || len(forgery.Blocked) != 0 {
+			t.Fatalf("TestMessage() should return forgeable after 1 try for a signed message: %v", err)
+		}
+	}
+
+	// Other messages can be forged if all their positions are at least the exposed ones
+	exposed := e.Positions()
+	for i := 0; i < 256; i++ {
+		msg := []byte{byte(i)}
+		forgery, _ := e.TestMessage(msg, 10, nil)
+		sig, _ := wots.ParseSignature(key.Sign(msg))
+		positions, _ := sig.Positions(msg)
+		var blocked []int
+		for j, pos := range positions {
+			if int(pos) < exposed[j] {
+				blocked = append(blocked, j)
+			}
+		}
+		if forgery.Forgeable != (len(blocked) == 0) || len(forgery.Blocked) != len(blocked) {
+			t.Fatalf("TestMessage() returned %v with blocked ladders %v, expected %v", forgery.Forgeable,
+				forgery.Blocked, blocked)
+		}
+		for j := range blocked {
+			if forgery.Blocked[j] != blocked[j] {
+				t.Fatalf("TestMessage() returned blocked ladders %v, expected %v", forgery.Blocked, blocked)
+			}
+		}
+	}
+}
+
+func TestExposure_Sample(t *testing.T) {
+	key := wots.NewKey(wots.DecodeParams(testEncoding), rand.Reader)
+	rng := mrand.New(mrand.NewSource(1))
+
+	// One signature can't be used to forge other messages
+	e, _ := Analyze(key.ComputePK(), leakSignatures(t, key, 1))
+	sample, err := e.Sample(1000, 1, rng)
+	if err != nil || sample.Messages != 1000 || sample.Forgeable != 0 || sample.Rate() != 0 {
+		t.Fatalf("Sample() should return no forgeable messages for a single signature: %+v, %v", sample, err)
+	}
+
+	// More signatures expose more positions
+	e, _ = Analyze(key.ComputePK(), leakSignatures(t, key, 16))
+	sample, err = e.Sample(1000, 1, rng)
+	if err != nil || sample.Forgeable == 0 || sample.Rate() != float64(sample.Forgeable)/1000 {
+		t.Fatalf("Sample() should return forgeable messages after 16 signatures: %+v, %v", sample, err)
+	}
+
+	if (Sample{}).Rate() != 0 {
+		t.Fatalf("Rate() should return 0 for an empty sample")
+	}
+}
+
+func TestExposure_TargetSum(t *testing.T) {
+	key := wots.NewKey(wots.DecodeParams(testTargetSumEncoding), rand.Reader)
+	leaks := leakSignatures(t, key, 4)
+	e, err := Analyze(key.ComputePK(), leaks)
+	if err != nil {
+		t.Fatalf("Analyze() returned error: %s", err)
+	}
+
+	if !e.Grindable() {
+		t.Fatalf("Grindable() should return true for target sum params")
+	}
+
+	// The forger grinds the counter, so more tries forge more messages
+	rng := mrand.New(mrand.NewSource(1))
+	once, _ := e.Sample(200, 1, rng)
+	grind, err := e.Sample(200, 1000, rng)
+	if err != nil || grind.Forgeable <= once.Forgeable {
+		t.Fatalf("Sample() should forge more messages with more tries: %d vs %d, %v",
+			grind.Forgeable, once.Forgeable, err)
+	}
+
+	// Forgeries stop at the first forgeable try
+	forgery, _ := e.TestMessage(leaks[0].Msg, 100000, rng)
+	if forgery.Forgeable && (forgery.Tries > 100000 || len(forgery.Blocked) != 0) {
+		t.Fatalf("TestMessage() returned inconsistent forgery: %+v", forgery)
+	}
+
+	// Signatures of other params are rejected
+	other := wots.NewKeyFromSeed(wots.DecodeParams(testEncoding), bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32))
+	leaks = append(leaks, leakSignatures(t, other, 1)...)
+	if _, err := Analyze(key.ComputePK(), leaks); err == nil {
+		t.Fatalf("Analyze() should return error for signatures of different keys")
+	}
+}
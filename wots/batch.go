@@ -0,0 +1,139 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+///////////////////////////////////////////////////////////////////////
+// BATCH VERIFICATION
+/*
+	Verify many signatures concurrently. Each item is verified with the
+	same semantics as Verify, and gets its own result
+
+	The random elements used to mask the ladders only depend on the
+	params and public seed, so they are computed once per batch for
+	each distinct (params, public seed) pair
+*/
+
+// A signature to be verified
+type Item struct {
+	Msg       []byte
+	Signature []byte
+	PublicKey []byte
+}
+
+// The result of verifying an item
+type Result struct {
+	Valid bool
+	Err   error
+}
+
+// Verify a batch of signatures, using one worker per CPU
+// Returns one result for each item, in the same order
+func VerifyBatch(items []Item) []Result {
+	return VerifyBatchWorkers(items, runtime.NumCPU())
+}
+
+// Verify a batch of signatures, using at most the given number of workers
+// Returns one result for each item, in the same order
+func VerifyBatchWorkers(items []Item, workers int) []Result {
+	results := make([]Result, len(items))
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	cache := &randsCache{entries: make(map[string]*randsEntry)}
+	next := int64(-1)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(items) {
+					return
+				}
+				valid, err := cache.verify(items[i])
+				results[i] = Result{Valid: valid, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+///////////////////////////////////////////////////////////////////////
+// RANDOM ELEMENTS CACHE
+
+type randsEntry struct {
+	once  sync.Once
+	rands [][]byte
+}
+
+type randsCache struct {
+	mux     sync.Mutex
+	entries map[string]*randsEntry
+}
+
+// Get the random elements for the params and public seed, computing them only once
+func (c *randsCache) get(params *Params, enc ParamsEncoding, pSeed []byte) [][]byte {
+	// RFC 8391 doesn't use random elements
+	if params.construction == ConstructionRFC8391 {
+		return nil
+	}
+
+	key := string(append([]byte{byte(enc)}, pSeed...))
+	c.mux.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &randsEntry{}
+		c.entries[key] = e
+	}
+	c.mux.Unlock()
+
+	e.once.Do(func() {
+		e.rands = params.newRands(pSeed)
+	})
+	return e.rands
+}
+
+// Verify an item, with the same semantics as Verify
+func (c *randsCache) verify(item Item) (bool, error) {
+	// 1. Decode params
+	params, err := decodeParams(item.Msg, item.Signature, true)
+	if err != nil {
+		return false, err
+	}
+	enc := ParamsEncoding(item.Signature[0])
+	signature := item.Signature[1:]
+
+	// 2. Ensure pubkey and signature have correct size
+	if len(item.PublicKey) != PKSize {
+		return false, errWrongPubKeySize
+	}
+	if len(signature) != params.sigSize() {
+		return false, errWrongSigLen
+	}
+
+	// 3. Decode signature using cached random elements
+	rands := c.get(params, enc, signature[0:SeedSize])
+	s := getScratch()
+	defer putScratch(s)
+	pk, err := params.decode(s.pk[:0], item.Msg, signature, rands)
+
+	// 4. Compare public key
+	return bytes.Equal(pk, item.PublicKey), err
+}
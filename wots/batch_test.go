@@ -0,0 +1,81 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+// Build a batch with valid and invalid items for multiple params
+// Keys with the same public seed are used to exercise the cache
+func buildTestBatch(t *testing.T) []Item {
+	pSeed := getRandData(t, SeedSize)
+	items := make([]Item, 0)
+	for _, enc := range []ParamsEncoding{Level0, Level1, Consensus, WOTSP_SHA2_256} {
+		for i := 0; i < 3; i++ {
+			key := NewKeyFromSeed(DecodeParams(enc), getRandData(t, SeedSize), pSeed)
+			msg := getRandData(t, 64)
+			sig := key.Sign(msg)
+			pk := key.ComputePK()
+
+			// Valid
+			items = append(items, Item{Msg: msg, Signature: sig, PublicKey: pk})
+
+			// Wrong message
+			items = append(items, Item{Msg: getRandData(t, 64), Signature: sig, PublicKey: pk})
+
+			// Wrong signature size
+			items = append(items, Item{Msg: msg, Signature: sig[:len(sig)-1], PublicKey: pk})
+
+			// Wrong public key size
+			items = append(items, Item{Msg: msg, Signature: sig, PublicKey: pk[1:]})
+		}
+	}
+
+	// Empty message and unknown params
+	key := NewKey(level0Params, rand.Reader)
+	sig := key.Sign([]byte{1})
+	items = append(items, Item{Msg: nil, Signature: sig, PublicKey: key.ComputePK()})
+	sig[0] = byte(ParamsEncodingLen)
+	items = append(items, Item{Msg: []byte{1}, Signature: sig, PublicKey: key.ComputePK()})
+	return items
+}
+
+func TestVerifyBatch(t *testing.T) {
+	items := buildTestBatch(t)
+
+	for _, workers := range []int{0, 1, 3, len(items) + 1} {
+		results := VerifyBatchWorkers(items, workers)
+
+		if len(results) != len(items) {
+			t.Fatalf("VerifyBatchWorkers() returned %d results for %d items", len(results), len(items))
+		}
+
+		// Results must match Verify for each item
+		for i, item := range items {
+			valid, err := Verify(item.Msg, item.Signature, item.PublicKey)
+
+			if results[i].Valid != valid || results[i].Err != err {
+				t.Fatalf("VerifyBatchWorkers() with %d workers returned (%v, %v) for item %d, Verify returned (%v, %v)",
+					workers, results[i].Valid, results[i].Err, i, valid, err)
+			}
+		}
+	}
+
+	// Test default workers
+	results := VerifyBatch(items)
+
+	if !results[0].Valid || results[1].Valid {
+		t.Fatalf("VerifyBatch() returned wrong results")
+	}
+
+	// Test empty batch
+	if len(VerifyBatch(nil)) != 0 {
+		t.Fatalf("VerifyBatch() should return no results for empty batch")
+	}
+}
@@ -0,0 +1,99 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/internal/secmem"
+)
+
+///////////////////////////////////////////////////////////////////////
+// LADDER CHECKPOINTS
+/*
+	A generated key stores all W levels of every ladder, which takes
+	W*total*n bytes, so signing just copies ladder points
+
+	With a checkpoint interval k, only the levels 0, k, 2k, ... are
+	stored, taking about W/k of the memory. Signing then starts from
+	the closest stored level below each ladder position, computing at
+	most k-1 hashes per ladder
+
+	An interval of 1 stores all levels, and an interval of W only stores
+	the secret keys
+*/
+
+// Set the checkpoint interval used when generating the key
+// If the key was already generated, the stored ladders are dropped,
+// and Generate must be called again
+func (k *Key) SetCheckpointInterval(interval int) error {
+	if interval < 1 || interval > k.params.w {
+		return errors.New(fmt.Sprintf("checkpoint interval must be between 1 and %d, got %d",
+			k.params.w, interval))
+	}
+	if k.generated && interval != k.CheckpointInterval() {
+		if k.locked {
+			for _, c := range k.chains {
+				_ = secmem.Unlock(c)
+			}
+		}
+		secmem.WipeAll(k.chains)
+		k.chains = nil
+		k.rands = nil
+		k.generated = false
+	}
+	k.interval = interval
+	return nil
+}
+
+// Get the checkpoint interval used when generating the key
+func (k *Key) CheckpointInterval() int {
+	if k.interval < 1 {
+		return 1
+	}
+	return k.interval
+}
+
+// Set the checkpoint interval to the smallest one whose memory fits in the budget
+// See Params.IntervalForBudget
+func (k *Key) SetMemoryBudget(budget int) error {
+	interval, err := k.params.IntervalForBudget(budget)
+	if err != nil {
+		return err
+	}
+	return k.SetCheckpointInterval(interval)
+}
+
+// Get the memory in bytes used by a generated key with the given checkpoint interval
+// Includes the stored ladder levels and the random elements needed to sign
+func (p *Params) CheckpointMemory(interval int) int {
+	if interval < 1 {
+		interval = 1
+	}
+	memory := p.checkpointLevels(interval) * p.total * p.n
+	if interval > 1 && p.construction != ConstructionRFC8391 {
+		memory += (p.w - 1) * p.n
+	}
+	return memory
+}
+
+// Get the smallest checkpoint interval whose memory fits in the budget, in bytes
+// Returns an error if the budget is smaller than the secret keys
+func (p *Params) IntervalForBudget(budget int) (int, error) {
+	for interval := 1; interval <= p.w; interval++ {
+		if p.CheckpointMemory(interval) <= budget {
+			return interval, nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("memory budget of %d bytes is too small: at least %d bytes are needed",
+		budget, p.CheckpointMemory(p.w)))
+}
+
+// Get the number of stored ladder levels with the given checkpoint interval
+func (p *Params) checkpointLevels(interval int) int {
+	return (p.w-1)/interval + 1
+}
@@ -0,0 +1,173 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"testing"
+)
+
+func TestKey_CheckpointInterval(t *testing.T) {
+	paramsList := []*Params{
+		level0Params,
+		wotspSHA2_256Params,
+		NewParamsW(32, 24, 4, hasher.BLAKE3_256, hasher.SHA3_224),
+	}
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+	msg := getRandData(t, 256)
+
+	for _, params := range paramsList {
+		// Signature computed from scratch
+		expected := NewKeyFromSeed(params, seed, pSeed).Sign(msg)
+		pk := NewKeyFromSeed(params, seed, pSeed).ComputePK()
+
+		for _, interval := range []int{1, 2, 3, 5, 15, params.w - 1, params.w} {
+			if interval > params.w {
+				continue
+			}
+			key := NewKeyFromSeed(params, seed, pSeed)
+
+			if err := key.SetCheckpointInterval(interval); err != nil {
+				t.Fatalf("Key.SetCheckpointInterval() returned error for interval %d: %s", interval, err)
+			}
+
+			key.Generate()
+
+			if len(key.chains) != params.checkpointLevels(interval) {
+				t.Fatalf("Key.Generate() stored %d levels with interval %d, expected %d",
+					len(key.chains), interval, params.checkpointLevels(interval))
+			}
+
+			if !bytes.Equal(key.GetPK(), pk) {
+				t.Fatalf("Key.Generate() with interval %d computed wrong PK for params %s", interval, params)
+			}
+
+			if !bytes.Equal(key.Sign(msg), expected) {
+				t.Fatalf("Key.Sign() with interval %d returned wrong signature for params %s", interval, params)
+			}
+		}
+	}
+}
+
+func TestKey_SetCheckpointInterval(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+
+	if key.CheckpointInterval() != 1 {
+		t.Fatalf("Key should use checkpoint interval 1 by default. Got %d", key.CheckpointInterval())
+	}
+
+	// Test invalid intervals
+	if key.SetCheckpointInterval(0) == nil {
+		t.Fatalf("Key.SetCheckpointInterval() should return error when interval is 0")
+	}
+
+	if key.SetCheckpointInterval(W+1) == nil {
+		t.Fatalf("Key.SetCheckpointInterval() should return error when interval is larger than W")
+	}
+
+	// Test changing interval of generated key
+	key.Generate()
+	_ = key.SetCheckpointInterval(1)
+
+	if !key.generated {
+		t.Fatalf("Key.SetCheckpointInterval() shouldn't drop the ladders if interval is the same")
+	}
+
+	_ = key.SetCheckpointInterval(16)
+
+	if key.generated || key.chains != nil {
+		t.Fatalf("Key.SetCheckpointInterval() should drop the ladders if interval changes")
+	}
+
+	key.Generate()
+
+	if len(key.chains) != 16 {
+		t.Fatalf("Key.Generate() stored %d levels with interval 16, expected 16", len(key.chains))
+	}
+}
+
+func TestParams_MemoryBudget(t *testing.T) {
+	params := level0Params
+	ladderSize := params.total * params.n
+
+	// Test memory for each interval
+	if params.CheckpointMemory(1) != W*ladderSize {
+		t.Fatalf("Params.CheckpointMemory() returned wrong memory for interval 1: %d", params.CheckpointMemory(1))
+	}
+
+	// Levels 0, 16, ..., 240 plus random elements
+	if params.CheckpointMemory(16) != 16*ladderSize+(W-1)*params.n {
+		t.Fatalf("Params.CheckpointMemory() returned wrong memory for interval 16: %d", params.CheckpointMemory(16))
+	}
+
+	// Test budget
+	interval, err := params.IntervalForBudget(W * ladderSize)
+
+	if err != nil || interval != 1 {
+		t.Fatalf("Params.IntervalForBudget() should return interval 1 for full memory. Got %d, %v", interval, err)
+	}
+
+	budget := params.CheckpointMemory(16)
+	interval, err = params.IntervalForBudget(budget)
+
+	if err != nil || params.CheckpointMemory(interval) > budget || params.CheckpointMemory(interval-1) <= budget {
+		t.Fatalf("Params.IntervalForBudget() didn't return the smallest interval for budget. Got %d, %v",
+			interval, err)
+	}
+
+	if _, err = params.IntervalForBudget(ladderSize - 1); err == nil {
+		t.Fatalf("Params.IntervalForBudget() should return error when budget is too small")
+	}
+
+	// Test key budget
+	key := NewKey(params, rand.Reader)
+
+	if key.SetMemoryBudget(ladderSize-1) == nil {
+		t.Fatalf("Key.SetMemoryBudget() should return error when budget is too small")
+	}
+
+	if err = key.SetMemoryBudget(budget); err != nil || key.CheckpointInterval() != interval {
+		t.Fatalf("Key.SetMemoryBudget() didn't set the interval. Got %d, expected %d",
+			key.CheckpointInterval(), interval)
+	}
+}
+
+func TestKey_MarshalBinary_Checkpoints(t *testing.T) {
+	msg := getRandData(t, 256)
+
+	// Test key with checkpoints
+	key := NewKey(level0Params, rand.Reader)
+	_ = key.SetCheckpointInterval(10)
+	key.Generate()
+	data, _ := key.MarshalBinary()
+
+	loaded := new(Key)
+
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Key.UnmarshalBinary() returned error: %s", err)
+	}
+
+	if loaded.CheckpointInterval() != 10 || !loaded.generated {
+		t.Fatalf("Key.UnmarshalBinary() didn't load the checkpoint interval")
+	}
+
+	if !bytes.Equal(loaded.Sign(msg), key.Sign(msg)) {
+		t.Fatalf("Key.UnmarshalBinary() loaded a different key")
+	}
+
+	// Test invalid interval
+	wrong := append([]byte{}, data...)
+	wrong[keyHeaderSize+1+2*SeedSize] = 0xFF
+
+	if loaded.UnmarshalBinary(wrong) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when interval is invalid")
+	}
+
+}
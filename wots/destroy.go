@@ -0,0 +1,84 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"github.com/xx-labs/sleeve/internal/secmem"
+)
+
+///////////////////////////////////////////////////////////////////////
+// KEY MATERIAL LIFETIME
+/*
+	The secret seed and the generated ladders of a key are secret, and
+	stay in memory for the whole life of the key. Destroy wipes them,
+	after which the key can't be used anymore
+
+	LockMemory prevents the secret memory of the key from being swapped
+	to disk (Linux only). Locked memory is unlocked by Destroy
+*/
+
+// Wipe all the secret material of the key
+// After this, Sign and ComputePK return nil, and Generate does nothing
+func (k *Key) Destroy() {
+	if k.destroyed {
+		return
+	}
+
+	// 1. Wipe secrets
+	secmem.Wipe(k.seed)
+	secmem.WipeAll(k.chains)
+	k.skHash.destroy()
+
+	// 2. Unlock memory
+	if k.locked {
+		_ = secmem.Unlock(k.seed)
+		for _, c := range k.chains {
+			_ = secmem.Unlock(c)
+		}
+	}
+
+	// 3. Drop references
+	k.seed = nil
+	k.chains = nil
+	k.rands = nil
+	k.pk = nil
+	k.generated = false
+	k.locked = false
+	k.destroyed = true
+}
+
+// Lock the memory holding the secret seed and generated ladders
+// Ladders generated after locking are also locked, on a best effort basis
+// Returns secmem.ErrLockNotSupported on platforms other than Linux, or
+// an error if the locked memory limit of the process is exceeded
+func (k *Key) LockMemory() error {
+	if k.destroyed || k.locked {
+		return nil
+	}
+	if err := secmem.Lock(k.seed); err != nil {
+		return err
+	}
+	for i, c := range k.chains {
+		if err := secmem.Lock(c); err != nil {
+			// Undo previous locks
+			_ = secmem.Unlock(k.seed)
+			for _, l := range k.chains[:i] {
+				_ = secmem.Unlock(l)
+			}
+			return err
+		}
+	}
+	k.locked = true
+	return nil
+}
+
+// Lock the generated ladders, ignoring errors
+func (k *Key) lockChains() {
+	for _, c := range k.chains {
+		_ = secmem.Lock(c)
+	}
+}
@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestKey_Destroy(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	key.Generate()
+
+	// Keep references to the secret memory
+	seed := key.seed
+	chains := key.chains
+
+	key.Destroy()
+
+	if !bytes.Equal(seed, make([]byte, SeedSize)) {
+		t.Fatalf("Key.Destroy() didn't wipe the secret seed")
+	}
+	for _, c := range chains {
+		if !bytes.Equal(c, make([]byte, len(c))) {
+			t.Fatalf("Key.Destroy() didn't wipe the ladders")
+		}
+	}
+
+	if key.Sign([]byte("msg")) != nil {
+		t.Fatalf("Key.Sign() should return nil when key was destroyed")
+	}
+	if key.ComputePK() != nil {
+		t.Fatalf("Key.ComputePK() should return nil when key was destroyed")
+	}
+	key.Generate()
+	if key.chains != nil {
+		t.Fatalf("Key.Generate() shouldn't generate ladders when key was destroyed")
+	}
+	if _, err := key.MarshalBinary(); err == nil {
+		t.Fatalf("Key.MarshalBinary() should return error when key was destroyed")
+	}
+
+	// Destroy twice should be fine
+	key.Destroy()
+}
+
+func TestKey_LockMemory(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	if err := key.LockMemory(); err != nil {
+		t.Skipf("Key.LockMemory() is not available: %s", err)
+	}
+
+	// Signing still works with locked memory
+	msg := []byte("msg")
+	pk := key.ComputePK()
+	sig := key.Sign(msg)
+	if ok, err := Verify(msg, sig, pk); !ok || err != nil {
+		t.Fatalf("Key.Sign() with locked memory returned invalid signature")
+	}
+
+	key.Destroy()
+	if key.locked {
+		t.Fatalf("Key.Destroy() should unlock the memory")
+	}
+}
@@ -0,0 +1,177 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xx-labs/sleeve/hasher"
+	"hash"
+)
+
+///////////////////////////////////////////////////////////////////////
+// PREHASHED MESSAGES
+/*
+	Signing and verifying start by hashing the message with the MSG
+	hash of the params, keeping the first m bytes:
+	  digest = MSG(msg)[:m]
+	Only the digest is used to compute the ladder positions, so the
+	message can be hashed separately, e.g., while it is received or
+	read from disk, and signed or verified with SignDigest and
+	VerifyDigest. Signatures are identical to the ones of Sign
+
+	Signer and Verifier hash the message as it is written to them,
+	so large payloads don't need to be kept in memory
+
+	Params with randomized message hashing don't support digests, since
+	the digest depends on a randomizer derived from the whole message,
+	see randomized.go
+*/
+
+var errWrongDigestSize = errors.New("message digest has incorrect length")
+
+// Get the size of the message digest, m bytes
+func (p *Params) DigestSize() int {
+	return p.m
+}
+
+// Compute the m byte digest of the message
+// For params with randomized message hashing, the digest can't be signed or verified
+func (p *Params) HashMessage(msg []byte) []byte {
+	h := p.msgHash.Get()
+	defer p.msgHash.Put(h)
+	h.Write(msg)
+	return hasher.SumN(h, nil, p.m)
+}
+
+///////////////////////////////////////////////////////////////////////
+// SIGN DIGEST
+// Signs an m byte message digest using the WOTS+ key
+// Returns the same signature as Sign for the message of the digest
+// Returns nil if the digest has incorrect length, the key was destroyed,
+// or the params use randomized message hashing
+func (k *Key) SignDigest(digest []byte) []byte {
+	if k.destroyed || k.params.randomized || len(digest) != k.params.m {
+		return nil
+	}
+	out := make([]byte, 0, 1+k.params.sigSize())
+
+	// Signature header, see Signature for the serialized layout
+	out = append(out, byte(EncodeParams(k.params)))
+	out = append(out, k.pSeed...)
+	return k.signDigest(out, digest)
+}
+
+///////////////////////////////////////////////////////////////////////
+// Decode a signature of an m byte message digest, i.e., compute the public key
+// The output slice must have length 0 and capacity of 32 bytes, see Decode
+func (p *Params) DecodeDigest(out, digest, signature []byte) ([]byte, error) {
+	if p.randomized {
+		return nil, errRandomizedDigest
+	}
+	if err := p.checkDecode(out, signature); err != nil {
+		return nil, err
+	}
+	if len(digest) != p.m {
+		return nil, errWrongDigestSize
+	}
+	return p.decodeDigestTo(out, digest, signature)
+}
+
+// Verify a signature of an m byte message digest
+// The signature doesn't include the params encoding, see Verify
+func (p *Params) VerifyDigest(digest, signature, pubkey []byte) (bool, error) {
+	// Ensure pubkey has correct size
+	if len(pubkey) != PKSize {
+		return false, errWrongPubKeySize
+	}
+	// Decode signature, using scratch memory for the public key
+	s := getScratch()
+	defer putScratch(s)
+	pk, err := p.DecodeDigest(s.pk[:0], digest, signature)
+	// Compare public key
+	return bytes.Equal(pk, pubkey), err
+}
+
+// Verify a signature of an m byte message digest, using the params encoded in the signature
+func VerifyDigest(digest, signature, pubkey []byte) (bool, error) {
+	// 1. Decode params
+	params, err := decodeParams(digest, signature, true)
+	if err != nil {
+		return false, err
+	}
+	// 2. Verify signature
+	return params.VerifyDigest(digest, signature[1:], pubkey)
+}
+
+///////////////////////////////////////////////////////////////////////
+// STREAMING
+
+// Signs a message written to it, without keeping it in memory
+type Signer struct {
+	key *Key
+	h   hash.Hash
+}
+
+// Creates a streaming signer for the key
+// Returns nil if the params use randomized message hashing
+func (k *Key) NewSigner() *Signer {
+	if k.params.randomized {
+		return nil
+	}
+	return &Signer{
+		key: k,
+		h:   k.params.msgHash.New(),
+	}
+}
+
+// Write part of the message, implementing io.Writer
+// Never returns an error
+func (s *Signer) Write(p []byte) (int, error) {
+	return s.h.Write(p)
+}
+
+// Sign the message written so far
+// Returns the same signature as Key.Sign for the whole message
+func (s *Signer) Sign() []byte {
+	return s.key.SignDigest(s.digest())
+}
+
+// Get the m byte digest of the message written so far
+func (s *Signer) digest() []byte {
+	return hasher.SumN(s.h, nil, s.key.params.m)
+}
+
+// Verifies a signature of a message written to it, without keeping it in memory
+type Verifier struct {
+	params *Params
+	h      hash.Hash
+}
+
+// Creates a streaming verifier for the params
+// Returns nil if the params use randomized message hashing
+func (p *Params) NewVerifier() *Verifier {
+	if p.randomized {
+		return nil
+	}
+	return &Verifier{
+		params: p,
+		h:      p.msgHash.New(),
+	}
+}
+
+// Write part of the message, implementing io.Writer
+// Never returns an error
+func (v *Verifier) Write(p []byte) (int, error) {
+	return v.h.Write(p)
+}
+
+// Verify a signature of the message written so far
+// The signature doesn't include the params encoding, like Params.Verify
+func (v *Verifier) Verify(signature, pubkey []byte) (bool, error) {
+	return v.params.VerifyDigest(hasher.SumN(v.h, nil, v.params.m), signature, pubkey)
+}
@@ -0,0 +1,146 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestKey_SignDigest(t *testing.T) {
+	msg := getRandData(t, 1000)
+	for _, params := range allocParamsList() {
+		// Digests are not supported with randomized message hashing, see TestParams_RandomizedDigest
+		if params.RandomizedHashing() {
+			continue
+		}
+		key := NewKey(params, rand.Reader)
+		sig := key.Sign(msg)
+		digest := params.HashMessage(msg)
+
+		if len(digest) != params.DigestSize() {
+			t.Fatalf("HashMessage() should return %d bytes for %s, got %d", params.DigestSize(), params, len(digest))
+		}
+
+		if !bytes.Equal(key.SignDigest(digest), sig) {
+			t.Fatalf("SignDigest() should return the same signature as Sign() for %s", params)
+		}
+
+		// Generated keys use fast signing
+		key.Generate()
+		if !bytes.Equal(key.SignDigest(digest), sig) {
+			t.Fatalf("SignDigest() should return the same signature as Sign() for generated key with %s", params)
+		}
+
+		if key.SignDigest(digest[1:]) != nil {
+			t.Fatalf("SignDigest() should return nil for digest with incorrect length")
+		}
+	}
+
+	key := NewKey(DecodeParams(DefaultParams), rand.Reader)
+	key.Destroy()
+	if key.SignDigest(make([]byte, key.params.DigestSize())) != nil {
+		t.Fatalf("SignDigest() should return nil if the key was destroyed")
+	}
+}
+
+func TestParams_VerifyDigest(t *testing.T) {
+	msg := getRandData(t, 1000)
+	for _, params := range allocParamsList() {
+		// Digests are not supported with randomized message hashing, see TestParams_RandomizedDigest
+		if params.RandomizedHashing() {
+			continue
+		}
+		key := NewKey(params, rand.Reader)
+		pk := key.ComputePK()
+		sig := key.Sign(msg)
+		digest := params.HashMessage(msg)
+
+		ok, err := params.VerifyDigest(digest, sig[1:], pk)
+		if err != nil {
+			t.Fatalf("VerifyDigest() returned error for valid signature with %s: %s", params, err)
+		}
+		if !ok {
+			t.Fatalf("VerifyDigest() should return true for valid signature with %s", params)
+		}
+
+		out := make([]byte, 0, PKSize)
+		out, err = params.DecodeDigest(out, digest, sig[1:])
+		if err != nil || !bytes.Equal(out, pk) {
+			t.Fatalf("DecodeDigest() should return the public key for %s", params)
+		}
+
+		digest[0] ^= 0x01
+		if ok, _ := params.VerifyDigest(digest, sig[1:], pk); ok {
+			t.Fatalf("VerifyDigest() should return false for wrong digest with %s", params)
+		}
+
+		if _, err := params.VerifyDigest(digest[1:], sig[1:], pk); err != errWrongDigestSize {
+			t.Fatalf("VerifyDigest() should return error for digest with incorrect length")
+		}
+
+		if _, err := params.VerifyDigest(digest, sig[2:], pk); err != errWrongSigLen {
+			t.Fatalf("VerifyDigest() should return error for signature with incorrect length")
+		}
+
+		if _, err := params.VerifyDigest(digest, sig[1:], pk[1:]); err != errWrongPubKeySize {
+			t.Fatalf("VerifyDigest() should return error for public key with incorrect length")
+		}
+	}
+
+	// Verify with params encoded in the signature
+	key := NewKey(DecodeParams(DefaultParams), rand.Reader)
+	sig := key.Sign(msg)
+	ok, err := VerifyDigest(key.params.HashMessage(msg), sig, key.ComputePK())
+	if err != nil || !ok {
+		t.Fatalf("VerifyDigest() should return true for valid signature")
+	}
+}
+
+func TestSigner(t *testing.T) {
+	msg := getRandData(t, 10000)
+	for _, params := range allocParamsList() {
+		// Digests are not supported with randomized message hashing, see TestParams_RandomizedDigest
+		if params.RandomizedHashing() {
+			continue
+		}
+		key := NewKey(params, rand.Reader)
+		pk := key.ComputePK()
+		sig := key.Sign(msg)
+
+		// Write message in chunks
+		signer := key.NewSigner()
+		verifier := params.NewVerifier()
+		for i := 0; i < len(msg); i += 777 {
+			end := i + 777
+			if end > len(msg) {
+				end = len(msg)
+			}
+			_, _ = signer.Write(msg[i:end])
+			_, _ = verifier.Write(msg[i:end])
+		}
+
+		if !bytes.Equal(signer.Sign(), sig) {
+			t.Fatalf("Signer.Sign() should return the same signature as Sign() for %s", params)
+		}
+
+		ok, err := verifier.Verify(sig[1:], pk)
+		if err != nil {
+			t.Fatalf("Verifier.Verify() returned error for valid signature with %s: %s", params, err)
+		}
+		if !ok {
+			t.Fatalf("Verifier.Verify() should return true for valid signature with %s", params)
+		}
+
+		// More data changes the message
+		_, _ = verifier.Write([]byte{0})
+		if ok, _ := verifier.Verify(sig[1:], pk); ok {
+			t.Fatalf("Verifier.Verify() should return false for different message with %s", params)
+		}
+	}
+}
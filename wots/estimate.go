@@ -0,0 +1,165 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/hasher"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////
+// SECURITY ESTIMATE
+/*
+	The security of the ladders is estimated as in the WOTS+ paper
+	(Hulsing, "W-OTS+ - Shorter Signatures for Hash-Based Signature
+	Schemes"), using the tight bound for the PRF hash of n bytes:
+	  Classical:    8n - log2(w^2 * total)
+	  Post quantum: 8n / 2 (Grover search on the ladders)
+	Both are capped by the security of the public key hash, and by the
+	preimage security of the PRF hash: 8 bits per output byte for fixed
+	size hash functions, and half the capacity for XOFs, i.e., 128 bits
+	for SHAKE128 and 256 bits for SHAKE256
+
+	These are the numbers published in security.go. They assume the
+	signed message is always structured, so chosen message attacks
+	don't apply to the message hash. The security of the message hash,
+	truncated to m bytes, is reported separately:
+	  Classical:    8m (second preimage)
+	  Post quantum: 8m / 2
+	also capped by the preimage security of the MSG hash
+	For params with randomized message hashing, these hold for any
+	message, see randomized.go. For target sum params, the bits lost
+	by requiring the target sum are subtracted, see targetsum.go
+
+	Hash call counts include every call to a hash function, including
+	the message hash, random elements, tweak and public key hashes
+	For signing and verifying, the expected count for a random message
+	is given, since ladders are walked a number of steps that depends
+	on the message. Signing assumes the key was not generated
+*/
+type SecurityEstimate struct {
+	// Security of the ladders, in bits
+	Classical   float64
+	PostQuantum float64
+	// Security of the message hash, in bits
+	MsgClassical   float64
+	MsgPostQuantum float64
+	// Sizes in bytes
+	SignatureSize int
+	PublicKeySize int
+	// Number of hash calls
+	KeyGenHashes int
+	SignHashes   float64
+	VerifyHashes float64
+}
+
+// Estimate the security and cost of the params
+func (p *Params) SecurityEstimate() SecurityEstimate {
+	n := float64(p.n)
+	w := float64(p.w)
+	total := float64(p.total)
+	// Steps to walk all ladders to the end
+	steps := total * (w - 1)
+	// Bits of the ladders and message digest
+	ladderBits := math.Min(8*n, hashSecurity(p.prfHash))
+	msgBits := math.Min(8*float64(p.m), hashSecurity(p.msgHash)) - p.targetSumBits()
+
+	est := SecurityEstimate{
+		Classical:      math.Min(math.Min(8*n-math.Log2(w*w*total), ladderBits), 8*PKSize),
+		PostQuantum:    math.Min(ladderBits/2, 8*PKSize/2),
+		MsgClassical:   msgBits,
+		MsgPostQuantum: msgBits / 2,
+		SignatureSize:  1 + p.sigSize(),
+		PublicKeySize:  PKSize,
+	}
+
+	switch p.construction {
+	case ConstructionRFC8391:
+		// Each ladder step is 2 PRF calls for key and mask, plus F
+		// The L-tree has total-1 nodes, each with 3 PRF calls, plus H
+		lTree := 4 * (total - 1)
+		est.KeyGenHashes = p.total + 3*int(steps) + int(lTree)
+		est.SignHashes = total + 1 + 3*steps/2
+		est.VerifyHashes = 1 + 3*steps/2 + lTree
+	default:
+		// Random elements, W-1 hashes
+		rands := w - 1
+		est.KeyGenHashes = p.total + int(rands) + int(steps) + 2
+		est.SignHashes = total + 1 + rands + steps/2
+		est.VerifyHashes = 1 + rands + steps/2 + 2
+	}
+
+	// The randomizer PRF
+	if p.randomized {
+		est.SignHashes++
+	}
+
+	// Grinding the counter takes 1 / P(sum = S) tries, and verifying 1
+	if p.targetSum != 0 {
+		est.SignHashes += math.Exp2(p.targetSumBits())
+		est.VerifyHashes++
+	}
+	return est
+}
+
+// Get the preimage security of the hash function, in bits
+func hashSecurity(h hasher.Hasher) float64 {
+	switch h {
+	case hasher.SHAKE128:
+		return 128
+	case hasher.SHAKE256:
+		return 256
+	default:
+		return 8 * float64(h.Size())
+	}
+}
+
+///////////////////////////////////////////////////////////////////////
+// STRICT CONSTRUCTOR
+
+// Creates WOTS+ params with given values of n, m, w; prf and msg hashes,
+// like NewParamsW, but returns an error explaining why the params are
+// invalid, or if the estimated security is below the minimum levels
+// Both the ladders and the message hash must reach the minimum levels
+func NewParamsStrict(n, m, w int, prf, msg hasher.Hasher, min SecurityLevels) (*Params, error) {
+	// 1. Create params
+	params := NewParamsW(n, m, w, prf, msg)
+	if params == nil {
+		switch {
+		case m < 1 || m > MaxMsgSize:
+			return nil, errors.New(fmt.Sprintf("message size must be between 1 and %d bytes, got %d", MaxMsgSize, m))
+		case !canOutput(prf, n):
+			return nil, errors.New(fmt.Sprintf("PRF hash %s is smaller than n = %d bytes", prf, n))
+		case !canOutput(msg, m):
+			return nil, errors.New(fmt.Sprintf("MSG hash %s is smaller than m = %d bytes", msg, m))
+		default:
+			return nil, errors.New(fmt.Sprintf("W must be 4, 16 or 256, got %d", w))
+		}
+	}
+
+	// 2. Check security levels
+	est := params.SecurityEstimate()
+	if est.Classical < min.Classical {
+		return nil, errors.New(fmt.Sprintf("classical security of %.2f bits is below the minimum of %.2f bits",
+			est.Classical, min.Classical))
+	}
+	if est.PostQuantum < min.PostQuantum {
+		return nil, errors.New(fmt.Sprintf("post quantum security of %.2f bits is below the minimum of %.2f bits",
+			est.PostQuantum, min.PostQuantum))
+	}
+	if est.MsgClassical < min.Classical {
+		return nil, errors.New(fmt.Sprintf("classical message security of %.2f bits is below the minimum of %.2f bits",
+			est.MsgClassical, min.Classical))
+	}
+	if est.MsgPostQuantum < min.PostQuantum {
+		return nil, errors.New(fmt.Sprintf("post quantum message security of %.2f bits is below the minimum of %.2f bits",
+			est.MsgPostQuantum, min.PostQuantum))
+	}
+	return params, nil
+}
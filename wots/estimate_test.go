@@ -0,0 +1,179 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"github.com/xx-labs/sleeve/hasher"
+	"math"
+	"testing"
+)
+
+func TestParams_SecurityEstimate_Published(t *testing.T) {
+	// Published signature sizes in bits, from security.go
+	sizes := map[ParamsEncoding]int{
+		Level0:         4424,
+		Level1:         5256,
+		Level2:         6088,
+		Level3:         6920,
+		Consensus:      8968,
+		WOTSP_SHA2_256: 17416,
+		// Randomizer of n bytes
+		Level0Randomized: 4424 + 160,
+		Level1Randomized: 5256 + 192,
+		Level2Randomized: 6088 + 224,
+		Level3Randomized: 6920 + 256,
+		// No checksum ladders, 4 byte counter
+		Level0TargetSum: 4136,
+		Level1TargetSum: 4904,
+		Level2TargetSum: 5672,
+		Level3TargetSum: 6440,
+		// Same as Level0 to Level3
+		Level0Keyed: 4424,
+		Level1Keyed: 5256,
+		Level2Keyed: 6088,
+		Level3Keyed: 6920,
+	}
+
+	// Estimates must match the published security levels
+	for _, info := range RegisteredParams() {
+		est := info.Params.SecurityEstimate()
+
+		if math.Abs(est.Classical-info.Security.Classical) > 0.005 {
+			t.Fatalf("SecurityEstimate() returned wrong classical security for %s. Got %.2f, expected %.2f",
+				info.Name, est.Classical, info.Security.Classical)
+		}
+
+		if est.PostQuantum != info.Security.PostQuantum {
+			t.Fatalf("SecurityEstimate() returned wrong post quantum security for %s. Got %.2f, expected %.2f",
+				info.Name, est.PostQuantum, info.Security.PostQuantum)
+		}
+
+		if est.SignatureSize*8 != sizes[info.Encoding] {
+			t.Fatalf("SecurityEstimate() returned wrong signature size for %s. Got %d bits, expected %d",
+				info.Name, est.SignatureSize*8, sizes[info.Encoding])
+		}
+
+		if est.PublicKeySize != PKSize {
+			t.Fatalf("SecurityEstimate() returned wrong public key size for %s", info.Name)
+		}
+	}
+
+	// Message hash security of Level2 (see note in security.go)
+	est := level2Params.SecurityEstimate()
+
+	if est.MsgClassical != 192 || est.MsgPostQuantum != 96 {
+		t.Fatalf("SecurityEstimate() returned wrong message security for Level2. Got %.2f, %.2f",
+			est.MsgClassical, est.MsgPostQuantum)
+	}
+}
+
+func TestParams_SecurityEstimate_Hashes(t *testing.T) {
+	// Level0: total = 26, W = 256
+	est := level0Params.SecurityEstimate()
+
+	// SK (26) + rands (255) + ladders (26*255) + tweak and PK (2)
+	if est.KeyGenHashes != 26+255+26*255+2 {
+		t.Fatalf("SecurityEstimate() returned wrong keygen hash count: %d", est.KeyGenHashes)
+	}
+
+	// SK (26) + msg (1) + rands (255) + half of ladders
+	if est.SignHashes != 26+1+255+26*255/2.0 {
+		t.Fatalf("SecurityEstimate() returned wrong sign hash count: %.1f", est.SignHashes)
+	}
+
+	// msg (1) + rands (255) + half of ladders + tweak and PK (2)
+	if est.VerifyHashes != 1+255+26*255/2.0+2 {
+		t.Fatalf("SecurityEstimate() returned wrong verify hash count: %.1f", est.VerifyHashes)
+	}
+
+	// RFC 8391: total = 67, W = 16
+	est = wotspSHA2_256Params.SecurityEstimate()
+
+	// SK (67) + 3 hashes per step + 4 hashes per L-tree node (66)
+	if est.KeyGenHashes != 67+3*67*15+4*66 {
+		t.Fatalf("SecurityEstimate() returned wrong keygen hash count for RFC 8391: %d", est.KeyGenHashes)
+	}
+
+	// XOFs are capped by their capacity
+	est = NewParamsW(48, 48, 16, hasher.SHAKE128, hasher.SHAKE128).SecurityEstimate()
+
+	if est.Classical != 128 || est.PostQuantum != 64 || est.MsgClassical != 128 || est.MsgPostQuantum != 64 {
+		t.Fatalf("SecurityEstimate() should cap security of SHAKE128 at 128 bits, got %+v", est)
+	}
+
+	// Smaller W is faster
+	fast := NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256).SecurityEstimate()
+	slow := NewParams(32, 32, hasher.BLAKE3_256, hasher.BLAKE3_256).SecurityEstimate()
+
+	if fast.KeyGenHashes >= slow.KeyGenHashes || fast.SignatureSize <= slow.SignatureSize {
+		t.Fatalf("SecurityEstimate() should give faster keygen and larger signatures for smaller W")
+	}
+}
+
+func TestNewParamsStrict(t *testing.T) {
+	min := SecurityLevels{Classical: 128, PostQuantum: 80}
+
+	// Test invalid params
+	if _, err := NewParamsStrict(32, 0, W, hasher.BLAKE3_256, hasher.BLAKE3_256, min); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if message size is 0")
+	}
+
+	if _, err := NewParamsStrict(32, 32, W, hasher.SHA3_224, hasher.BLAKE3_256, min); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if PRF hash size is smaller than n")
+	}
+
+	if _, err := NewParamsStrict(32, 32, W, hasher.BLAKE3_256, hasher.SHA3_224, min); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if MSG hash size is smaller than m")
+	}
+
+	if _, err := NewParamsStrict(32, 32, 8, hasher.BLAKE3_256, hasher.BLAKE3_256, min); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if W is invalid")
+	}
+
+	// Test params below minimum
+	if _, err := NewParamsStrict(16, 24, W, hasher.BLAKE3_256, hasher.BLAKE3_256, min); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if classical security is below minimum")
+	}
+
+	if _, err := NewParamsStrict(20, 24, W, hasher.BLAKE3_256, hasher.BLAKE3_256,
+		SecurityLevels{Classical: 128, PostQuantum: 96}); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if post quantum security is below minimum")
+	}
+
+	// Test message size below minimum
+	if _, err := NewParamsStrict(32, 1, 16, hasher.BLAKE3_256, hasher.BLAKE3_256,
+		SecurityLevels{Classical: 128, PostQuantum: 128}); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if classical message security is below minimum")
+	}
+
+	if _, err := NewParamsStrict(32, 20, 16, hasher.BLAKE3_256, hasher.BLAKE3_256,
+		SecurityLevels{Classical: 128, PostQuantum: 96}); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if post quantum message security is below minimum")
+	}
+
+	// Test hash functions below minimum
+	if _, err := NewParamsStrict(32, 32, 16, hasher.SHAKE128, hasher.BLAKE3_256,
+		SecurityLevels{Classical: 128, PostQuantum: 96}); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if PRF hash security is below minimum")
+	}
+
+	if _, err := NewParamsStrict(32, 32, 16, hasher.BLAKE3_256, hasher.SHAKE128,
+		SecurityLevels{Classical: 128, PostQuantum: 96}); err == nil {
+		t.Fatalf("NewParamsStrict() should return error if MSG hash security is below minimum")
+	}
+
+	// Test valid params
+	params, err := NewParamsStrict(level0N, level0M, W, level0PrfH, level0MsgH, min)
+
+	if err != nil {
+		t.Fatalf("NewParamsStrict() returned error for Level0 params: %s", err)
+	}
+
+	if !params.Equal(level0Params) {
+		t.Fatalf("NewParamsStrict() returned wrong params: %s", params)
+	}
+}
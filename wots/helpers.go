@@ -8,22 +8,40 @@ package wots
 
 import (
 	"encoding/binary"
+	"github.com/xx-labs/sleeve/hasher"
 	"hash"
 )
 
-func prf(dst []byte, h hash.Hash, seed []byte, idx uint8) []byte {
+// One byte slices for all byte values, so that indexes can be hashed without allocating
+var byteValues = func() []byte {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}()
+
+func byteSlice(b uint8) []byte {
+	return byteValues[b : int(b)+1]
+}
+
+// The n byte output is appended to dst, which should have enough capacity, see outputSize
+// The seed is nil if h is keyed with it, see keyed.go
+func prf(dst []byte, h hash.Hash, n int, seed []byte, idx ...byte) []byte {
 	h.Reset()
 	h.Write(seed)
-	h.Write([]byte{idx})
-	return h.Sum(dst)
+	h.Write(idx)
+	return hasher.SumN(h, dst, n)
 }
 
+// The output is appended to dst, which should have enough capacity
+// The seed is nil if h is keyed with it, see keyed.go
 func chain(dst []byte, h hash.Hash, seed []byte, idx uint8, maskedMsg []byte) []byte {
 	h.Reset()
 	h.Write(seed)
-	h.Write([]byte{idx})
+	h.Write(byteSlice(idx))
 	h.Write(maskedMsg)
-	return h.Sum(dst)
+	return hasher.SumN(h, dst, len(maskedMsg))
 }
 
 func checksum(msg []byte) []byte {
@@ -39,24 +57,55 @@ func checksum(msg []byte) []byte {
 	return out
 }
 
-func computeRands(n int, pSeed []byte, h hash.Hash) [][]byte {
-	// Buffer for hashing
-	buf := make([]byte, 0, h.Size())
+// Compute the checksum of base w digits, as len(out) base w digits
+// For w=256 this is equivalent to checksum
+func checksumW(out, digits []byte, w int) {
+	sum := 0
+	for _, d := range digits {
+		sum += w - 1 - int(d)
+	}
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = byte(sum % w)
+		sum /= w
+	}
+}
+
+// Split x into len(out) base w digits, where w = 2^logW
+// Digits are taken from the most significant bits of each byte first
+func baseW(out, x []byte, logW int) {
+	in := 0
+	bits := 0
+	total := byte(0)
+	mask := byte(1<<uint(logW) - 1)
+	for i := range out {
+		if bits == 0 {
+			total = x[in]
+			in++
+			bits = 8
+		}
+		bits -= logW
+		out[i] = (total >> uint(bits)) & mask
+	}
+}
+
+func computeRands(n, w int, pSeed []byte, h hash.Hash) [][]byte {
 	// Random elements memory
-	rands := make([][]byte, W-1)
+	rands := make([][]byte, w-1)
 	for i := range rands {
 		rands[i] = make([]byte, n)
 	}
+	fillRands(rands, pSeed, h, make([]byte, 0, outputSize(h, n)))
+	return rands
+}
 
-	// Compute all random elements
-	// There is one random element for each ladder depth, 1 to W-1
-	for i := uint8(0); i < W-1; i++ {
+// Compute all random elements into rands, using buf for hashing
+// There is one random element for each ladder depth, 1 to w-1
+func fillRands(rands [][]byte, pSeed []byte, h hash.Hash, buf []byte) {
+	for i := range rands {
 		// Rands[i] = H(PKSEED || i+1)
-		buf = prf(buf, h, pSeed, i+1)
-		copy(rands[i], buf[0:n])
-		buf = buf[:0]
+		buf = prf(buf[:0], h, len(rands[i]), pSeed, byteSlice(uint8(i+1))...)
+		copy(rands[i], buf)
 	}
-	return rands
 }
 
 // Check parity of value
@@ -70,3 +119,17 @@ func parity(value []byte) bool {
 	}
 	return count%2 == 1
 }
+
+// Get the capacity of a buffer to append n bytes of output of h without allocating,
+// which is the size of h for fixed size hash functions, see hasher.SumN
+func outputSize(h hash.Hash, n int) int {
+	if size := h.Size(); size > n {
+		return size
+	}
+	return n
+}
+
+// Check if the hash function can output size bytes, which XOFs always can
+func canOutput(h hasher.Hasher, size int) bool {
+	return h.XOF() || h.Size() >= size
+}
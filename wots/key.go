@@ -7,6 +7,7 @@
 package wots
 
 import (
+	"github.com/xx-labs/sleeve/internal/secmem"
 	"io"
 )
 
@@ -16,14 +17,25 @@ type Key struct {
 	seed []byte
 	// The public seed, used to generate random elements
 	pSeed []byte
-	// The full ladders of a key, once generated
+	// The ladders of a key, once generated
+	// Only every interval-th level is stored
 	chains [][]byte
+	// Checkpoint interval of the stored ladder levels (0 means 1, i.e., all levels)
+	interval int
+	// Random elements, stored when generated with interval > 1
+	rands [][]byte
 	// Flag to tell if the ladders have been generated
 	generated bool
+	// Flag to tell if the key memory is locked
+	locked bool
+	// Flag to tell if the key was destroyed
+	destroyed bool
 	// The public key
 	pk []byte
 	// The params of this key
 	params *Params
+	// Hash object keyed with the secret seed, only for the keyed construction
+	skHash *skHash
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -47,6 +59,7 @@ func NewKey(params *Params, csprng io.Reader) *Key {
 	if err != nil || n != SeedSize {
 		return nil
 	}
+	k.skHash = newSKHash(params)
 	return k
 }
 
@@ -68,6 +81,7 @@ func NewKeyFromSeed(params *Params, seed []byte, pSeed []byte) *Key {
 	}
 	copy(k.seed, seed)
 	copy(k.pSeed, pSeed)
+	k.skHash = newSKHash(params)
 	return k
 }
 
@@ -82,39 +96,73 @@ func (k *Key) GetPK() []byte {
 // COMPUTE PK
 // Compute the PK from this key's seeds, without storing ladder points
 // If PK was already computed, return it
+// Returns nil if the key was destroyed
 func (k *Key) ComputePK() []byte {
-	if k.pk != nil {
+	if k.pk != nil || k.destroyed {
 		return k.pk
 	}
+	k.pk = k.computePK(make([]byte, 0, PKSize))
+	return k.pk
+}
 
-	// Get PK by computing all ladders until the end
-	k.pk = make([]byte, 0, PKSize)
-	k.pk = k.params.computeLadders(k.pk, k.pSeed, nil, k.computeSK(), nil, false)
+// Compute the PK from this key's seeds and append it to out
+// If out has enough capacity, no memory is allocated
+// Unlike ComputePK, the PK is not stored in the key, unless it was already computed
+// Returns out unchanged if the key was destroyed
+func (k *Key) ComputePKTo(out []byte) []byte {
+	if k.destroyed {
+		return out
+	}
+	if k.pk != nil {
+		return append(out, k.pk...)
+	}
+	return k.computePK(out)
+}
 
-	return k.pk
+// Get PK by computing all ladders until the end, appending it to out
+func (k *Key) computePK(out []byte) []byte {
+	s := getScratch()
+	defer putScratch(s)
+	sk := k.computeSKTo(s)
+	return k.params.computeLadders(out, k.pSeed, nil, sk, nil, false)
 }
 
 ///////////////////////////////////////////////////////////////////////
 // GENERATE
 // Generate all the ladder values in order to speed up signing
-// If ladders are already generated, simply return
+// Only every k-th level is stored, according to the checkpoint interval
+// If ladders are already generated, or the key was destroyed, simply return
 func (k *Key) Generate() {
-	if k.generated {
+	if k.generated || k.destroyed {
 		return
 	}
 
-	// Create memory for all ladders
-	k.chains = make([][]byte, W)
+	// Create memory for stored ladder levels
+	interval := k.CheckpointInterval()
+	k.chains = make([][]byte, k.params.checkpointLevels(interval))
 	for i := range k.chains {
 		k.chains[i] = make([]byte, k.params.n*k.params.total)
 	}
 
 	// Compute Secret Keys and place them at the beginning of chains memory
-	k.chains[0] = k.computeSK()
+	s := getScratch()
+	copy(k.chains[0], k.computeSKTo(s))
+	putScratch(s)
+
+	// Random elements are needed to sign from a checkpoint
+	k.rands = nil
+	if interval > 1 && k.params.construction != ConstructionRFC8391 {
+		k.rands = k.params.newRands(k.pSeed)
+	}
 
-	// Get PK by computing all ladders until the end, while saving all ladder positions to memory
+	// Get PK by computing all ladders until the end, while saving ladder positions to memory
 	k.pk = make([]byte, 0, PKSize)
-	k.pk = k.params.computeLadders(k.pk, k.pSeed, nil, k.chains[0], k.chains, false)
+	k.pk = k.params.computeLaddersWithRands(k.pk, k.pSeed, nil, k.chains[0], k.chains, false, k.rands, interval)
+
+	// Lock new ladders if key memory is locked (best effort)
+	if k.locked {
+		k.lockChains()
+	}
 
 	// Set generated flag
 	k.generated = true
@@ -127,60 +175,116 @@ func (k *Key) Generate() {
 // Note: If the key is already generated, this function is fast,
 // since it simply hashes the message and then copies the correct
 // positions of the ladders
+// Returns nil if the key was destroyed
 func (k *Key) Sign(msg []byte) []byte {
+	if k.destroyed {
+		return nil
+	}
+	return k.SignTo(make([]byte, 0, 1+k.params.sigSize()), msg)
+}
+
+// Signs an arbitrary length message using the WOTS+ key, like Sign,
+// appending the signature to out
+// If out has enough capacity, no memory is allocated
+// Returns out unchanged if the key was destroyed
+func (k *Key) SignTo(out, msg []byte) []byte {
+	if k.destroyed {
+		return out
+	}
+
+	// Signature header, see Signature for the serialized layout
+	out = append(out, byte(EncodeParams(k.params)))
+	out = append(out, k.pSeed...)
+
+	// Hash the message, appending the randomizer if used
+	s := getScratch()
+	defer putScratch(s)
+	out, digest := k.hashMsg(s, out, msg)
+	return k.signDigest(out, digest)
+}
+
+// Append the ladder points of the signature of the m byte message digest to out
+// For target sum params, the counter is appended first, see targetsum.go
+// Returns nil if no counter reaches the target sum
+func (k *Key) signDigest(out, digest []byte) []byte {
+	// Grind the counter and sign the target digest
+	if k.params.targetSum != 0 {
+		s := getScratch()
+		defer putScratch(s)
+		var ok bool
+		if out, digest, ok = k.params.grindTargetSum(s, out, digest); !ok {
+			return nil
+		}
+	}
+
 	// If all ladders have been generated, use fast signing
 	if k.generated {
-		return k.fastSign(msg)
+		return k.fastSign(out, digest)
 	}
 
 	// Otherwise, compute the signature from scratch
-	// Get the signature by computing ladder points according to message
-	signature := k.params.computeLadders(nil, k.pSeed, msg, k.computeSK(), nil, true)
-
-	// Build signature
-	return k.buildSignature(signature)
+	// Get the signature by computing ladder points according to message digest
+	s := getScratch()
+	defer putScratch(s)
+	sk := k.computeSKTo(s)
+	return k.params.computeLadders(out, k.pSeed, digest, sk, nil, true)
 }
 
-func (k *Key) fastSign(msg []byte) []byte {
-	// Compute message hash and checksum
-	data := k.params.msgHashAndComputeChecksum(msg)
+// Append the ladder points of the signature to out, using the generated ladders
+func (k *Key) fastSign(out, digest []byte) []byte {
+	s := getScratch()
+	defer putScratch(s)
+
+	// Compute checksum
+	data := k.params.digestDigits(s, digest)
 
 	// Get the signature by copying the ladder positions from memory according to message
-	signature := make([]byte, k.params.total*k.params.n)
+	out, signature := extend(out, k.params.total*k.params.n)
+	interval := k.CheckpointInterval()
+	w := &s.getWorkers(1)[0]
 	for i := 0; i < k.params.total; i++ {
-		copy(signature[i*k.params.n:(i+1)*k.params.n], k.chains[int(data[i])][i*k.params.n:(i+1)*k.params.n])
-	}
+		point := signature[i*k.params.n : (i+1)*k.params.n]
+		level := int(data[i]) / interval
+		copy(point, k.chains[level][i*k.params.n:(i+1)*k.params.n])
 
-	// Build signature
-	return k.buildSignature(signature)
+		// Walk the remaining steps from the closest stored level, at most interval-1
+		if interval > 1 {
+			k.params.walkLadder(w, point, k.pSeed, i, level*interval, int(data[i]), k.rands)
+		}
+	}
+	return out
 }
 
+// Compute the secret keys
 func (k *Key) computeSK() []byte {
-	// Create secret keys slice
+	s := getScratch()
+	defer putScratch(s)
 	sks := make([]byte, k.params.n*k.params.total)
+	copy(sks, k.computeSKTo(s))
+	return sks
+}
+
+// Compute the secret keys into the scratch memory
+func (k *Key) computeSKTo(s *scratch) []byte {
+	s.sks = grow(s.sks, k.params.n*k.params.total)
+	sks := s.sks
+	if k.params.construction == ConstructionRFC8391 {
+		k.params.rfcComputeSK(s, sks, k.seed, k.pSeed)
+		return sks
+	}
 	// Get PRF hash
-	hPrf := k.params.prfHash.New()
+	hPrf, seed := k.getSKHash()
+	defer k.putSKHash(hPrf)
 	// Hash buffer
-	prfBuffer := make([]byte, 0, hPrf.Size())
+	s.buf = growCap(s.buf, outputSize(hPrf, k.params.n))
+	prfBuffer := s.buf
 
 	// Compute SK_i = H(SEED || i)
 	for i := 0; i < k.params.total; i++ {
-		prfBuffer = prf(prfBuffer, hPrf, k.seed, uint8(i))
+		prfBuffer = prf(prfBuffer, hPrf, k.params.n, seed, k.params.encodeIndex(s.idx[:0], i)...)
 		copy(sks[i*k.params.n:(i+1)*k.params.n], prfBuffer[0:k.params.n])
 		prfBuffer = prfBuffer[:0]
 	}
+	secmem.Wipe(prfBuffer[:cap(prfBuffer)])
 	return sks
 }
-
-func (k *Key) buildSignature(sig []byte) []byte {
-	encode := EncodeParams(k.params)
-	// Signature is composed by
-	// ParamsEncoding, 1 byte
-	// Public Seed,    32 bytes
-	// Ladder points,  Total*n bytes
-	signature := make([]byte, 1+SeedSize+len(sig))
-	signature[0] = byte(encode)
-	copy(signature[1:1+SeedSize], k.pSeed)
-	copy(signature[1+SeedSize:], sig)
-	return signature
-}
@@ -266,6 +266,88 @@ func TestKey_Sign_Consistency(t *testing.T) {
 	}
 }
 
+func TestKey_Sign_Consistency_W(t *testing.T) {
+	// Test all values of w, including more than 256 ladders
+	tests := []struct {
+		m, w int
+	}{
+		{32, 16},
+		{32, 4},
+		{64, 16},
+		{64, 4},
+	}
+
+	for _, tt := range tests {
+		params := NewParamsW(32, tt.m, tt.w, hasher.BLAKE3_256, hasher.BLAKE2B_512)
+		key := NewKey(params, rand.Reader)
+
+		if key == nil {
+			t.Fatalf("NewKey returned nil")
+		}
+
+		msg := getRandData(t, 256)
+
+		// Sign without chains
+		sig := key.Sign(msg)
+		pk := key.ComputePK()
+
+		valid, _ := params.Verify(msg, sig[1:], pk)
+
+		if !valid {
+			t.Fatalf("Key.Sign + Params.Verify are not consistent for m = %d and w = %d", tt.m, tt.w)
+		}
+
+		// Sign with chains
+		key.Generate()
+		fastSig := key.Sign(msg)
+
+		if !bytes.Equal(sig, fastSig) {
+			t.Fatalf("Key.Sign returned different signatures with and without Generate for m = %d and w = %d",
+				tt.m, tt.w)
+		}
+
+		if !bytes.Equal(pk, key.GetPK()) {
+			t.Fatalf("Key.GetPK and Key.ComputePK are not consistent for m = %d and w = %d", tt.m, tt.w)
+		}
+	}
+}
+
+func TestParams_MsgDigits_W16(t *testing.T) {
+	params := NewParamsW(32, 32, 16, hasher.SHA3_256, hasher.SHA3_256)
+
+	digits := params.msgHashAndComputeChecksum([]byte(TestData))
+
+	if !bytes.Equal(digits, TestVector16) {
+		t.Fatalf("Invalid message digits for W = 16! Got: %v, Expected: %v", digits, TestVector16)
+	}
+
+	// Edge cases of all 0's and all 15's
+	out := make([]byte, 3)
+	checksumW(out, make([]byte, 64), 16)
+
+	if !bytes.Equal(out, []byte{3, 12, 0}) {
+		t.Fatalf("Invalid checksum for W = 16! Got: %v, Expected: %v", out, []byte{3, 12, 0})
+	}
+
+	dat := make([]byte, 64)
+	for i := range dat {
+		dat[i] = 15
+	}
+	checksumW(out, dat, 16)
+
+	if !bytes.Equal(out, []byte{0, 0, 0}) {
+		t.Fatalf("Invalid checksum for W = 16! Got: %v, Expected: %v", out, []byte{0, 0, 0})
+	}
+
+	// Check W = 256 digits are the same as checksum
+	dat = getRandData(t, 32)
+	checksumW(out[:2], dat, W)
+
+	if !bytes.Equal(out[:2], checksum(dat)) {
+		t.Fatalf("checksumW() with W = %d should be the same as checksum()", W)
+	}
+}
+
 /////////////////////////////////////////////////
 ///////////////// TEST VECTORS //////////////////
 /////////////////////////////////////////////////
@@ -332,6 +414,23 @@ var TestVector192 = []uint8{
 // 255*24 - SUM(TestVector192[0:23]) = 255*24 - 3139 = 2981
 const Checksum192 = "0ba5"
 
+/////////////////////////////////////////////////
+// Indexes computed as follows, for W = 16:
+// 1. H = SHA3_256("XX NETWORK")
+// 2. D = BASE_16(H), splitting each byte of H into 2 digits, most significant first
+// 3. CHECK = BASE_16(SUM(15 - D[i])), as 3 digits
+// 4. TestVector16 = D || CHECK
+var TestVector16 = []uint8{
+	2, 6, 7, 15, 15, 9, 12, 14, 13, 12, 7, 0, 10, 11, 14, 2,
+	11, 15, 3, 2, 3, 15, 13, 12, 4, 8, 0, 3, 11, 13, 13, 1,
+	15, 11, 11, 6, 0, 0, 5, 6, 6, 2, 7, 1, 2, 11, 10, 14,
+	0, 7, 5, 15, 7, 3, 9, 1, 13, 8, 8, 0, 4, 0, 0, 1,
+	// Checksum
+	1, 15, 0,
+}
+
+// 15*64 - SUM(TestVector16[0:63]) = 15*64 - 464 = 496 = 0x1f0
+
 /////////////////////////////////////////////////
 
 func TestChecksum(t *testing.T) {
@@ -0,0 +1,155 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"github.com/xx-labs/sleeve/hasher"
+	"hash"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// KEYED HASH CONSTRUCTION
+/*
+	The xx network construction prefixes the seed to every PRF input:
+	  sk_i    = PRF(SEED || i)
+	  rands_i = PRF(PSEED || i)
+	  ladder  = PRF(PSEED || l || masked value)
+	The keyed construction uses the native keyed mode of the PRF hash
+	function instead, with the seed as the key, see hasher.NewKeyed:
+	  sk_i    = PRF_SEED(i)
+	  rands_i = PRF_PSEED(i)
+	  ladder  = PRF_PSEED(l || masked value)
+	The public key is computed the same way as for the xx network
+	construction. Since the ladders are different, the same seeds give
+	different public keys for each construction
+
+	The keyed state is set up once per seed, and reused for every ladder
+	step, since Reset keeps the key. Hash objects keyed with a public seed
+	are kept in the scratch and worker memory, and reused while the public
+	seed doesn't change. The hash object keyed with the secret seed is
+	created when first needed and kept in the key, which overwrites it
+	with hasher.Destroy when the key is destroyed
+
+	With BLAKE3, a ladder step hashes 1+n bytes instead of 33+n bytes. For
+	n = 32 this is one compression instead of two, so computing the ladders
+	takes less time, see BenchmarkKeyed_ComputePK, while for n < 32 both
+	fit in one 64 byte block and take the same time. BLAKE2b compresses
+	the key as an extra 128 byte block, so it is slower in keyed mode, and
+	the registered keyed params use BLAKE3, see security.go
+*/
+
+// Creates WOTS+ params with given values of n, m, w; prf and msg hashes,
+// like NewParamsW, using the keyed hash construction
+// Returns nil if the prf hash function has no keyed mode
+func NewParamsKeyed(n, m, w int, prf, msg hasher.Hasher) *Params {
+	if !prf.Keyed() {
+		return nil
+	}
+	params := NewParamsW(n, m, w, prf, msg)
+	if params == nil {
+		return nil
+	}
+	params.construction = ConstructionKeyed
+	return params
+}
+
+// Hash object keyed with a public seed, cached in scratch or worker memory
+type keyedHash struct {
+	h     hash.Hash
+	prf   hasher.Hasher
+	pSeed [SeedSize]byte
+}
+
+// Get a hash object keyed with the public seed, creating it only
+// if the cached one has a different seed or hash function
+func (c *keyedHash) get(prf hasher.Hasher, pSeed []byte) hash.Hash {
+	if c.h == nil || c.prf != prf || !bytes.Equal(c.pSeed[:], pSeed) {
+		c.h = prf.NewKeyed(pSeed)
+		c.prf = prf
+		copy(c.pSeed[:], pSeed)
+	}
+	return c.h
+}
+
+// Get a hash object for prf and chain calls with the public seed, and the prefix to hash
+// For the keyed construction, the hash object is keyed with the public seed and cached in c,
+// and the prefix is nil. Otherwise, it's taken from the pool of the PRF hash function, and
+// the prefix is the public seed
+// The hash object must be released with putSeeded
+func (p *Params) getSeeded(c *keyedHash, pSeed []byte) (hash.Hash, []byte) {
+	if p.construction == ConstructionKeyed {
+		return c.get(p.prfHash, pSeed), nil
+	}
+	return p.prfHash.Get(), pSeed
+}
+
+// Release a hash object obtained with getSeeded
+func (p *Params) putSeeded(h hash.Hash) {
+	if p.construction != ConstructionKeyed {
+		p.prfHash.Put(h)
+	}
+}
+
+// Compute all random elements for the public seed into new memory
+func (p *Params) newRands(pSeed []byte) [][]byte {
+	if p.construction == ConstructionKeyed {
+		return computeRands(p.n, p.w, nil, p.prfHash.NewKeyed(pSeed))
+	}
+	return computeRands(p.n, p.w, pSeed, p.prfHash.New())
+}
+
+// Hash object keyed with the secret seed of a key
+// Locked while in use, so the secret keys of a key can be computed concurrently
+type skHash struct {
+	sync.Mutex
+	h hash.Hash
+}
+
+// Create the holder of the hash object keyed with the secret seed
+// Returns nil if the params don't use the keyed construction
+func newSKHash(params *Params) *skHash {
+	if params == nil || params.construction != ConstructionKeyed {
+		return nil
+	}
+	return &skHash{}
+}
+
+// Overwrite the hash object keyed with the secret seed, if it was created
+func (c *skHash) destroy() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	hasher.Destroy(c.h)
+	c.h = nil
+}
+
+// Get a hash object for prf calls with the secret seed, and the prefix to hash, like getSeeded
+// Unkeyed hash objects are taken from the secret pool of the PRF hash function, see hasher.GetSecret
+// The hash object must be released with putSKHash
+func (k *Key) getSKHash() (hash.Hash, []byte) {
+	if k.skHash != nil {
+		k.skHash.Lock()
+		if k.skHash.h == nil {
+			k.skHash.h = k.params.prfHash.NewKeyed(k.seed)
+		}
+		return k.skHash.h, nil
+	}
+	return k.params.prfHash.GetSecret(), k.seed
+}
+
+// Release a hash object obtained with getSKHash
+func (k *Key) putSKHash(h hash.Hash) {
+	if k.skHash != nil {
+		k.skHash.Unlock()
+		return
+	}
+	k.params.prfHash.PutSecret(h)
+}
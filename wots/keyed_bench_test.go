@@ -0,0 +1,73 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"github.com/xx-labs/sleeve/hasher"
+	"testing"
+)
+
+// Params with the xx network construction, and the same params with the keyed construction
+func keyedBenchParams() [][2]*Params {
+	var list [][2]*Params
+	for _, prf := range []hasher.Hasher{hasher.BLAKE2B_256, hasher.BLAKE3_256} {
+		for _, n := range []int{level0N, level3N} {
+			list = append(list, [2]*Params{
+				NewParams(n, level0M, prf, level0MsgH),
+				NewParamsKeyed(n, level0M, W, prf, level0MsgH),
+			})
+		}
+	}
+	return list
+}
+
+func BenchmarkKeyed_Generate(b *testing.B) {
+	initTestData()
+	for _, pair := range keyedBenchParams() {
+		for _, params := range pair {
+			params := params
+			b.Run(params.String(), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					key := NewKeyFromSeed(params, t.seed, t.pSeed)
+					key.Generate()
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkKeyed_ComputePK(b *testing.B) {
+	initTestData()
+	for _, pair := range keyedBenchParams() {
+		for _, params := range pair {
+			key := NewKeyFromSeed(params, t.seed, t.pSeed)
+			out := make([]byte, 0, PKSize)
+			b.Run(params.String(), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					out = key.ComputePKTo(out[:0])
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkKeyed_Verify(b *testing.B) {
+	initTestData()
+	for _, pair := range keyedBenchParams() {
+		for _, params := range pair {
+			params := params
+			key := NewKeyFromSeed(params, t.seed, t.pSeed)
+			sig := key.Sign(t.msg)
+			pk := key.ComputePK()
+			b.Run(params.String(), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, _ = params.Verify(t.msg, sig[1:], pk)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,183 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"github.com/xx-labs/sleeve/hasher"
+	"strings"
+	"testing"
+)
+
+var keyedEncodings = []ParamsEncoding{Level0Keyed, Level1Keyed, Level2Keyed, Level3Keyed}
+
+func TestNewParamsKeyed(t *testing.T) {
+	if NewParamsKeyed(32, 24, 16, hasher.SHA3_256, hasher.SHA3_256) != nil {
+		t.Fatalf("NewParamsKeyed() should return nil for PRF hash without keyed mode")
+	}
+
+	if NewParamsKeyed(32, 24, 8, hasher.BLAKE3_256, hasher.SHA3_256) != nil {
+		t.Fatalf("NewParamsKeyed() should return nil for invalid params")
+	}
+
+	params := NewParamsKeyed(level0N, level0M, W, level0PrfH, level0MsgH)
+	if params.Equal(level0Params) {
+		t.Fatalf("Equal() should return false for params with different construction")
+	}
+
+	if !strings.HasSuffix(params.String(), ", KEYED") {
+		t.Fatalf("String() should show the keyed construction, got %s", params)
+	}
+
+	for _, enc := range keyedEncodings {
+		if DecodeParams(enc).construction != ConstructionKeyed || DecodeParams(enc).prfHash != hasher.BLAKE3_256 {
+			t.Fatalf("DecodeParams() should return keyed params with BLAKE3_256 PRF for %s", enc)
+		}
+	}
+}
+
+// Compute the public key with keyed hash objects directly, see keyed.go
+func keyedReferencePK(params *Params, seed, pSeed []byte) []byte {
+	hSeed := params.prfHash.NewKeyed(seed)
+	hPSeed := params.prfHash.NewKeyed(pSeed)
+	n := params.n
+
+	// rands_i = PRF_PSEED(i)
+	rands := make([][]byte, params.w-1)
+	for i := range rands {
+		hPSeed.Reset()
+		hPSeed.Write([]byte{byte(i + 1)})
+		rands[i] = hPSeed.Sum(nil)[:n]
+	}
+
+	outputs := append([]byte{}, pSeed...)
+	for i := 0; i < params.total; i++ {
+		// sk_i = PRF_SEED(i)
+		hSeed.Reset()
+		hSeed.Write(params.encodeIndex(nil, i))
+		value := hSeed.Sum(nil)[:n]
+
+		// ladder = PRF_PSEED(l || masked value)
+		for l := 0; l < params.w-1; l++ {
+			for z := range value {
+				value[z] ^= rands[l][z]
+			}
+			hPSeed.Reset()
+			hPSeed.Write([]byte{byte(l + 1)})
+			hPSeed.Write(value)
+			value = hPSeed.Sum(nil)[:n]
+		}
+		outputs = append(outputs, value...)
+	}
+	return params.tweak(outputs)
+}
+
+func TestKey_ComputePK_Keyed(t *testing.T) {
+	for _, params := range []*Params{
+		NewParamsKeyed(32, 24, 4, hasher.BLAKE3_256, hasher.SHA3_256),
+		NewParamsKeyed(20, 24, 16, hasher.BLAKE2B_256, hasher.SHA3_224),
+		DecodeParams(Level3Keyed),
+	} {
+		seed := getRandData(t, SeedSize)
+		pSeed := getRandData(t, SeedSize)
+		key := NewKeyFromSeed(params, seed, pSeed)
+
+		if !bytes.Equal(key.ComputePK(), keyedReferencePK(params, seed, pSeed)) {
+			t.Fatalf("ComputePK() returned wrong PK for keyed params %s", params)
+		}
+
+		// Same seeds, different construction
+		if bytes.Equal(key.ComputePK(), NewKeyFromSeed(NewParamsW(params.n, params.m, params.w, params.prfHash,
+			params.msgHash), seed, pSeed).ComputePK()) {
+			t.Fatalf("ComputePK() should return a different PK for the xx network construction")
+		}
+	}
+}
+
+func TestKey_Sign_Keyed(t *testing.T) {
+	msg := getRandData(t, 256)
+	for _, enc := range keyedEncodings {
+		params := DecodeParams(enc)
+		seed := getRandData(t, SeedSize)
+		pSeed := getRandData(t, SeedSize)
+		key := NewKeyFromSeed(params, seed, pSeed)
+		pk := key.ComputePK()
+		sig := key.Sign(msg)
+
+		if len(sig) != SignatureSize(enc) {
+			t.Fatalf("Sign() returned signature with wrong size for %s: %d", enc, len(sig))
+		}
+
+		ok, err := Verify(msg, sig, pk)
+		if err != nil || !ok {
+			t.Fatalf("Verify() should return true for valid %s signature: %v", enc, err)
+		}
+
+		// Generated keys, with and without checkpoints, sign the same
+		key.Generate()
+		if !bytes.Equal(key.Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for generated key with %s", enc)
+		}
+		_ = key.SetCheckpointInterval(16)
+		key.Generate()
+		if !bytes.Equal(key.Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for generated key with checkpoints with %s", enc)
+		}
+
+		// Serialized keys sign the same
+		data, err := key.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() returned error for %s: %s", enc, err)
+		}
+		nk := new(Key)
+		if err = nk.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() returned error for %s: %s", enc, err)
+		}
+		if !bytes.Equal(nk.Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for unmarshalled key with %s", enc)
+		}
+
+		// Destroyed keys overwrite their keyed hash object
+		h := key.skHash.h
+		key.Destroy()
+		if h == nil || key.skHash.h != nil || h.Size() != 0 {
+			t.Fatalf("Destroy() should overwrite the hash object keyed with the secret seed")
+		}
+	}
+}
+
+func TestVerify_KeyedPublicSeeds(t *testing.T) {
+	// Cached hash objects keyed with a public seed are only reused for the same seed
+	params := DecodeParams(Level0Keyed)
+	msg := getRandData(t, 256)
+	var items []Item
+	for i := 0; i < 3; i++ {
+		key := NewKeyFromSeed(params, getRandData(t, SeedSize), getRandData(t, SeedSize))
+		items = append(items, Item{Msg: msg, Signature: key.Sign(msg), PublicKey: key.ComputePK()})
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, item := range items {
+			if ok, err := Verify(item.Msg, item.Signature, item.PublicKey); !ok || err != nil {
+				t.Fatalf("Verify() should return true for signatures with different public seeds: %v", err)
+			}
+		}
+	}
+
+	// Swapping public seeds invalidates the signatures
+	sig := append([]byte{}, items[0].Signature...)
+	copy(sig[1:1+SeedSize], items[1].Signature[1:1+SeedSize])
+	if ok, _ := Verify(msg, sig, items[0].PublicKey); ok {
+		t.Fatalf("Verify() should return false for signature with a different public seed")
+	}
+
+	for i, result := range VerifyBatch(items) {
+		if !result.Valid || result.Err != nil {
+			t.Fatalf("VerifyBatch() should return valid result for keyed signature %d: %v", i, result.Err)
+		}
+	}
+}
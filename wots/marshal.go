@@ -0,0 +1,334 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+///////////////////////////////////////////////////////////////////////
+// KEY SERIALIZATION
+/*
+	A Key is serialized as:
+	  Version,         1 byte
+	  Flags,           1 byte
+	  Salt,            16 bytes             (only if encrypted)
+	  Nonce,           24 bytes             (only if encrypted)
+	  Payload,         encrypted with a 16 byte tag appended (if encrypted)
+
+	The payload is composed by:
+	  ParamsEncoding, 1 byte
+	  Secret Seed,    32 bytes
+	  Public Seed,    32 bytes
+	  Interval,       2 bytes              (only if chains are included)
+	  Public Key,     32 bytes             (only if chains are included)
+	  Chains,         levels*total*n bytes (only if chains are included)
+
+	Chains are only included if the key was generated, so that the
+	key can be loaded with fast signing ready. Only the levels stored
+	according to the checkpoint interval are included, see checkpoint.go
+
+	When chains are loaded, the secret keys (level 0) are recomputed
+	from the secret seed, and the public key from the last stored level,
+	and the key is rejected if they don't match. Intermediate levels are
+	not checked, since that costs as much as generating the key, so they
+	are only protected against tampering when the key is encrypted
+
+	When encrypted with a passphrase, the encryption key is derived
+	using Argon2id with a random salt, and the payload is encrypted
+	using XChaCha20-Poly1305, with the version, flags, salt and nonce
+	as additional data. The key is then authenticated as well
+*/
+
+// Serialization format version
+const keyVersion = 1
+
+// Flags
+const (
+	keyFlagChains    = 1 << 0
+	keyFlagEncrypted = 1 << 1
+	keyFlagsKnown    = keyFlagChains | keyFlagEncrypted
+)
+
+// Argon2id parameters (RFC 9106, second recommended option)
+const (
+	keySaltSize      = 16
+	keyArgonTime     = 3
+	keyArgonMemory   = 64 * 1024
+	keyArgonThreads  = 4
+	keyTagSize       = 16
+	keyHeaderSize    = 2
+	keyEncHeaderSize = keyHeaderSize + keySaltSize + chacha20poly1305.NonceSizeX
+)
+
+var (
+	errKeyDataTooShort   = errors.New("serialized key is too short")
+	errKeyEncrypted      = errors.New("serialized key is encrypted: passphrase is required")
+	errKeyNotEncrypted   = errors.New("serialized key is not encrypted")
+	errKeyDecrypt        = errors.New("couldn't decrypt serialized key: wrong passphrase or corrupted data")
+	errKeyUnknownParams  = errors.New("key params don't have an encoding and can't be serialized")
+	errKeyEmptyPassword  = errors.New("passphrase can't be empty")
+	errKeyInvalidPayload = errors.New("serialized key payload is invalid")
+	errKeyDestroyed      = errors.New("key was destroyed and can't be serialized")
+	errKeyChainsMismatch = errors.New("serialized key chains or public key don't match its seeds")
+)
+
+// Serialize the key, including the chains if the key was generated
+// WARNING: The output contains the secret seed in plaintext
+func (k *Key) MarshalBinary() ([]byte, error) {
+	payload, flags, err := k.marshalPayload()
+	if err != nil {
+		return nil, err
+	}
+	defer secmem.Wipe(payload)
+	return append([]byte{keyVersion, flags}, payload...), nil
+}
+
+// Serialize the key, encrypting it with a key derived from the passphrase
+func (k *Key) MarshalBinaryWithPassphrase(passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errKeyEmptyPassword
+	}
+
+	// 1. Get payload
+	payload, flags, err := k.marshalPayload()
+	if err != nil {
+		return nil, err
+	}
+	defer secmem.Wipe(payload)
+
+	// 2. Build header with random salt and nonce
+	header := make([]byte, keyEncHeaderSize, keyEncHeaderSize+len(payload)+keyTagSize)
+	header[0] = keyVersion
+	header[1] = flags | keyFlagEncrypted
+	if _, err := rand.Read(header[keyHeaderSize:]); err != nil {
+		return nil, err
+	}
+	salt := header[keyHeaderSize : keyHeaderSize+keySaltSize]
+	nonce := header[keyHeaderSize+keySaltSize:]
+
+	// 3. Encrypt payload, authenticating header
+	aead, err := keyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, payload, header), nil
+}
+
+// Deserialize a key that is not encrypted
+func (k *Key) UnmarshalBinary(data []byte) error {
+	flags, err := checkKeyHeader(data)
+	if err != nil {
+		return err
+	}
+	if flags&keyFlagEncrypted != 0 {
+		return errKeyEncrypted
+	}
+	return k.unmarshalPayload(data[keyHeaderSize:], flags)
+}
+
+// Deserialize a key encrypted with the passphrase
+func (k *Key) UnmarshalBinaryWithPassphrase(data []byte, passphrase string) error {
+	flags, err := checkKeyHeader(data)
+	if err != nil {
+		return err
+	}
+	if flags&keyFlagEncrypted == 0 {
+		return errKeyNotEncrypted
+	}
+	if len(data) < keyEncHeaderSize+keyTagSize {
+		return errKeyDataTooShort
+	}
+
+	// 1. Get salt and nonce
+	header := data[:keyEncHeaderSize]
+	salt := header[keyHeaderSize : keyHeaderSize+keySaltSize]
+	nonce := header[keyHeaderSize+keySaltSize:]
+
+	// 2. Decrypt payload
+	aead, err := keyCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	payload, err := aead.Open(nil, nonce, data[keyEncHeaderSize:], header)
+	if err != nil {
+		return errKeyDecrypt
+	}
+	defer secmem.Wipe(payload)
+	return k.unmarshalPayload(payload, flags)
+}
+
+///////////////////////////////////////////////////////////////////////
+// PRIVATE
+
+// Get the serialized payload and flags for this key
+func (k *Key) marshalPayload() ([]byte, byte, error) {
+	if k.destroyed {
+		return nil, 0, errKeyDestroyed
+	}
+	enc := EncodeParams(k.params)
+	if DecodeParams(enc) == nil {
+		return nil, 0, errKeyUnknownParams
+	}
+
+	flags := byte(0)
+	size := 1 + 2*SeedSize
+	if k.generated {
+		flags |= keyFlagChains
+		size += 2 + PKSize + len(k.chains)*k.params.total*k.params.n
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, byte(enc))
+	payload = append(payload, k.seed...)
+	payload = append(payload, k.pSeed...)
+	if k.generated {
+		interval := k.CheckpointInterval()
+		payload = append(payload, byte(interval>>8), byte(interval))
+		payload = append(payload, k.pk...)
+		for _, c := range k.chains {
+			payload = append(payload, c...)
+		}
+	}
+	return payload, flags, nil
+}
+
+// Set this key from the serialized payload
+// The previous secrets of the key are wiped
+func (k *Key) unmarshalPayload(payload []byte, flags byte) error {
+	if len(payload) < 1+2*SeedSize {
+		return errKeyDataTooShort
+	}
+
+	// 1. Get params
+	params := DecodeParams(ParamsEncoding(payload[0]))
+	if params == nil {
+		return errDecodingParams
+	}
+
+	// 2. Create key from seeds
+	nk := NewKeyFromSeed(params, payload[1:1+SeedSize], payload[1+SeedSize:1+2*SeedSize])
+	if err := nk.unmarshalChains(payload[1+2*SeedSize:], flags); err != nil {
+		nk.Destroy()
+		return err
+	}
+
+	// 3. Replace this key
+	k.Destroy()
+	*k = *nk
+	return nil
+}
+
+// Set the public key and chains of this key from the serialized data, if included
+func (k *Key) unmarshalChains(data []byte, flags byte) error {
+	if flags&keyFlagChains == 0 {
+		if len(data) != 0 {
+			return errKeyInvalidPayload
+		}
+		return nil
+	}
+
+	// 1. Get checkpoint interval
+	if len(data) < 2 {
+		return errKeyDataTooShort
+	}
+	interval := int(data[0])<<8 | int(data[1])
+	data = data[2:]
+	if err := k.SetCheckpointInterval(interval); err != nil {
+		return err
+	}
+
+	// 2. Check size
+	params := k.params
+	ladderSize := params.total * params.n
+	levels := params.checkpointLevels(interval)
+	if len(data) != PKSize+levels*ladderSize {
+		return errKeyInvalidPayload
+	}
+
+	// 3. Get public key and chains
+	k.pk = make([]byte, PKSize)
+	copy(k.pk, data[:PKSize])
+	data = data[PKSize:]
+	k.chains = make([][]byte, levels)
+	for i := range k.chains {
+		k.chains[i] = make([]byte, ladderSize)
+		copy(k.chains[i], data[i*ladderSize:(i+1)*ladderSize])
+	}
+	if interval > 1 && params.construction != ConstructionRFC8391 {
+		k.rands = params.newRands(k.pSeed)
+	}
+
+	// 4. Check the secret keys and public key against the seeds
+	if !k.checkChains() {
+		return errKeyChainsMismatch
+	}
+	k.generated = true
+	return nil
+}
+
+// Check the loaded secret keys and public key of the key against its seeds
+// The secret keys are recomputed from the secret seed, and the public key by
+// walking the ladders from the last stored level, see unmarshalChains
+func (k *Key) checkChains() bool {
+	s := getScratch()
+	defer putScratch(s)
+	p := k.params
+
+	// 1. Check secret keys
+	if subtle.ConstantTimeCompare(k.computeSKTo(s), k.chains[0]) != 1 {
+		return false
+	}
+
+	// 2. Walk the ladders from the last stored level to the end
+	interval := k.CheckpointInterval()
+	level := (len(k.chains) - 1) * interval
+	outputs := make([]byte, len(k.chains[len(k.chains)-1]))
+	copy(outputs, k.chains[len(k.chains)-1])
+	if level < p.w-1 {
+		rands := k.rands
+		if rands == nil && p.construction != ConstructionRFC8391 {
+			rands = p.newRands(k.pSeed)
+		}
+		w := &s.getWorkers(1)[0]
+		for i := 0; i < p.total; i++ {
+			p.walkLadder(w, outputs[i*p.n:(i+1)*p.n], k.pSeed, i, level, p.w-1, rands)
+		}
+	}
+
+	// 3. Check public key
+	pk := p.hashPK(s, make([]byte, 0, PKSize), outputs, k.pSeed)
+	return subtle.ConstantTimeCompare(pk, k.pk) == 1
+}
+
+// Check the version and get the flags from the serialized key
+func checkKeyHeader(data []byte) (byte, error) {
+	if len(data) < keyHeaderSize {
+		return 0, errKeyDataTooShort
+	}
+	if data[0] != keyVersion {
+		return 0, errors.New(fmt.Sprintf("unsupported serialized key version: %d", data[0]))
+	}
+	if data[1]&^keyFlagsKnown != 0 {
+		return 0, errors.New(fmt.Sprintf("unknown serialized key flags: %#x", data[1]&^keyFlagsKnown))
+	}
+	return data[1], nil
+}
+
+// Derive the encryption key from the passphrase and salt and get the cipher
+func keyCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, keyArgonTime, keyArgonMemory, keyArgonThreads, chacha20poly1305.KeySize)
+	defer secmem.Wipe(key)
+	return chacha20poly1305.NewX(key)
+}
@@ -0,0 +1,241 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"reflect"
+	"testing"
+)
+
+func TestKey_MarshalBinary(t *testing.T) {
+	for _, enc := range []ParamsEncoding{Level0, Consensus, WOTSP_SHA2_256} {
+		params := DecodeParams(enc)
+		key := NewKey(params, rand.Reader)
+		msg := getRandData(t, 256)
+
+		// Test key without chains
+		data, err := key.MarshalBinary()
+
+		if err != nil {
+			t.Fatalf("Key.MarshalBinary() returned error: %s", err)
+		}
+
+		if len(data) != 2+1+2*SeedSize {
+			t.Fatalf("Key.MarshalBinary() returned data with wrong size for key without chains. Got %d", len(data))
+		}
+
+		loaded := new(Key)
+		err = loaded.UnmarshalBinary(data)
+
+		if err != nil {
+			t.Fatalf("Key.UnmarshalBinary() returned error: %s", err)
+		}
+
+		if loaded.generated {
+			t.Fatalf("Key.UnmarshalBinary() loaded chains for key without chains")
+		}
+
+		if !bytes.Equal(loaded.Sign(msg), key.Sign(msg)) {
+			t.Fatalf("Key.UnmarshalBinary() loaded a different key for params %s", params)
+		}
+
+		// Test key with chains
+		key.Generate()
+		data, err = key.MarshalBinary()
+
+		if err != nil {
+			t.Fatalf("Key.MarshalBinary() returned error: %s", err)
+		}
+
+		loaded = new(Key)
+		err = loaded.UnmarshalBinary(data)
+
+		if err != nil {
+			t.Fatalf("Key.UnmarshalBinary() returned error: %s", err)
+		}
+
+		if !loaded.generated || !reflect.DeepEqual(loaded.chains, key.chains) {
+			t.Fatalf("Key.UnmarshalBinary() didn't load the chains for params %s", params)
+		}
+
+		if !bytes.Equal(loaded.GetPK(), key.GetPK()) {
+			t.Fatalf("Key.UnmarshalBinary() loaded a different PK for params %s", params)
+		}
+
+		if !bytes.Equal(loaded.Sign(msg), key.Sign(msg)) {
+			t.Fatalf("Key.UnmarshalBinary() loaded a different key for params %s", params)
+		}
+
+		// Test encrypted key can't be loaded without passphrase
+		err = loaded.UnmarshalBinaryWithPassphrase(data, "passphrase")
+
+		if err == nil {
+			t.Fatalf("Key.UnmarshalBinaryWithPassphrase() should return error when key is not encrypted")
+		}
+	}
+}
+
+func TestKey_UnmarshalBinary_Errors(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	key.Generate()
+	data, _ := key.MarshalBinary()
+	loaded := new(Key)
+
+	// Test empty data
+	if loaded.UnmarshalBinary(nil) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when data is empty")
+	}
+
+	// Test wrong version
+	wrong := append([]byte{}, data...)
+	wrong[0] = keyVersion + 1
+
+	if loaded.UnmarshalBinary(wrong) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when version is unknown")
+	}
+
+	// Test wrong params
+	wrong = append([]byte{}, data...)
+	wrong[keyHeaderSize] = byte(ParamsEncodingLen)
+
+	if loaded.UnmarshalBinary(wrong) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when params encoding is unknown")
+	}
+
+	// Test wrong size
+	if loaded.UnmarshalBinary(data[:len(data)-1]) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when chains are incomplete")
+	}
+
+	if loaded.UnmarshalBinary(data[:keyHeaderSize+1+SeedSize]) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when seeds are incomplete")
+	}
+
+	// Test key with params without encoding
+	key = NewKey(NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256), rand.Reader)
+
+	if _, err := key.MarshalBinary(); err == nil {
+		t.Fatalf("Key.MarshalBinary() should return error when params don't have an encoding")
+	}
+}
+
+func TestKey_MarshalBinaryWithPassphrase(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	key.Generate()
+	msg := getRandData(t, 256)
+
+	// Test empty passphrase
+	if _, err := key.MarshalBinaryWithPassphrase(""); err == nil {
+		t.Fatalf("Key.MarshalBinaryWithPassphrase() should return error when passphrase is empty")
+	}
+
+	data, err := key.MarshalBinaryWithPassphrase("passphrase")
+
+	if err != nil {
+		t.Fatalf("Key.MarshalBinaryWithPassphrase() returned error: %s", err)
+	}
+
+	// Test seed is not in plaintext
+	if bytes.Contains(data, key.seed) {
+		t.Fatalf("Key.MarshalBinaryWithPassphrase() output contains the secret seed")
+	}
+
+	loaded := new(Key)
+
+	// Test encrypted key can't be loaded without passphrase
+	if loaded.UnmarshalBinary(data) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when key is encrypted")
+	}
+
+	// Test wrong passphrase
+	if loaded.UnmarshalBinaryWithPassphrase(data, "wrong") == nil {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() should return error when passphrase is wrong")
+	}
+
+	// Test tampered header
+	wrong := append([]byte{}, data...)
+	wrong[1] &^= keyFlagChains
+
+	if loaded.UnmarshalBinaryWithPassphrase(wrong, "passphrase") == nil {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() should return error when header is modified")
+	}
+
+	// Test tampered payload
+	wrong = append([]byte{}, data...)
+	wrong[len(wrong)-1] ^= 1
+
+	if loaded.UnmarshalBinaryWithPassphrase(wrong, "passphrase") == nil {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() should return error when payload is modified")
+	}
+
+	// Test correct passphrase
+	err = loaded.UnmarshalBinaryWithPassphrase(data, "passphrase")
+
+	if err != nil {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() returned error: %s", err)
+	}
+
+	if !loaded.generated || !bytes.Equal(loaded.GetPK(), key.GetPK()) {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() didn't load the chains")
+	}
+
+	if !bytes.Equal(loaded.Sign(msg), key.Sign(msg)) {
+		t.Fatalf("Key.UnmarshalBinaryWithPassphrase() loaded a different key")
+	}
+}
+
+func TestKey_UnmarshalBinary_Tampered(t *testing.T) {
+	for _, interval := range []int{1, 16} {
+		key := NewKey(level0Params, rand.Reader)
+		_ = key.SetCheckpointInterval(interval)
+		key.Generate()
+		data, _ := key.MarshalBinary()
+		pkStart := keyHeaderSize + 1 + 2*SeedSize + 2
+		loaded := new(Key)
+
+		// Test tampered public key, secret keys and last stored level
+		for _, pos := range []int{pkStart, pkStart + PKSize, len(data) - 1} {
+			wrong := append([]byte{}, data...)
+			wrong[pos] ^= 1
+
+			if err := loaded.UnmarshalBinary(wrong); err != errKeyChainsMismatch {
+				t.Fatalf("Key.UnmarshalBinary() should return errKeyChainsMismatch when byte %d is modified "+
+					"with interval %d. Got: %v", pos, interval, err)
+			}
+		}
+
+		if loaded.generated || loaded.seed != nil {
+			t.Fatalf("Key.UnmarshalBinary() shouldn't change the key when the chains don't match")
+		}
+	}
+
+	// Test unknown flags
+	key := NewKey(level0Params, rand.Reader)
+	data, _ := key.MarshalBinary()
+	wrong := append([]byte{}, data...)
+	wrong[1] |= 1 << 7
+
+	if new(Key).UnmarshalBinary(wrong) == nil {
+		t.Fatalf("Key.UnmarshalBinary() should return error when flags are unknown")
+	}
+
+	// Test previous secrets are wiped when a key is overwritten
+	other := NewKey(level0Params, rand.Reader)
+	other.Generate()
+	seed, chains := other.seed, other.chains
+
+	if err := other.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Key.UnmarshalBinary() returned error: %s", err)
+	}
+
+	if !bytes.Equal(seed, make([]byte, SeedSize)) || !bytes.Equal(chains[0], make([]byte, len(chains[0]))) {
+		t.Fatalf("Key.UnmarshalBinary() should wipe the previous secrets of the key")
+	}
+}
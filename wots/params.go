@@ -11,11 +11,12 @@ import (
 	"errors"
 	"fmt"
 	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/internal/secmem"
 )
 
-// WOTS+ implementation with compression parameter of Wbits = 8
+// Default WOTS+ compression parameter of Wbits = 8
 // This means that each byte of the message has one ladder of depth W=2^Wbits=256
-// WARNING: Changing this parameter leads to unexpected results!
+// Params can use W = 4, 16 or 256, see NewParamsW
 const W = 256
 
 // The size of WOTS+ Public Keys if fixed to 32 bytes
@@ -29,25 +30,55 @@ const PKHash = hasher.SHA3_256
 const SeedSize = 32
 
 // Notes about parameter restrictions
-// The number of message ladders is computed as numLadders = m*8/Wbits = m, for Wbits=8
+// The number of message ladders is computed as numLadders = ceil(m*8/Wbits), which is m for Wbits=8
 // The number of checksum ladders is computed as floor(log2((W-1)*numLadders)/Wbits) + 1
-// This basically will be the number of bytes needed to store the maximum checksum value possible of m*(W-1)
-// If m=1, meaning we are signing 1 byte, then the checksum is 1 byte as well
+// This basically will be the number of base W digits needed to store the maximum checksum value possible
+// of numLadders*(W-1)
+// For W=256, if m=1, meaning we are signing 1 byte, then the checksum is 1 byte as well
 // For higher values of m, the checksum fits in 2 bytes, until m=258, where 3 bytes become necessary
-// Since we don't except to ever need to sign a message as large as 258 bytes
-// we can assume that 1 <= m <= 257 and set checksum as 1 or 2 bytes accordingly
-// This way we compute total ladders as:
+// This way we compute total ladders for W=256 as:
 // if m == 1 -> total = m + 1 = 2
 // if m > 1 && m <= 257 -> total = m + 2
 // Furthermore, when using ladder indexes or depths in hash functions, we want to fit the indexes in 1 byte
-// for efficiency. Since W is hardcoded to 256, possible depths are always 0 to 255, fully covering the
-// range of 1 byte values. On another hand, this means that the total number of ladders is limited to 256 as
-// well, so that the ladder index also ranges between 0 and 255
-// This imposes an extra restriction on the size m, since total <= 256, which is equivalent to m <= 254
+// for efficiency. Since W is at most 256, possible depths are always between 0 and 255, fitting in 1 byte
+// Ladder indexes are encoded in 1 byte when the total number of ladders is at most 256, which is always
+// the case for W=256, since m <= 254. For smaller W, if total > 256, ladder indexes are encoded in 2 bytes
+// The maximum message size is kept at 254 bytes for all values of W
 const MaxMsgSize = 254
 
+// WOTS+ constructions //
+// The construction defines how secret keys, ladders and public keys are computed
+type Construction uint8
+
+const (
+	// xx network WOTS+ construction, described in the Sleeve paper
+	ConstructionXX Construction = iota
+	// RFC 8391 WOTS+ construction, with ADRS addressing and L-tree public key compression
+	ConstructionRFC8391
+	// xx network WOTS+ construction using the native keyed mode of the PRF hash function, see keyed.go
+	ConstructionKeyed
+)
+
+// Returns the string representation of the construction
+func (c Construction) String() string {
+	switch c {
+	case ConstructionXX:
+		return "XX"
+	case ConstructionRFC8391:
+		return "RFC8391"
+	case ConstructionKeyed:
+		return "KEYED"
+	default:
+		return "UNKNOWN CONSTRUCTION"
+	}
+}
+
 // WOTS+ parameters //
 type Params struct {
+	// The construction of the ladders and public key
+	construction Construction
+	// The Winternitz parameter, i.e., the depth of the ladders
+	w int
 	// The size of the secret keys and ladder points
 	n int
 	// The size of the message to be signed (after being hashed)
@@ -58,6 +89,19 @@ type Params struct {
 	msgHash hasher.Hasher
 	// The total number of ladders
 	total int
+	// Number of bits per ladder, log2(w)
+	logW int
+	// The number of message ladders
+	len1 int
+	// Flag to tell if messages are hashed with a randomizer, see randomized.go
+	randomized bool
+	// The target sum of the message digits, 0 if checksum ladders are used, see targetsum.go
+	targetSum int
+	// Maximum number of goroutines used to compute ladders
+	// Doesn't affect the output, so it's not part of the params encoding
+	workers int
+	// Encoding set by RegisterParams, accessed atomically, see registry.go
+	encoding uint64
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -72,61 +116,180 @@ var (
 // Constructor
 
 // Creates WOTS+ params with given values of n, m; prf and msg hashes
+// Uses the default W = 256
 func NewParams(n, m int, prf, msg hasher.Hasher) *Params {
+	return NewParamsW(n, m, W, prf, msg)
+}
+
+// Creates WOTS+ params with given values of n, m, w; prf and msg hashes
+// W must be one of 4, 16 or 256
+// Smaller values of W produce larger signatures, but signing and verifying is faster
+func NewParamsW(n, m, w int, prf, msg hasher.Hasher) *Params {
 	// Don't allow creation of params if m == 0 or m > MaxMsgSize
 	if m < 1 || m > MaxMsgSize {
 		return nil
 	}
 	// Don't allow creation of params if hash functions sizes are smaller than specified N and M
-	if prf.Size() < n || msg.Size() < m {
+	// XOFs output exactly N and M bytes
+	if !canOutput(prf, n) || !canOutput(msg, m) {
 		return nil
 	}
-	checksumLadders := 2
-	// The only case that we need just 1 checksum ladder is for m=1
-	if m == 1 {
-		checksumLadders = 1
+	// Get bits per ladder
+	logW := 0
+	switch w {
+	case 4:
+		logW = 2
+	case 16:
+		logW = 4
+	case 256:
+		logW = 8
+	default:
+		return nil
+	}
+	// Message ladders: ceil(8m / logW)
+	len1 := (8*m + logW - 1) / logW
+	// Checksum ladders: floor(log2(len1 * (w-1)) / logW) + 1
+	// i.e., the number of base w digits of the maximum checksum
+	len2 := 1
+	for max := len1 * (w - 1); max >= w; max /= w {
+		len2++
 	}
 	return &Params{
-		n:       n,
-		m:       m,
-		prfHash: prf,
-		msgHash: msg,
-		total:   m + checksumLadders,
+		construction: ConstructionXX,
+		w:            w,
+		n:            n,
+		m:            m,
+		prfHash:      prf,
+		msgHash:      msg,
+		total:        len1 + len2,
+		logW:         logW,
+		len1:         len1,
 	}
 }
 
 ///////////////////////////////////////////////////////////////////////
 // Stringer interface
 func (p *Params) String() string {
-	return fmt.Sprintf("N: %d, M: %d, PRF: %s, MSG: %s", p.n, p.m, p.prfHash, p.msgHash)
+	str := fmt.Sprintf("N: %d, M: %d, PRF: %s, MSG: %s", p.n, p.m, p.prfHash, p.msgHash)
+	if p.construction != ConstructionXX {
+		str += fmt.Sprintf(", %s", p.construction)
+	}
+	if p.w != W {
+		str += fmt.Sprintf(", W: %d", p.w)
+	}
+	if p.targetSum != 0 {
+		str += fmt.Sprintf(", TARGET SUM: %d", p.targetSum)
+	}
+	if p.randomized {
+		str += ", RANDOMIZED"
+	}
+	return str
 }
 
 ///////////////////////////////////////////////////////////////////////
 // Comparison
+// The number of workers is not compared, since it doesn't change the output
 func (p *Params) Equal(other *Params) bool {
-	return p.n == other.n && p.m == other.m && p.prfHash == other.prfHash && p.msgHash == other.msgHash
+	return p.construction == other.construction && p.w == other.w &&
+		p.n == other.n && p.m == other.m && p.prfHash == other.prfHash && p.msgHash == other.msgHash &&
+		p.randomized == other.randomized && p.targetSum == other.targetSum
 }
 
 ///////////////////////////////////////////////////////////////////////
 // Decode a signature, i.e., compute the public key from the message and signature
 func (p *Params) Decode(out, msg, signature []byte) ([]byte, error) {
+	return p.decode(out, msg, signature, nil)
+}
+
+// Decode a signature, appending the public key to out
+// Unlike Decode, out can have any length and capacity
+// If out has enough capacity, no memory is allocated
+func (p *Params) DecodeTo(out, msg, signature []byte) ([]byte, error) {
 	// Ensure signature has correct size
-	siglen := p.total*p.n + SeedSize
-	if len(signature) != siglen {
+	if len(signature) != p.sigSize() {
 		return nil, errWrongSigLen
 	}
+	return p.decodeTo(out, msg, signature, nil)
+}
+
+// Decode a signature, using the given random elements for the public seed
+// If rands is nil, they are computed from the public seed
+func (p *Params) decode(out, msg, signature []byte, rands [][]byte) ([]byte, error) {
+	if err := p.checkDecode(out, signature); err != nil {
+		return nil, err
+	}
+
+	// Compute the public key from message and signature
+	return p.decodeTo(out, msg, signature, rands)
+}
+
+// Check the signature size and output slice of Decode
+func (p *Params) checkDecode(out, signature []byte) error {
+	// Ensure signature has correct size
+	if len(signature) != p.sigSize() {
+		return errWrongSigLen
+	}
 
 	// Ensure output slice is well formed
 	if len(out) != 0 || cap(out) != PKSize {
-		return nil, errInvalidOutputSlice
+		return errInvalidOutputSlice
 	}
+	return nil
+}
 
-	// Get public seed from first 32 bytes of the signature
+// Compute the public key from message and signature, appending it to out
+// The signature must have the correct size
+func (p *Params) decodeTo(out, msg, signature []byte, rands [][]byte) ([]byte, error) {
+	pSeed, r, counter, points := p.splitSignature(signature)
+	s := getScratch()
+	defer putScratch(s)
+	return p.decodePoints(out, pSeed, p.hashMsg(s, r, pSeed, msg), counter, points, rands)
+}
+
+// Compute the public key from message digest and signature, appending it to out
+// The signature must have the correct size
+func (p *Params) decodeDigestTo(out, digest, signature []byte) ([]byte, error) {
+	pSeed, _, counter, points := p.splitSignature(signature)
+	return p.decodePoints(out, pSeed, digest, counter, points, nil)
+}
+
+// Compute the public key from message digest and ladder points, appending it to out
+// For target sum params, the digest is hashed with the counter first, and
+// must reach the target sum, see targetsum.go
+func (p *Params) decodePoints(out, pSeed, digest, counter, points []byte, rands [][]byte) ([]byte, error) {
+	if counter != nil {
+		s := getScratch()
+		defer putScratch(s)
+		var ok bool
+		if digest, ok = p.targetDigest(s, digest, counter); !ok {
+			return nil, errTargetSum
+		}
+	}
+	return p.computeLaddersWithRands(out, pSeed, digest, points, nil, false, rands, 1), nil
+}
+
+// Get the size of a signature, without the params encoding
+func (p *Params) sigSize() int {
+	return SeedSize + p.randomizerSize() + p.counterSize() + p.total*p.n
+}
+
+// Split a signature, without the params encoding, into public seed, randomizer, counter and ladder points
+// The randomizer is nil if the params don't use randomized message hashing,
+// and the counter is nil if the params don't use a target sum
+// The signature must have the correct size
+func (p *Params) splitSignature(signature []byte) ([]byte, []byte, []byte, []byte) {
 	pSeed := signature[0:SeedSize]
 	signature = signature[SeedSize:]
-
-	// Compute the public key from message and signature
-	return p.computeLadders(out, pSeed, msg, signature, nil, false), nil
+	var r, counter []byte
+	if p.randomized {
+		r = signature[:p.n]
+		signature = signature[p.n:]
+	}
+	if p.targetSum != 0 {
+		counter = signature[:counterSize]
+		signature = signature[counterSize:]
+	}
+	return pSeed, r, counter, signature
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -136,10 +299,10 @@ func (p *Params) Verify(msg, signature, pubkey []byte) (bool, error) {
 	if len(pubkey) != PKSize {
 		return false, errWrongPubKeySize
 	}
-	// Decode signature
-	pk := make([]byte, 0, PKSize)
-	var err error
-	pk, err = p.Decode(pk, msg, signature)
+	// Decode signature, using scratch memory for the public key
+	s := getScratch()
+	defer putScratch(s)
+	pk, err := p.Decode(s.pk[:0], msg, signature)
 	// Compare public key
 	return bytes.Equal(pk, pubkey), err
 }
@@ -147,128 +310,274 @@ func (p *Params) Verify(msg, signature, pubkey []byte) (bool, error) {
 ///////////////////////////////////////////////////////////////////////
 
 // Hash Message, Compute Checksum and Append it
+// Returns the ladder positions of the signature, one for each ladder
 func (p *Params) msgHashAndComputeChecksum(msg []byte) []byte {
-	// Hash the message
-	hMsg := p.msgHash.New()
-	msgBuffer := make([]byte, 0, hMsg.Size())
-	hashedMsg := make([]byte, p.m)
+	s := getScratch()
+	defer putScratch(s)
+	digits := make([]byte, p.total)
+	copy(digits, p.msgDigits(s, msg))
+	return digits
+}
+
+// Same as msgHashAndComputeChecksum, using the scratch memory
+func (p *Params) msgDigits(s *scratch, msg []byte) []byte {
+	return p.digestDigits(s, p.hashMsg(s, nil, nil, msg))
+}
+
+// Get the ladder positions signed for the m byte message digest into new memory,
+// using the scratch memory
+// For target sum params, the digest is first hashed with the counter, and false
+// is returned if it doesn't reach the target sum
+func (p *Params) digestPositions(s *scratch, digest, counter []byte) ([]byte, bool) {
+	if p.targetSum != 0 {
+		var ok bool
+		if digest, ok = p.targetDigest(s, digest, counter); !ok {
+			return nil, false
+		}
+	}
+	positions := make([]byte, p.total)
+	copy(positions, p.digestDigits(s, digest))
+	return positions, true
+}
+
+// Hash the message into the scratch memory
+// If the randomizer r is not nil, H(r || pSeed || msg) is computed, see randomized.go
+// Returns the m byte message digest
+func (p *Params) hashMsg(s *scratch, r, pSeed, msg []byte) []byte {
+	hMsg := p.msgHash.Get()
+	if r != nil {
+		hMsg.Write(r)
+		hMsg.Write(pSeed)
+	}
 	hMsg.Write(msg)
-	msgBuffer = hMsg.Sum(msgBuffer)
-	copy(hashedMsg[0:p.m], msgBuffer[0:p.m])
-	// Calculate and append checksum
-	return append(hashedMsg, checksum(hashedMsg)...)
+	s.hashed = hasher.SumN(hMsg, growCap(s.hashed, outputSize(hMsg, p.m)), p.m)
+	p.msgHash.Put(hMsg)
+	return s.hashed
+}
+
+// Get the ladder positions for the m byte message digest, using the scratch memory
+func (p *Params) digestDigits(s *scratch, digest []byte) []byte {
+	if p.construction == ConstructionRFC8391 {
+		return p.rfcDigestDigits(s, digest)
+	}
+	// Split digest into base W digits and append checksum digits
+	// For W=256, each byte is a ladder position
+	s.digits = grow(s.digits, p.total)
+	baseW(s.digits[:p.len1], digest, p.logW)
+	checksumW(s.digits[p.len1:], s.digits[:p.len1], p.w)
+	return s.digits
+}
+
+// Get the encoding of a ladder index, used when computing secret keys
+// Indexes are encoded in 1 byte if there are at most 256 ladders, otherwise in 2 bytes
+// The encoding is appended to dst
+func (p *Params) encodeIndex(dst []byte, i int) []byte {
+	if p.total <= 256 {
+		return append(dst, uint8(i))
+	}
+	return append(dst, uint8(i>>8), uint8(i))
 }
 
 // Go down the ladders and calculate PK or signature
 // There are 4 possible scenarios to call this method:
 // 1. ComputePK() - Compute the Public Key without storing any data in memory
-// 2. Generate() - Compute Public Key storing all the ladder points in memory
-// 3. Decode() - Decode a signature starting from the message + Compute Public Key without storing any data in memory
-// 4. Sign() - Signs a message + Returns the Signature without storing any data in memory
-func (p *Params) computeLadders(out, pSeed, msg, points []byte, chains [][]byte, sign bool) []byte {
+// 2. Generate() - Compute Public Key storing the ladder points in memory (see computeLaddersWithRands)
+// 3. Decode() - Decode a signature starting from the message digest + Compute Public Key without storing any data in memory
+// 4. Sign() - Signs a message digest + Returns the Signature without storing any data in memory
+// The public key or the signature ladder points are appended to out
+func (p *Params) computeLadders(out, pSeed, digest, points []byte, chains [][]byte, sign bool) []byte {
+	return p.computeLaddersWithRands(out, pSeed, digest, points, chains, sign, nil, 1)
+}
+
+// Same as computeLadders, using the given random elements for the public seed
+// If rands is nil, they are computed from the public seed
+// The RFC 8391 construction doesn't use random elements, so rands is ignored
+// When generating, only every interval-th level of the ladders is stored, i.e.,
+// chains[l] holds level l*interval, and chains[0] holds the secret keys
+func (p *Params) computeLaddersWithRands(out, pSeed, digest, points []byte, chains [][]byte, sign bool,
+	rands [][]byte, interval int) []byte {
+	s := getScratch()
+	defer putScratch(s)
 
 	// If SIGN() or DECODE()
 	var start []byte
-	if msg != nil {
-		start = p.msgHashAndComputeChecksum(msg)
+	if digest != nil {
+		start = p.digestDigits(s, digest)
 
 		// If GENERATE() or ComputePK()
 	} else {
 		// Set start array with beginning of each ladder (0s when computing)
-		start = make([]byte, p.total)
+		s.digits = grow(s.digits, p.total)
+		for i := range s.digits {
+			s.digits[i] = 0
+		}
+		start = s.digits
 	}
 
-	// Get Hashes
-	// PRF
-	hPrf := p.prfHash.New()
-	// Tweak and Public Key
-	hTweak := PKHash.New()
-
-	// Hash buffer
-	prfBuffer := make([]byte, 0, hPrf.Size())
-
 	// Compute random elements
-	rands := computeRands(p.n, pSeed, hPrf)
-
-	// Chains memory
-	value := make([]byte, p.n)
+	if rands == nil && p.construction != ConstructionRFC8391 {
+		hPrf, seed := p.getSeeded(&s.keyed, pSeed)
+		s.buf = growCap(s.buf, outputSize(hPrf, p.n))
+		rands = s.getRands(p.n, p.w)
+		fillRands(rands, seed, hPrf, s.buf)
+		p.putSeeded(hPrf)
+	}
 
 	// Save output values
+	// If SIGN(), the ladder points are appended to out
 	var outputs []byte
-	if chains != nil {
-		outputs = chains[W-1]
+	saved := false
+	if sign {
+		out, outputs = extend(out, p.total*p.n)
 	} else {
-		outputs = make([]byte, p.n*p.total)
+		outputs, saved = p.ladderOutputs(s, chains, interval)
+	}
+
+	// Ladders are independent, so they can be split across workers
+	s.job = ladderJob{
+		params:   p,
+		pSeed:    pSeed,
+		start:    start,
+		points:   points,
+		outputs:  outputs,
+		chains:   chains,
+		rands:    rands,
+		sign:     sign,
+		saved:    saved,
+		interval: interval,
+	}
+	p.forEachLadder(s)
+
+	// If SIGN()
+	if sign {
+		return out
+	}
+
+	// If GENERATE() or DECODE() or ComputePK()
+	return p.hashPK(s, out, outputs, pSeed)
+}
+
+// Compute the public key from the last level of the ladders, appending it to out
+func (p *Params) hashPK(s *scratch, out, outputs, pSeed []byte) []byte {
+	if p.construction == ConstructionRFC8391 {
+		return p.rfcLTree(s, out, outputs, pSeed)
 	}
 
+	// Get Tweak and Public Key Hash
+	hTweak := PKHash.Get()
+	defer PKHash.Put(hTweak)
+
+	// Calculate tweak
+	for i := 0; i < p.total; i++ {
+		value := outputs[i*p.n : (i+1)*p.n]
+		if parity(value) {
+			hTweak.Write(value)
+		}
+	}
+	s.buf = hasher.SumTo(hTweak, growCap(s.buf, hTweak.Size()))
+	tweak := s.buf
+
+	// H(PSeed || T || pk1...pk)
+	// pk1...pk are placed in outputs slice
+	hTweak.Reset()
+	hTweak.Write(pSeed)
+	hTweak.Write(tweak)
+	hTweak.Write(outputs)
+
+	// Compute PK by performing the hash sum
+	return hasher.SumTo(hTweak, out)
+}
+
+// Walk ladders [from, to) of the job, see computeLaddersWithRands
+func (p *Params) walkLadders(j *ladderJob, w *ladderWorker, from, to int) {
+	// Get PRF Hash
+	hPrf, seed := p.getSeeded(&w.keyed, j.pSeed)
+	defer p.putSeeded(hPrf)
+
+	// Hash buffer
+	w.buf = growCap(w.buf, outputSize(hPrf, p.n))
+	prfBuffer := w.buf
+
+	// Chains memory
+	w.value = grow(w.value, p.n)
+	value := w.value
+
 	// index
 	begin := uint8(0)
 	end := uint8(0)
-	for i := 0; i < p.total; i++ {
+	for i := from; i < to; i++ {
 
 		// Initialize value with the relevant ladder from the signature OR Secret Keys
-		copy(value, points[i*p.n:(i+1)*p.n])
+		copy(value, j.points[i*p.n:(i+1)*p.n])
 
 		// If SIGN()
-		if sign {
+		if j.sign {
 			begin = 0
-			end = start[i]
+			end = j.start[i]
 		} else {
-			begin = start[i]
-			end = W - 1
+			begin = j.start[i]
+			end = uint8(p.w - 1)
 		}
 
 		// Go down the ladder
-		for j := begin; j < end; j++ {
+		for l := begin; l < end; l++ {
 
 			// Perform masking of the value by XORing it with the correct random element
 			for z, val := range value {
-				value[z] = rands[j][z] ^ val
+				value[z] = j.rands[l][z] ^ val
 			}
 
-			// Chain the value. value = H(PKSEED || j || masked value)
-			prfBuffer = chain(prfBuffer, hPrf, pSeed, j+1, value)
+			// Chain the value. value = H(PKSEED || l || masked value)
+			prfBuffer = chain(prfBuffer, hPrf, seed, l+1, value)
 			copy(value, prfBuffer[0:p.n])
 			prfBuffer = prfBuffer[:0]
 
 			// If GENERATE()
-			if chains != nil {
+			if j.chains != nil && (int(l)+1)%j.interval == 0 {
 				// Save in memory for all ladders
-				copy(chains[int(j)+1][i*p.n:(i+1)*p.n], value)
+				copy(j.chains[(int(l)+1)/j.interval][i*p.n:(i+1)*p.n], value)
 			}
 		}
 
-		// If chains passed as nil copy values to outputs
-		if chains == nil {
-			copy(outputs[i*p.n:(i+1)*p.n], value)
+		// If outputs were not saved in chains copy values to outputs
+		if !j.saved {
+			copy(j.outputs[i*p.n:(i+1)*p.n], value)
 		}
-
-		// If GENERATE() or DECODE() or ComputePK()
-		if !sign {
-			// Calculate tweak
-			if parity(value) {
-				hTweak.Write(value)
-			}
-		}
-
 	}
 
-	// If GENERATE() or DECODE() or ComputePK()
-	if !sign {
-		// Tweak
-		tweak := hTweak.Sum(nil)
-
-		// H(PSeed || T || pk1...pk)
-		// pk1...pk are placed in outputs slice
-		hTweak.Reset()
-		hTweak.Write(pSeed)
-		hTweak.Write(tweak)
-		hTweak.Write(outputs)
+	// Wipe intermediate ladder values
+	secmem.Wipe(value)
+	secmem.Wipe(prfBuffer[:cap(prfBuffer)])
+}
 
-		// Compute PK by performing the hash sum
-		return hTweak.Sum(out)
+// Get the memory for the last level of the ladders
+// If the last level is stored in chains, use it and return true
+func (p *Params) ladderOutputs(s *scratch, chains [][]byte, interval int) ([]byte, bool) {
+	if chains != nil && (p.w-1)%interval == 0 {
+		return chains[(p.w-1)/interval], true
 	}
+	s.outputs = grow(s.outputs, p.n*p.total)
+	return s.outputs, false
+}
 
-	// If SIGN()
-	return outputs
+// Walk ladder i from level begin to level end, updating value in place
+// Used to sign from the closest stored level of the ladder
+func (p *Params) walkLadder(w *ladderWorker, value, pSeed []byte, i, begin, end int, rands [][]byte) {
+	if p.construction == ConstructionRFC8391 {
+		p.rfcWalkLadder(w, value, pSeed, i, begin, end)
+		return
+	}
+	hPrf, seed := p.getSeeded(&w.keyed, pSeed)
+	defer p.putSeeded(hPrf)
+	w.buf = growCap(w.buf, outputSize(hPrf, p.n))
+	prfBuffer := w.buf
+	for j := begin; j < end; j++ {
+		for z, val := range value {
+			value[z] = rands[j][z] ^ val
+		}
+		prfBuffer = chain(prfBuffer, hPrf, seed, uint8(j+1), value)
+		copy(value, prfBuffer[0:p.n])
+		prfBuffer = prfBuffer[:0]
+	}
+	secmem.Wipe(prfBuffer[:cap(prfBuffer)])
 }
@@ -7,6 +7,7 @@
 package wots
 
 import (
+	"bytes"
 	"crypto/rand"
 	"github.com/xx-labs/sleeve/hasher"
 	"testing"
@@ -70,6 +71,79 @@ func TestParams_NewParams(t *testing.T) {
 	}
 }
 
+func TestParams_NewParams_XOF(t *testing.T) {
+	// XOFs output n and m bytes larger than their default size
+	params := NewParamsW(48, 96, 16, hasher.SHAKE128, hasher.SHAKE256)
+
+	if params == nil {
+		t.Fatalf("NewParamsW() should accept XOFs for any n and m")
+	}
+
+	msg := getRandData(t, 256)
+	s := getScratch()
+	digest := params.hashMsg(s, nil, nil, msg)
+	if !bytes.Equal(digest, hasher.SHAKE256.HashN(msg, 96)) {
+		t.Fatalf("hashMsg() should read m bytes of output from the XOF")
+	}
+	if !bytes.Equal(params.HashMessage(msg), digest) {
+		t.Fatalf("HashMessage() should return the m byte digest for XOF params")
+	}
+	putScratch(s)
+
+	key := NewKeyFromSeed(params, getRandData(t, SeedSize), getRandData(t, SeedSize))
+	sig := key.Sign(msg)
+	if ok, err := params.Verify(msg, sig[1:], key.ComputePK()); !ok || err != nil {
+		t.Fatalf("Verify() should return true for valid signature with XOF params: %v", err)
+	}
+	if len(sig[1:]) != SeedSize+params.total*48 {
+		t.Fatalf("Sign() returned signature with wrong size for XOF params: %d", len(sig))
+	}
+}
+
+func TestParams_NewParamsW(t *testing.T) {
+	// Test invalid values of w
+	for _, w := range []int{0, 2, 8, 32, 255, 512} {
+		params := NewParamsW(32, 32, w, hasher.BLAKE3_256, hasher.BLAKE3_256)
+
+		if params != nil {
+			t.Fatalf("NewParamsW() should return nil if w is %d", w)
+		}
+	}
+
+	// Test total number of ladders for each w
+	tests := []struct {
+		m, w, total int
+	}{
+		{1, 256, 2},
+		{32, 256, 34},
+		{32, 16, 67},
+		{24, 16, 51},
+		{32, 4, 133},
+		{24, 4, 101},
+		{64, 16, 131},
+		{64, 4, 261},
+	}
+
+	for _, tt := range tests {
+		params := NewParamsW(32, tt.m, tt.w, hasher.BLAKE3_256, hasher.BLAKE2B_512)
+
+		if params == nil {
+			t.Fatalf("NewParamsW() returned nil for m = %d and w = %d", tt.m, tt.w)
+		}
+
+		if params.total != tt.total {
+			t.Fatalf("NewParamsW() computed wrong number of ladders for m = %d and w = %d. Got %d, expected %d",
+				tt.m, tt.w, params.total, tt.total)
+		}
+	}
+
+	// Test NewParams uses default W
+	if !NewParams(32, 32, hasher.BLAKE3_256, hasher.BLAKE3_256).Equal(
+		NewParamsW(32, 32, W, hasher.BLAKE3_256, hasher.BLAKE3_256)) {
+		t.Fatalf("NewParams() should be the same as NewParamsW() with w = %d", W)
+	}
+}
+
 func TestParams_String(t *testing.T) {
 	params := NewParams(32, 32, hasher.BLAKE3_256, hasher.BLAKE3_256)
 
@@ -80,6 +154,16 @@ func TestParams_String(t *testing.T) {
 	if str != expected {
 		t.Errorf("Params.String() returned invalid string! Expected %s, got %s", expected, str)
 	}
+
+	params = NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256)
+
+	expected = "N: 32, M: 32, PRF: BLAKE3_256, MSG: BLAKE3_256, W: 16"
+
+	str = params.String()
+
+	if str != expected {
+		t.Errorf("Params.String() returned invalid string! Expected %s, got %s", expected, str)
+	}
 }
 
 func TestParams_Equal(t *testing.T) {
@@ -110,6 +194,12 @@ func TestParams_Equal(t *testing.T) {
 		t.Fatalf("Params can't be equal when MSG hash is different")
 	}
 
+	// Different W
+	other = NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256)
+	if params.Equal(other) {
+		t.Fatalf("Params can't be equal when w is different")
+	}
+
 	// Equal params
 	other = NewParams(32, 32, hasher.BLAKE3_256, hasher.BLAKE3_256)
 	if !params.Equal(other) {
@@ -0,0 +1,95 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"errors"
+	"github.com/xx-labs/sleeve/hasher"
+)
+
+///////////////////////////////////////////////////////////////////////
+// RANDOMIZED MESSAGE HASHING
+/*
+	By default, the signed digest is MSG(msg)[:m], so an attacker who
+	finds two messages with the same digest, and gets one of them signed,
+	can forge a signature of the other. The security of the message hash
+	is then bounded by collision resistance, 8m/2 bits classically, which
+	is why the parameter sets rely on signed messages being structured
+
+	With randomized message hashing, each signature carries an n byte
+	randomizer r, and the digest is computed as:
+	  digest = MSG(r || PSEED || msg)[:m]
+	Since r is only known once the message is chosen, finding a
+	collision beforehand doesn't help, and the security of the message
+	hash is the second preimage resistance of 8m bits, for any message
+
+	The randomizer is derived from the secret seed and the message:
+	  r = PRF(SEED || "WOTS+ randomizer" || msg)[:n]
+	so signing is still deterministic, and doesn't need a random source.
+	The input is always longer than the inputs used to derive the
+	secret keys, so the randomizer is independent of them
+
+	Signatures of randomized params are serialized as:
+	  ParamsEncoding, 1 byte
+	  Public Seed,    32 bytes
+	  Randomizer,     n bytes
+	  Ladder points,  total*n bytes
+
+	Randomized params have their own encodings, so signatures created
+	with the original params still verify. Since the digest depends on
+	r, which is derived from the whole message, messages can't be signed
+	or verified from a digest, see digest.go
+*/
+
+// Domain separation for the randomizer PRF
+var randomizerDomain = []byte("WOTS+ randomizer")
+
+var errRandomizedDigest = errors.New("params with randomized message hashing can't sign or verify digests")
+
+// Get a copy of the params using randomized message hashing
+func (p *Params) WithRandomizedHashing() *Params {
+	cp := *p
+	cp.randomized = true
+	// Not the registered params anymore
+	cp.encoding = 0
+	return &cp
+}
+
+// Get if the params use randomized message hashing
+func (p *Params) RandomizedHashing() bool {
+	return p.randomized
+}
+
+// Get the size of the randomizer in signatures, 0 if the params don't use randomized message hashing
+func (p *Params) randomizerSize() int {
+	if !p.randomized {
+		return 0
+	}
+	return p.n
+}
+
+// Hash the message to be signed by the key into the scratch memory
+// If the params use randomized message hashing, the randomizer is appended to out
+// Returns out and the m byte message digest
+func (k *Key) hashMsg(s *scratch, out, msg []byte) ([]byte, []byte) {
+	if !k.params.randomized {
+		return out, k.params.hashMsg(s, nil, nil, msg)
+	}
+
+	// Compute randomizer
+	hPrf := k.params.prfHash.GetSecret()
+	hPrf.Write(k.seed)
+	hPrf.Write(randomizerDomain)
+	hPrf.Write(msg)
+	s.buf = hasher.SumN(hPrf, growCap(s.buf, outputSize(hPrf, k.params.n)), k.params.n)
+	k.params.prfHash.PutSecret(hPrf)
+	out = append(out, s.buf...)
+
+	// Hash the message with the randomizer
+	r := out[len(out)-k.params.n:]
+	return out, k.params.hashMsg(s, r, k.pSeed, msg)
+}
@@ -0,0 +1,189 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var randomizedEncodings = []ParamsEncoding{Level0Randomized, Level1Randomized, Level2Randomized, Level3Randomized}
+
+func TestParams_WithRandomizedHashing(t *testing.T) {
+	params := level0Params.WithRandomizedHashing()
+
+	if !params.RandomizedHashing() {
+		t.Fatalf("WithRandomizedHashing() should return params with randomized message hashing")
+	}
+
+	if level0Params.RandomizedHashing() {
+		t.Fatalf("WithRandomizedHashing() shouldn't modify the original params")
+	}
+
+	if params.Equal(level0Params) {
+		t.Fatalf("Equal() should return false for params with different message hashing")
+	}
+
+	if !strings.HasSuffix(params.String(), ", RANDOMIZED") {
+		t.Fatalf("String() should show randomized message hashing, got %s", params)
+	}
+
+	if EncodeParams(params) != Level0Randomized {
+		t.Fatalf("EncodeParams() should return %s, got %s", Level0Randomized, EncodeParams(params))
+	}
+}
+
+func TestKey_Sign_Randomized(t *testing.T) {
+	msg := getRandData(t, 256)
+	for _, enc := range randomizedEncodings {
+		params := DecodeParams(enc)
+		seed := getRandData(t, SeedSize)
+		pSeed := getRandData(t, SeedSize)
+		key := NewKeyFromSeed(params, seed, pSeed)
+		pk := key.ComputePK()
+		sig := key.Sign(msg)
+
+		if len(sig) != SignatureSize(enc) || len(sig) != 1+SeedSize+params.n+params.total*params.n {
+			t.Fatalf("Sign() returned signature with wrong size for %s: %d", enc, len(sig))
+		}
+
+		ok, err := Verify(msg, sig, pk)
+		if err != nil || !ok {
+			t.Fatalf("Verify() should return true for valid %s signature: %v", enc, err)
+		}
+
+		// Signing is deterministic, also with generated keys
+		if !bytes.Equal(NewKeyFromSeed(params, seed, pSeed).Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for the same message with %s", enc)
+		}
+		key.Generate()
+		if !bytes.Equal(key.Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for generated key with %s", enc)
+		}
+
+		// The randomizer depends on the message
+		r := sig[1+SeedSize : 1+SeedSize+params.n]
+		other := key.Sign(getRandData(t, 256))
+		if bytes.Equal(other[1+SeedSize:1+SeedSize+params.n], r) {
+			t.Fatalf("Sign() should use a different randomizer for a different message with %s", enc)
+		}
+
+		// Tampering with the randomizer changes the digest
+		sig[1+SeedSize] ^= 0x01
+		if ok, _ := Verify(msg, sig, pk); ok {
+			t.Fatalf("Verify() should return false for tampered randomizer with %s", enc)
+		}
+	}
+}
+
+func TestKey_Sign_RandomizedDigest(t *testing.T) {
+	params := DecodeParams(Level0Randomized)
+	key := NewKey(params, rand.Reader)
+	msg := getRandData(t, 256)
+	sig := key.Sign(msg)
+
+	if key.SignDigest(params.HashMessage(msg)) != nil {
+		t.Fatalf("SignDigest() should return nil for params with randomized message hashing")
+	}
+
+	if _, err := params.VerifyDigest(params.HashMessage(msg), sig[1:], key.ComputePK()); err != errRandomizedDigest {
+		t.Fatalf("VerifyDigest() should return error for params with randomized message hashing")
+	}
+
+	if key.NewSigner() != nil || params.NewVerifier() != nil {
+		t.Fatalf("NewSigner() and NewVerifier() should return nil for params with randomized message hashing")
+	}
+}
+
+func TestKey_Sign_RandomizedCompatibility(t *testing.T) {
+	// The same key signs differently with and without randomized message hashing,
+	// and signatures of the original params still verify
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+	msg := getRandData(t, 256)
+
+	key := NewKeyFromSeed(level1Params, seed, pSeed)
+	rkey := NewKeyFromSeed(level1RandomizedParams, seed, pSeed)
+	sig := key.Sign(msg)
+	rsig := rkey.Sign(msg)
+
+	if !bytes.Equal(key.ComputePK(), rkey.ComputePK()) {
+		t.Fatalf("ComputePK() shouldn't depend on randomized message hashing")
+	}
+
+	if sig[0] != byte(Level1) || rsig[0] != byte(Level1Randomized) {
+		t.Fatalf("Sign() returned wrong params encodings: %d and %d", sig[0], rsig[0])
+	}
+
+	for _, s := range [][]byte{sig, rsig} {
+		ok, err := Verify(msg, s, key.ComputePK())
+		if err != nil || !ok {
+			t.Fatalf("Verify() should return true for signature with params %s: %v", ParamsEncoding(s[0]), err)
+		}
+	}
+
+	// Signatures can't be verified with the other params
+	if _, err := level1Params.Verify(msg, rsig[1:], key.ComputePK()); err != errWrongSigLen {
+		t.Fatalf("Verify() should return error for randomized signature with original params")
+	}
+
+	// Batch verification
+	results := VerifyBatch([]Item{
+		{Msg: msg, Signature: sig, PublicKey: key.ComputePK()},
+		{Msg: msg, Signature: rsig, PublicKey: key.ComputePK()},
+	})
+	for i, res := range results {
+		if res.Err != nil || !res.Valid {
+			t.Fatalf("VerifyBatch() should return true for item %d: %v", i, res.Err)
+		}
+	}
+}
+
+func TestSignature_Randomized(t *testing.T) {
+	key := NewKey(level2RandomizedParams, rand.Reader)
+	msg := getRandData(t, 256)
+	raw := key.Sign(msg)
+
+	sig, err := ParseSignature(raw)
+	if err != nil {
+		t.Fatalf("ParseSignature() returned error for randomized signature: %s", err)
+	}
+
+	if !bytes.Equal(sig.Randomizer(), raw[1+SeedSize:1+SeedSize+level2N]) {
+		t.Fatalf("Randomizer() should return the randomizer of the signature")
+	}
+
+	if !bytes.Equal(sig.Bytes(), raw) {
+		t.Fatalf("Bytes() should return the serialized signature")
+	}
+
+	if ok, err := sig.Verify(msg, key.ComputePK()); err != nil || !ok {
+		t.Fatalf("Signature.Verify() should return true for valid randomized signature: %v", err)
+	}
+
+	// JSON
+	data, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %s", err)
+	}
+	other := new(Signature)
+	if err := json.Unmarshal(data, other); err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("json.Unmarshal() didn't load the randomized signature: %v", err)
+	}
+
+	// Signatures of params without randomizer don't have one
+	plain, _ := ParseSignature(NewKey(level2Params, rand.Reader).Sign(msg))
+	if plain.Randomizer() != nil {
+		t.Fatalf("Randomizer() should return nil for params without randomized message hashing")
+	}
+	if data, _ := json.Marshal(plain); strings.Contains(string(data), "Randomizer") {
+		t.Fatalf("json.Marshal() shouldn't include the randomizer for params without randomized message hashing")
+	}
+}
@@ -0,0 +1,181 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+///////////////////////////////////////////////////////////////////////
+// PARAMS REGISTRY
+/*
+	Parameter sets are identified by their encoding, which is the first
+	byte of every signature. The sets defined in security.go are always
+	registered, and new sets can be added with RegisterParams, e.g., for
+	research or test networks
+
+	Encodings and names must be unique, and the same params can't be
+	registered twice, so that EncodeParams always has a single answer
+	ParamsEncodingLen is reserved to represent unknown params
+
+	EncodeParams is called for every signature, so RegisterParams caches
+	the encoding in the registered *Params, together with the registry
+	generation. Removing params from the registry increments the
+	generation, invalidating the encodings cached in the removed params
+	and in copies of any params. EncodeParams only scans the registry
+	when there's no valid cached encoding, e.g., for equal params created
+	with NewParams
+*/
+
+// Security levels of a parameter set, in bits
+type SecurityLevels struct {
+	Classical   float64
+	PostQuantum float64
+}
+
+// A registered parameter set
+type ParamsInfo struct {
+	Encoding ParamsEncoding
+	Params   *Params
+	Name     string
+	Security SecurityLevels
+}
+
+var registry = struct {
+	sync.RWMutex
+	byEncoding map[ParamsEncoding]*ParamsInfo
+	// Sorted by encoding
+	list []*ParamsInfo
+}{
+	byEncoding: make(map[ParamsEncoding]*ParamsInfo),
+}
+
+// Incremented when params are removed from the registry, accessed atomically
+var registryGeneration uint32
+
+// Register a parameter set with the given encoding, name and security levels
+// Returns an error if the encoding is reserved, or if the encoding, name or
+// params are already registered
+func RegisterParams(enc ParamsEncoding, params *Params, name string, security SecurityLevels) error {
+	// 1. Validate input
+	if params == nil {
+		return errors.New("can't register nil params")
+	}
+	if name == "" {
+		return errors.New("can't register params without a name")
+	}
+	if enc == ParamsEncodingLen {
+		return errors.New(fmt.Sprintf("params encoding %d is reserved for unknown params", enc))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	// 2. Check conflicts
+	if info, ok := registry.byEncoding[enc]; ok {
+		return errors.New(fmt.Sprintf("params encoding %d is already registered as %s", enc, info.Name))
+	}
+	for _, info := range registry.list {
+		if info.Name == name {
+			return errors.New(fmt.Sprintf("params name %s is already registered with encoding %d", name, info.Encoding))
+		}
+		if info.Params.Equal(params) {
+			return errors.New(fmt.Sprintf("params %s are already registered as %s", params, info.Name))
+		}
+	}
+
+	// 3. Register
+	info := &ParamsInfo{
+		Encoding: enc,
+		Params:   params,
+		Name:     name,
+		Security: security,
+	}
+	params.cacheEncoding(enc)
+	registry.byEncoding[enc] = info
+	registry.list = append(registry.list, info)
+	sort.Slice(registry.list, func(i, j int) bool {
+		return registry.list[i].Encoding < registry.list[j].Encoding
+	})
+	return nil
+}
+
+// Cache the encoding of registered params
+// Must be called with the registry lock held
+func (p *Params) cacheEncoding(enc ParamsEncoding) {
+	generation := uint64(atomic.LoadUint32(&registryGeneration))
+	atomic.StoreUint64(&p.encoding, generation<<32|(uint64(enc)+1))
+}
+
+// Get the cached encoding of the params
+// Returns false if the params weren't registered, or were removed from the registry
+func (p *Params) cachedEncoding() (ParamsEncoding, bool) {
+	cached := atomic.LoadUint64(&p.encoding)
+	if cached == 0 || uint32(cached>>32) != atomic.LoadUint32(&registryGeneration) {
+		return ParamsEncodingLen, false
+	}
+	return ParamsEncoding(uint32(cached) - 1), true
+}
+
+// Get all registered parameter sets, sorted by encoding
+func RegisteredParams() []ParamsInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	list := make([]ParamsInfo, len(registry.list))
+	for i, info := range registry.list {
+		list[i] = *info
+	}
+	return list
+}
+
+// Get the registered parameter set with the given encoding
+func LookupParams(enc ParamsEncoding) (ParamsInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.byEncoding[enc]
+	if !ok {
+		return ParamsInfo{}, false
+	}
+	return *info, true
+}
+
+// Get the name of the parameter set, if registered
+func (enc ParamsEncoding) String() string {
+	if info, ok := LookupParams(enc); ok {
+		return info.Name
+	}
+	return fmt.Sprintf("UNKNOWN PARAMS (%d)", uint8(enc))
+}
+
+// Register a built in parameter set, panicking on conflicts
+func mustRegisterParams(enc ParamsEncoding, params *Params, name string, security SecurityLevels) {
+	if err := RegisterParams(enc, params, name, security); err != nil {
+		panic(err)
+	}
+}
+
+// Remove a parameter set from the registry, only used for testing
+func unregisterParams(enc ParamsEncoding) {
+	registry.Lock()
+	defer registry.Unlock()
+	atomic.AddUint32(&registryGeneration, 1)
+	delete(registry.byEncoding, enc)
+	for i, info := range registry.list {
+		if info.Encoding == enc {
+			registry.list = append(registry.list[:i], registry.list[i+1:]...)
+			break
+		}
+	}
+	// Cache the encodings of the remaining params with the new generation
+	for _, info := range registry.list {
+		info.Params.cacheEncoding(info.Encoding)
+	}
+}
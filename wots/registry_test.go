@@ -0,0 +1,167 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"testing"
+)
+
+const testEncoding ParamsEncoding = 0xF0
+
+func TestRegisterParams(t *testing.T) {
+	params := NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256)
+	security := SecurityLevels{Classical: 238.0, PostQuantum: 128}
+
+	// Test invalid registrations
+	if RegisterParams(testEncoding, nil, "Test", security) == nil {
+		t.Fatalf("RegisterParams() should return error when params are nil")
+	}
+
+	if RegisterParams(testEncoding, params, "", security) == nil {
+		t.Fatalf("RegisterParams() should return error when name is empty")
+	}
+
+	if RegisterParams(ParamsEncodingLen, params, "Test", security) == nil {
+		t.Fatalf("RegisterParams() should return error when encoding is reserved")
+	}
+
+	if RegisterParams(Level0, params, "Test", security) == nil {
+		t.Fatalf("RegisterParams() should return error when encoding is already registered")
+	}
+
+	if RegisterParams(testEncoding, params, "Level0", security) == nil {
+		t.Fatalf("RegisterParams() should return error when name is already registered")
+	}
+
+	if RegisterParams(testEncoding, level0Params.WithWorkers(2), "Test", security) == nil {
+		t.Fatalf("RegisterParams() should return error when params are already registered")
+	}
+
+	// Test valid registration
+	err := RegisterParams(testEncoding, params, "Test", security)
+	defer unregisterParams(testEncoding)
+
+	if err != nil {
+		t.Fatalf("RegisterParams() returned error for valid params: %s", err)
+	}
+
+	if RegisterParams(testEncoding+1, params, "Test2", security) == nil {
+		t.Fatalf("RegisterParams() should return error when params are already registered")
+	}
+
+	if DecodeParams(testEncoding) != params {
+		t.Fatalf("DecodeParams() didn't return registered params")
+	}
+
+	if EncodeParams(NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256)) != testEncoding {
+		t.Fatalf("EncodeParams() didn't return registered encoding")
+	}
+
+	if testEncoding.String() != "Test" {
+		t.Fatalf("ParamsEncoding.String() returned wrong name. Got %s, expected Test", testEncoding)
+	}
+
+	info, ok := LookupParams(testEncoding)
+
+	if !ok || info.Name != "Test" || info.Security != security || info.Params != params {
+		t.Fatalf("LookupParams() returned wrong info: %+v", info)
+	}
+
+	// Test signatures with registered params
+	key := NewKey(params, rand.Reader)
+	msg := getRandData(t, 256)
+	sig := key.Sign(msg)
+
+	if ParamsEncoding(sig[0]) != testEncoding {
+		t.Fatalf("Key.Sign() used wrong params encoding. Got %d, expected %d", sig[0], testEncoding)
+	}
+
+	valid, err := Verify(msg, sig, key.ComputePK())
+
+	if err != nil || !valid {
+		t.Fatalf("Verify() should work for registered params: %v", err)
+	}
+
+	if _, err = ParseSignature(sig); err != nil {
+		t.Fatalf("ParseSignature() should work for registered params: %s", err)
+	}
+}
+
+func TestRegisteredParams(t *testing.T) {
+	list := RegisteredParams()
+
+	expected := []ParamsEncoding{Level0, Level1, Level2, Level3, Consensus, WOTSP_SHA2_256,
+		Level0Randomized, Level1Randomized, Level2Randomized, Level3Randomized,
+		Level0TargetSum, Level1TargetSum, Level2TargetSum, Level3TargetSum,
+		Level0Keyed, Level1Keyed, Level2Keyed, Level3Keyed}
+
+	if len(list) != len(expected) {
+		t.Fatalf("RegisteredParams() returned %d sets, expected %d", len(list), len(expected))
+	}
+
+	for i, info := range list {
+		if info.Encoding != expected[i] {
+			t.Fatalf("RegisteredParams() returned wrong encoding at %d. Got %d, expected %d",
+				i, info.Encoding, expected[i])
+		}
+
+		if !info.Params.Equal(DecodeParams(info.Encoding)) {
+			t.Fatalf("RegisteredParams() returned wrong params for %s", info.Name)
+		}
+	}
+
+	if list[0].Security.Classical != 139.30 || list[0].Security.PostQuantum != 80 {
+		t.Fatalf("RegisteredParams() returned wrong security levels for Level0: %+v", list[0].Security)
+	}
+
+	if ParamsEncodingLen.String() != "UNKNOWN PARAMS (5)" {
+		t.Fatalf("ParamsEncoding.String() returned wrong name for unknown params: %s", ParamsEncodingLen)
+	}
+}
+
+func TestEncodeParams_Cached(t *testing.T) {
+	// Registered params and their copies with workers are encoded without the registry lock
+	registry.Lock()
+	enc, encWorkers := EncodeParams(level1Params), EncodeParams(level1Params.WithWorkers(2))
+	registry.Unlock()
+
+	if enc != Level1 || encWorkers != Level1 {
+		t.Fatalf("EncodeParams() returned wrong encoding for registered params. Got %s and %s", enc, encWorkers)
+	}
+
+	if EncodeParams(level1Params.WithRandomizedHashing()) != Level1Randomized {
+		t.Fatalf("EncodeParams() should not use the cached encoding for randomized copies")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		enc = EncodeParams(level1Params)
+	})
+	if allocs != 0 {
+		t.Fatalf("EncodeParams() should not allocate for registered params, got %v allocations", allocs)
+	}
+
+	// Removing params invalidates the cached encodings
+	params := NewParamsW(32, 32, 16, hasher.BLAKE3_256, hasher.BLAKE3_256)
+	if err := RegisterParams(testEncoding, params, "Test", SecurityLevels{}); err != nil {
+		t.Fatalf("RegisterParams() returned error for valid params: %s", err)
+	}
+	withWorkers := params.WithWorkers(2)
+	if EncodeParams(withWorkers) != testEncoding {
+		t.Fatalf("EncodeParams() didn't return registered encoding")
+	}
+
+	unregisterParams(testEncoding)
+
+	if EncodeParams(params) != ParamsEncodingLen || EncodeParams(withWorkers) != ParamsEncodingLen {
+		t.Fatalf("EncodeParams() should return ParamsEncodingLen for removed params")
+	}
+	if EncodeParams(level1Params) != Level1 {
+		t.Fatalf("EncodeParams() should still encode registered params after others are removed")
+	}
+}
@@ -0,0 +1,354 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"encoding/binary"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"hash"
+)
+
+///////////////////////////////////////////////////////////////////////
+// RFC 8391 WOTS+ CONSTRUCTION
+/*
+	WOTS+ as specified in RFC 8391 (XMSS: eXtended Merkle Signature Scheme),
+	Section 3.1, in order to interoperate with other WOTS+ implementations
+
+	The differences to the xx network construction are:
+	  - Winternitz parameter w = 16, so each ladder signs 4 bits
+	  - Keys and bitmasks are computed for each chain step with
+	    PRF(SEED, ADRS), using the 32 byte ADRS addressing scheme
+	  - Hash functions are domain separated by a 32 byte prefix:
+	      F(KEY, M)   = H(toByte(0, 32) || KEY || M)
+	      H(KEY, M)   = H(toByte(1, 32) || KEY || M)
+	      PRF(KEY, M) = H(toByte(3, 32) || KEY || M)
+	  - The public key is compressed into n bytes using the L-tree
+	    of RFC 8391, Section 4.1.5
+
+	The RFC leaves the generation of the secret keys open. Here they are
+	derived from the secret seed as done in the XMSS reference code and
+	NIST SP 800-208:
+	      sk_i = H(toByte(4, 32) || SEED_SK || SEED || ADRS)
+	where ADRS has chain address i and all other fields set to 0
+
+	Messages of arbitrary length are first hashed with the message hash
+	function, and the first n bytes are signed as the RFC message M
+
+	Only n = 32 is supported, since public keys have PKSize bytes
+*/
+
+// Winternitz parameter and its logarithm for RFC 8391
+const (
+	rfcW    = 16
+	rfcLogW = 4
+)
+
+// Domain separation prefixes: toByte(X, 32)
+const (
+	rfcPadF         = 0
+	rfcPadH         = 1
+	rfcPadPRF       = 3
+	rfcPadPRFKeygen = 4
+	rfcPadLen       = 32
+)
+
+// ADRS types
+const (
+	rfcAdrsOTS   = 0
+	rfcAdrsLTree = 1
+)
+
+// Creates RFC 8391 WOTS+ params, using the given hash function
+// for F, H, PRF and message hashing
+// The hash function output size must be PKSize bytes
+func NewParamsRFC8391(h hasher.Hasher) *Params {
+	n := h.Size()
+	if n != PKSize {
+		return nil
+	}
+	// len_1 and len_2 are computed the same way as for the xx network construction
+	p := NewParamsW(n, n, rfcW, h, h)
+	p.construction = ConstructionRFC8391
+	return p
+}
+
+///////////////////////////////////////////////////////////////////////
+// ADRS
+// 32 byte address, composed of 8 big endian words:
+// layer || tree (2 words) || type || 4 words depending on type
+type adrs [32]byte
+
+func (a *adrs) setWord(pos int, val uint32) {
+	binary.BigEndian.PutUint32(a[4*pos:4*(pos+1)], val)
+}
+
+// Set the type, clearing the type specific words
+func (a *adrs) setType(typ uint32) {
+	a.setWord(3, typ)
+	for i := 16; i < len(a); i++ {
+		a[i] = 0
+	}
+}
+
+// OTS address fields
+func (a *adrs) setChain(i uint32)      { a.setWord(5, i) }
+func (a *adrs) setHash(i uint32)       { a.setWord(6, i) }
+func (a *adrs) setKeyAndMask(i uint32) { a.setWord(7, i) }
+
+// L-tree address fields
+func (a *adrs) setTreeHeight(i uint32) { a.setWord(5, i) }
+func (a *adrs) setTreeIndex(i uint32)  { a.setWord(6, i) }
+
+///////////////////////////////////////////////////////////////////////
+// HASH FUNCTIONS
+
+// Domain separation prefix for each function
+var rfcPads = func() [rfcPadPRFKeygen + 1][rfcPadLen]byte {
+	var pads [rfcPadPRFKeygen + 1][rfcPadLen]byte
+	for i := range pads {
+		pads[i][rfcPadLen-1] = byte(i)
+	}
+	return pads
+}()
+
+// Compute H(toByte(pad, 32) || key || msg...)
+// The output is appended to dst, which should have enough capacity
+func rfcHash(dst []byte, h hash.Hash, pad int, key []byte, msg ...[]byte) []byte {
+	h.Reset()
+	h.Write(rfcPads[pad][:])
+	h.Write(key)
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return hasher.SumTo(h, dst)
+}
+
+// Get the ladder positions for the message digest, according to RFC 8391 WOTS_sign
+// The digest is the hash of the message, truncated to n bytes
+func (p *Params) rfcDigestDigits(s *scratch, hashed []byte) []byte {
+	// msg = base_w(M, w, len_1)
+	len1 := p.len1
+	len2 := p.total - len1
+	s.digits = grow(s.digits, p.total)
+	digits := s.digits
+	baseW(digits[:len1], hashed, rfcLogW)
+
+	// Compute checksum
+	csum := uint32(0)
+	for _, d := range digits[:len1] {
+		csum += rfcW - 1 - uint32(d)
+	}
+
+	// csum = csum << ( 8 - ( ( len_2 * lg(w) ) % 8 ) )
+	csum <<= uint(8 - ((len2 * rfcLogW) % 8))
+
+	// msg = msg || base_w(toByte(csum, len_2_bytes), w, len_2)
+	len2Bytes := (len2*rfcLogW + 7) / 8
+	var csumBytes [4]byte
+	binary.BigEndian.PutUint32(csumBytes[:], csum)
+	baseW(digits[len1:], csumBytes[4-len2Bytes:], rfcLogW)
+	return digits
+}
+
+///////////////////////////////////////////////////////////////////////
+// SECRET KEYS
+// Compute sk_i = H(toByte(4, 32) || SEED_SK || SEED || ADRS) into sks
+func (p *Params) rfcComputeSK(s *scratch, sks, seed, pSeed []byte) {
+	h := p.prfHash.GetSecret()
+	defer p.prfHash.PutSecret(h)
+	s.buf = growCap(s.buf, h.Size())
+	buf := s.buf
+
+	// Address in scratch memory, so it does not escape to the heap
+	a := &s.adrs
+	*a = adrs{}
+	a.setType(rfcAdrsOTS)
+	for i := 0; i < p.total; i++ {
+		a.setChain(uint32(i))
+		buf = rfcHash(buf, h, rfcPadPRFKeygen, seed, pSeed, a[:])
+		copy(sks[i*p.n:(i+1)*p.n], buf[0:p.n])
+		buf = buf[:0]
+	}
+	secmem.Wipe(buf[:cap(buf)])
+}
+
+///////////////////////////////////////////////////////////////////////
+// LADDERS
+// Walk ladders [from, to) of the job, following RFC 8391 chain, WOTS_genPK,
+// WOTS_sign and WOTS_pkFromSig
+// See computeLaddersWithRands for the semantics of the job
+func (p *Params) rfcWalkLadders(j *ladderJob, w *ladderWorker, from, to int) {
+	// Hash and buffers
+	h := p.prfHash.Get()
+	defer p.prfHash.Put(h)
+	w.buf = growCap(w.buf, h.Size())
+	w.key = grow(w.key, p.n)
+	w.value = grow(w.value, p.n)
+	buf := w.buf
+	key := w.key
+	value := w.value
+
+	// Address in worker memory, so it does not escape to the heap
+	a := &w.adrs
+	*a = adrs{}
+	a.setType(rfcAdrsOTS)
+	begin := 0
+	end := 0
+	for i := from; i < to; i++ {
+		a.setChain(uint32(i))
+
+		// Initialize value with the relevant ladder from the signature OR Secret Keys
+		copy(value, j.points[i*p.n:(i+1)*p.n])
+
+		// If SIGN()
+		if j.sign {
+			begin = 0
+			end = int(j.start[i])
+		} else {
+			begin = int(j.start[i])
+			end = p.w - 1
+		}
+
+		// Go down the ladder
+		for l := begin; l < end; l++ {
+			a.setHash(uint32(l))
+
+			// KEY = PRF(SEED, ADRS)
+			a.setKeyAndMask(0)
+			buf = rfcHash(buf, h, rfcPadPRF, j.pSeed, a[:])
+			copy(key, buf)
+			buf = buf[:0]
+
+			// BM = PRF(SEED, ADRS)
+			a.setKeyAndMask(1)
+			buf = rfcHash(buf, h, rfcPadPRF, j.pSeed, a[:])
+			for z := range value {
+				value[z] ^= buf[z]
+			}
+			buf = buf[:0]
+
+			// value = F(KEY, value XOR BM)
+			buf = rfcHash(buf, h, rfcPadF, key, value)
+			copy(value, buf[0:p.n])
+			buf = buf[:0]
+
+			// If GENERATE()
+			if j.chains != nil && (l+1)%j.interval == 0 {
+				copy(j.chains[(l+1)/j.interval][i*p.n:(i+1)*p.n], value)
+			}
+		}
+
+		// If outputs were not saved in chains copy values to outputs
+		if !j.saved {
+			copy(j.outputs[i*p.n:(i+1)*p.n], value)
+		}
+	}
+
+	// Wipe intermediate ladder values
+	secmem.Wipe(value)
+	secmem.Wipe(key)
+	secmem.Wipe(buf[:cap(buf)])
+}
+
+// Walk ladder i from level begin to level end, updating value in place
+func (p *Params) rfcWalkLadder(w *ladderWorker, value, pSeed []byte, i, begin, end int) {
+	h := p.prfHash.Get()
+	defer p.prfHash.Put(h)
+	w.buf = growCap(w.buf, h.Size())
+	w.key = grow(w.key, p.n)
+	buf := w.buf
+	key := w.key
+
+	a := &w.adrs
+	*a = adrs{}
+	a.setType(rfcAdrsOTS)
+	a.setChain(uint32(i))
+	for j := begin; j < end; j++ {
+		a.setHash(uint32(j))
+
+		// KEY = PRF(SEED, ADRS)
+		a.setKeyAndMask(0)
+		buf = rfcHash(buf, h, rfcPadPRF, pSeed, a[:])
+		copy(key, buf)
+		buf = buf[:0]
+
+		// BM = PRF(SEED, ADRS)
+		a.setKeyAndMask(1)
+		buf = rfcHash(buf, h, rfcPadPRF, pSeed, a[:])
+		for z := range value {
+			value[z] ^= buf[z]
+		}
+		buf = buf[:0]
+
+		// value = F(KEY, value XOR BM)
+		buf = rfcHash(buf, h, rfcPadF, key, value)
+		copy(value, buf[0:p.n])
+		buf = buf[:0]
+	}
+	secmem.Wipe(key)
+	secmem.Wipe(buf[:cap(buf)])
+}
+
+// Compress the public key into n bytes, RFC 8391 Algorithm 8
+// The compressed public key is appended to out
+func (p *Params) rfcLTree(s *scratch, out, outputs, pSeed []byte) []byte {
+	// Copy the public key, since it's modified in place
+	s.ltree = grow(s.ltree, len(outputs))
+	pk := s.ltree
+	copy(pk, outputs)
+
+	h := p.prfHash.Get()
+	defer p.prfHash.Put(h)
+	// BM_0 and BM_1 are computed into the buffer one after the other
+	s.buf = growCap(s.buf, 2*h.Size())
+	s.key = grow(s.key, p.n)
+	s.bm = grow(s.bm, 2*p.n)
+	buf := s.buf
+	key := s.key
+	bm := s.bm
+
+	a := &s.adrs
+	*a = adrs{}
+	a.setType(rfcAdrsLTree)
+	a.setTreeHeight(0)
+	height := uint32(0)
+	for l := p.total; l > 1; l = (l + 1) / 2 {
+		for i := 0; i < l/2; i++ {
+			a.setTreeIndex(uint32(i))
+
+			// KEY = PRF(SEED, ADRS)
+			a.setKeyAndMask(0)
+			buf = rfcHash(buf, h, rfcPadPRF, pSeed, a[:])
+			copy(key, buf)
+			buf = buf[:0]
+
+			// BM_0 = PRF(SEED, ADRS), BM_1 = PRF(SEED, ADRS)
+			a.setKeyAndMask(1)
+			buf = rfcHash(buf, h, rfcPadPRF, pSeed, a[:])
+			a.setKeyAndMask(2)
+			buf = rfcHash(buf, h, rfcPadPRF, pSeed, a[:])
+			copy(bm, buf)
+			buf = buf[:0]
+
+			// pk[i] = H(KEY, (pk[2i] XOR BM_0) || (pk[2i+1] XOR BM_1))
+			for z := range bm {
+				bm[z] ^= pk[2*i*p.n+z]
+			}
+			buf = rfcHash(buf, h, rfcPadH, key, bm)
+			copy(pk[i*p.n:(i+1)*p.n], buf)
+			buf = buf[:0]
+		}
+		// Odd node is moved up
+		if l%2 == 1 {
+			copy(pk[(l/2)*p.n:(l/2+1)*p.n], pk[(l-1)*p.n:l*p.n])
+		}
+		height++
+		a.setTreeHeight(height)
+	}
+	return append(out, pk[0:p.n]...)
+}
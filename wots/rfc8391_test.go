@@ -0,0 +1,166 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/xx-labs/sleeve/hasher"
+	"testing"
+)
+
+func TestNewParamsRFC8391(t *testing.T) {
+	// Test hash with wrong size
+	params := NewParamsRFC8391(hasher.SHA3_224)
+
+	if params != nil {
+		t.Fatalf("NewParamsRFC8391() should return nil if hash size is not %d bytes", PKSize)
+	}
+
+	// Test WOTSP-SHA2_256: len_1 = 64, len_2 = 3
+	params = NewParamsRFC8391(hasher.SHA2_256)
+
+	if params.n != 32 || params.m != 32 || params.w != 16 || params.total != 67 {
+		t.Fatalf("NewParamsRFC8391() returned wrong params: %s, total %d", params, params.total)
+	}
+
+	expected := "N: 32, M: 32, PRF: SHA2_256, MSG: SHA2_256, RFC8391, W: 16"
+	if params.String() != expected {
+		t.Fatalf("Params.String() returned invalid string! Expected %s, got %s", expected, params.String())
+	}
+
+	// Test RFC params are not equal to xx network params with same hashes
+	if params.Equal(NewParams(32, 32, hasher.SHA2_256, hasher.SHA2_256)) {
+		t.Fatalf("RFC 8391 params can't be equal to xx network params")
+	}
+
+	// Test encoding
+	if EncodeParams(params) != WOTSP_SHA2_256 || !DecodeParams(WOTSP_SHA2_256).Equal(params) {
+		t.Fatalf("WOTSP_SHA2_256 params encoding is broken")
+	}
+}
+
+func TestBaseW(t *testing.T) {
+	// Example from RFC 8391, Section 2.6
+	out := make([]byte, 4)
+	baseW(out, []byte{0x12, 0x34}, rfcLogW)
+
+	if !bytes.Equal(out, []byte{1, 2, 3, 4}) {
+		t.Fatalf("baseW() returned wrong values. Got %v, expected [1 2 3 4]", out)
+	}
+
+	out = make([]byte, 3)
+	baseW(out, []byte{0x12, 0x34}, rfcLogW)
+
+	if !bytes.Equal(out, []byte{1, 2, 3}) {
+		t.Fatalf("baseW() returned wrong values. Got %v, expected [1 2 3]", out)
+	}
+}
+
+func TestAdrs(t *testing.T) {
+	var a adrs
+	a.setType(rfcAdrsOTS)
+	a.setChain(0x01020304)
+	a.setHash(5)
+	a.setKeyAndMask(1)
+
+	expected := "00000000" + "0000000000000000" + "00000000" + "00000000" + "01020304" + "00000005" + "00000001"
+	if hex.EncodeToString(a[:]) != expected {
+		t.Fatalf("Wrong ADRS encoding. Got %x, expected %s", a[:], expected)
+	}
+
+	// Changing type clears type specific words
+	a.setType(rfcAdrsLTree)
+	a.setTreeHeight(2)
+	a.setTreeIndex(3)
+
+	expected = "00000000" + "0000000000000000" + "00000001" + "00000000" + "00000002" + "00000003" + "00000000"
+	if hex.EncodeToString(a[:]) != expected {
+		t.Fatalf("Wrong ADRS encoding. Got %x, expected %s", a[:], expected)
+	}
+}
+
+func TestRFC8391_Consistency(t *testing.T) {
+	params := DecodeParams(WOTSP_SHA2_256)
+	key := NewKeyFromSeed(params, getRandData(t, SeedSize), getRandData(t, SeedSize))
+	msg := getRandData(t, 256)
+
+	// Test signing from scratch and after generation
+	sig := key.Sign(msg)
+	pk := key.ComputePK()
+	key.Generate()
+
+	if !bytes.Equal(sig, key.Sign(msg)) {
+		t.Fatalf("Key.Sign does not return the same signature before and after Generate()!")
+	}
+
+	if !bytes.Equal(pk, key.GetPK()) {
+		t.Fatalf("Key.ComputePK and Key.Generate computed different public keys!")
+	}
+
+	// Signature size: ParamsEncoding (1 byte), Public Seed (32 bytes), Ladders 67*32 bytes
+	if len(sig) != 1+SeedSize+67*32 {
+		t.Fatalf("Key.Sign returned signature with wrong size. Got %d, expected %d", len(sig), 1+SeedSize+67*32)
+	}
+
+	valid, err := Verify(msg, sig, pk)
+
+	if err != nil || !valid {
+		t.Fatalf("Key.Sign + Verify are not consistent for RFC 8391 params!")
+	}
+
+	valid, _ = Verify(getRandData(t, 256), sig, pk)
+
+	if valid {
+		t.Fatalf("Verify() should return false for a different message")
+	}
+}
+
+/////////////////////////////////////////////////
+// RFC 8391 KNOWN ANSWER TEST
+// RFC 8391 does not publish WOTS+ test vectors, so this vector is
+// reproduced by testdata/rfc8391_kat.py, a standalone transcription of
+// the RFC pseudocode (chain, WOTS_genPK, WOTS_sign and ltree) that only
+// uses Python's hashlib and shares no code with this package
+// SEED_SK = 0x00..0x1f, SEED = 0x20..0x3f
+// M = SHA2_256("XX NETWORK")
+const (
+	rfcTestVectorPubKey    = "311a4d9262ba7230891dacdc13b6f84fd444ff1ca80f51f25978c8f2d6a78c43"
+	rfcTestVectorSignature = "10202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3fbe2337f6dc4e993b4b06590f864a48a32d3fb08dc23fb96b026a80c6a31b8def9b72e35e0b1f72925633b5838ecc5c54efca878401edff3d2bea4baefb64c479bfe15aea8b472aed4fee46865bd730a771382f0288de3ba34f2b56cd53bc7330eb467fd34e6106b7dc682876bed2485a763046d57713bfbccf552e5d89b87052b4110f8287d7c4846899afbebd8f8d4a04234ebafc801b5e077cb311edd42f62aaa491b6f7624745cad71a8ccbc6a63361cb217ca71a81b2e6656709f7679e05133706c2ce1daaf0317171c0b7c9093840925d13a084c7b6e053a859c841acb8b69d4da3b32c6ee40cf7fca8c410bb1cad4a7a23130df1087b4b6c52318632bbc0de093859be64b714db51502c989ef198c9c2791180f2edfce1802caa1e941c8b51a04666f0a74de11681a85b396f2e6415cc822e7e7233f1738da1097b1dce38a9771f022db723ecd984160ae5ecee868af798ef8d5ec60e0569306cceda8ec4de899797c0b16c1228c4a42e17e288ed13cba6fb607f294f8266b3f5f679a0b9045259c2f5b1ba333b656ef76291ac5c70b76ec35e6076eb329af92b7a5edd9ffb918fa671a5e3a33b34f64ced051fd9a5e0711b2b4bda6942423fa49fba00c272494b883dce54a9e381482e91edf8472c7cf564fc09fcdcad154194030fd9e5010fc5945fec9d60edf42cb78c9a01639a238103e564fcc1e3e4ed7fe547dfaf675226d6d95967a2b58ab2ef32adf26058921c646b96c269a8b13ce2a1b57b7e92dd753e11d2e9c4f0171c33d2314a9ba1b8cef76e6635aea89e6dd8b0aadcf35337c6e796d7ea0180d6cb4d5e9f7de5281258717c0a6634edc4fc64f3199a24b96b21f7e5ef0ac2c2abc3ee5e50c17162895f6da933a2494f836d72e4c6979059d3df7cc5897f698d9f78b5ccc636658ab1e6c278eafdee243b085f561a30d37736dde2cb00df4cc60b772f71e0001869168d60e2f5463a701bbebc6909a14c50bcd4ffc1fa22ecc7f4610f12d41da330607a8712e2007d4edd842a751c6dbb2ee5fcc79da14de577f404c10ae839ab8b49d2ae8ffcdbe4ada10f33d13eb88b9cfbf789edac3bc3e87664f6734d1be792ff42f91dec6eba4b38399fedb204ca50b6392b026b1e42e261e329b26fe640ed9cb32b79cc6dff621892813fc2aa7d00eb226393099dbc3a7ea03392debaf43787b6e9bafef9e7aa832fa59af8bec2d4b0b17f4cd5f5919cf80a0f643e3f9c5dd6e4ae108e99b39f05fa6b85f740c1b776a5d29f5ddf6970e4ea49b5d02231d058cf8f7609b074c4ca0a45a024e731d799f354a177dae60fee62e403517da7337aad08c25a95521952309e6d8d10df409f79984e28695770ea1dd7824b11482ed371f169e51870cf2e0be4ed873e57dbb01b9306bfd9450d7effe8fa2c3603120b3d7eac68a85fc76bfc9b4b75061a983dea12544051cfca29673cb0cbf31abb84dc858b936ef69c0c86a90f9844a369f6074d8b3a3e7221c9238f0582ef5d553b1bcb3bcba92fba93721f03910505491844f776dbe847ff9bdc2046f264755019db727615a824ac5592aaed561dfbc579aa8ea1fca8bd3e04dce9050064765e3f15c56dd39ed5581ac221fe2e554570531b97985efa8b594e400bfd7324a88cef0328168eef8f8f35e75eff168d1123a30c46256b8d507d0c7f6b780d2dc21f0ff81263d6f145294183f098bc2f993939b3a5d1f39ae0999966cd0b608d61758d1ae478ed1f9ee99736c2aa7352fccc24edb13591a614aa3426ffe06d60257bb0630f1b0d4a9a134a6994df38fe17712d5f1ccd393e9857e98bf61b80d3cc6981b773d745a31ae0c59b52ad52d8bf10978cea1398f1a49de1f9dbd53b9491d9157bdacc65f9d5a2272dd2e12fabe8748e2e575f002b39e29164058eb521c7aa5249f06a27ebe0c2ad6f705ecd255cb2bdd7b6969d53f73b911779041ba5aef5e8364d73af2c9811e05e361668d5475c455324f91206d3fb578b3b24a4a6eab99c2f0477c657dc32e89cf76dc3fae256567bcb00f875a372eb056c4013ab253476c55c4a8a736ecfc8adcda9f31afd25871d4af570f172f67d15bcea9fbafd7921eaba07ce1fd189cd540722f352623b59561e2df4a02531137e15eb4893e7f45cbfe9e973282c5ad0283638c5e134327be48aae10b6f95988f7163b72fdb0924023f7787db2270a82ba2a4beafc0f4af4837f23229907c3872e789674e42b64b82d16794ad60c61a7bdf11fb076f1b126016bc35a40a800d1cbfdc4301263e6a2ba80fb747a8a6e5f0acfa0b6a3aa637c300f2b0631df19b5f70d6eecf7bda18ff8f26d110cd20e3ab4eb8d24d8332b51a1a33108a3ba914f898564e7709904e594006c71d707d9d2b508fea2e406041491f33378c27804b77cdab5679baf18ae9713906ec54e604ad4293cade5f32c9fa7581dbda5c3e22b1305ac8bc8cf4b062cdd95ecf8ea6b00ab2b131ccdb2e5bb7f1501c0f0c2078b3109e0b53ac39ff2f805570e92b6b276383a1913fe00633e2fd77c1c009e981a73d9f9c4729d6f098533f94e8de272467dd5359b3d4b22900fa1e6dcdba8e5bd0cf5912072cab07de511f4b7da042439e1f649ecff79851f3e9b1d54daee52f8cd522ab9697f8db10a5ea104ef8849ee9efa33a91aaadf7753eec048be20b7a56c2616e84dfac7f67903fe19dca14eaf7f77d892941710163a6ba0af2d7a2acac7307b7ab0781d195acec998d669743547aa28a4013d8c18ffb1f4e69347f911088f85588edbbab628734e7bf3090f5830b9f61fea99d516c0f107dcce37a27c46bb9f49f4a4ace384beb265cf8d4848c89022476b896d80c80340facab866f6e223bfd7869bf0b8dc6593c6890a54a660c029c4d3eadbed4a06809df680e5ff3b33a5e5dd414dffb7175f79f876892b6ba8b52a80eca51e9908b3aa551eca04cf9b8d824fe90a40e7cb9f6179e288ab343c64f9f555aa2cc549e9fb0c27136a2de6f3a92ef30a7ff772fa8d0dd914498dc9097a7a"
+)
+
+func TestRFC8391_KnownAnswer(t *testing.T) {
+	seed := make([]byte, SeedSize)
+	pSeed := make([]byte, SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+		pSeed[i] = byte(SeedSize + i)
+	}
+	key := NewKeyFromSeed(DecodeParams(WOTSP_SHA2_256), seed, pSeed)
+
+	// Validate public key
+	pk := key.ComputePK()
+	expectedPk := mustDecodeHex(t, rfcTestVectorPubKey)
+
+	if !bytes.Equal(pk, expectedPk) {
+		t.Fatalf("RFC 8391 public key is broken! Got: %x\nExpected: %x\n", pk, expectedPk)
+	}
+
+	// Validate signature
+	sig := key.Sign([]byte(TestData))
+	expectedSig := mustDecodeHex(t, rfcTestVectorSignature)
+
+	if !bytes.Equal(sig, expectedSig) {
+		t.Fatalf("RFC 8391 signature is broken! Got: %x\nExpected: %x\n", sig, expectedSig)
+	}
+
+	valid, err := Verify([]byte(TestData), expectedSig, expectedPk)
+
+	if err != nil || !valid {
+		t.Fatalf("Verify() failed for RFC 8391 known answer test")
+	}
+}
@@ -0,0 +1,153 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// MEMORY REUSE
+/*
+	Computing the ladders needs hash objects, and buffers for the message
+	digits, random elements, secret keys and ladder values. Instead of
+	allocating them on every call, buffers are kept in a pool of scratch
+	memory, and hash objects are taken from the hasher pools, so that
+	decoding a signature into a given output slice doesn't allocate
+	memory in steady state
+
+	Buffers that hold secret values are wiped before being returned
+	to the pool
+*/
+
+// Scratch memory for one computation of the ladders
+type scratch struct {
+	// Message hash and ladder positions
+	hashed []byte
+	target []byte
+	digits []byte
+	// Random elements, all sharing randsMem
+	rands    [][]byte
+	randsMem []byte
+	// Secret keys
+	sks []byte
+	// Last level of the ladders
+	outputs []byte
+	// L-tree nodes
+	ltree []byte
+	// Hash buffers for random elements, tweak and L-tree
+	buf []byte
+	key []byte
+	bm  []byte
+	// Encoded ladder index and address
+	idx  [2]byte
+	adrs adrs
+	// Decoded public key
+	pk [PKSize]byte
+	// Hash object keyed with the public seed, see keyed.go
+	keyed keyedHash
+	// Memory of each worker
+	workers []ladderWorker
+	// The ladders being computed
+	job ladderJob
+}
+
+// Memory used by a worker to walk the ladders
+type ladderWorker struct {
+	buf   []byte
+	value []byte
+	key   []byte
+	adrs  adrs
+	keyed keyedHash
+}
+
+// The ladders to compute, shared by all workers
+// See computeLaddersWithRands for the meaning of each field
+type ladderJob struct {
+	params   *Params
+	pSeed    []byte
+	start    []byte
+	points   []byte
+	outputs  []byte
+	chains   [][]byte
+	rands    [][]byte
+	sign     bool
+	saved    bool
+	interval int
+}
+
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return new(scratch)
+	},
+}
+
+// Get scratch memory from the pool
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+// Wipe secrets and return the scratch memory to the pool
+func putScratch(s *scratch) {
+	secmem.Wipe(s.sks)
+	secmem.Wipe(s.buf[:cap(s.buf)])
+	secmem.Wipe(s.key)
+	secmem.Wipe(s.bm)
+	s.job = ladderJob{}
+	scratchPool.Put(s)
+}
+
+// Get the memory of the workers, reusing previous memory
+func (s *scratch) getWorkers(n int) []ladderWorker {
+	if cap(s.workers) < n {
+		s.workers = make([]ladderWorker, n)
+	}
+	s.workers = s.workers[:n]
+	return s.workers
+}
+
+// Get memory for w-1 random elements of n bytes
+func (s *scratch) getRands(n, w int) [][]byte {
+	s.randsMem = grow(s.randsMem, (w-1)*n)
+	if cap(s.rands) < w-1 {
+		s.rands = make([][]byte, w-1)
+	}
+	s.rands = s.rands[:w-1]
+	for i := range s.rands {
+		s.rands[i] = s.randsMem[i*n : (i+1)*n]
+	}
+	return s.rands
+}
+
+// Get a slice of length n, reusing the memory of b if it's large enough
+func grow(b []byte, n int) []byte {
+	if cap(b) < n {
+		return make([]byte, n)
+	}
+	return b[:n]
+}
+
+// Get an empty slice with capacity of at least n, reusing the memory of b if it's large enough
+func growCap(b []byte, n int) []byte {
+	if cap(b) < n {
+		return make([]byte, 0, n)
+	}
+	return b[:0]
+}
+
+// Extend b by n bytes, returning the extended slice and the new n bytes
+func extend(b []byte, n int) ([]byte, []byte) {
+	size := len(b) + n
+	if cap(b) < size {
+		nb := make([]byte, len(b), size)
+		copy(nb, b)
+		b = nb
+	}
+	b = b[:size]
+	return b, b[size-n:]
+}
@@ -0,0 +1,128 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/xx-labs/sleeve/hasher"
+	"testing"
+)
+
+// Params tested for allocations, including all registered params
+func allocParamsList() []*Params {
+	list := []*Params{
+		NewParamsW(32, 24, 16, hasher.BLAKE3_256, hasher.SHA3_224),
+		NewParamsW(32, 24, 4, hasher.SHA3_256, hasher.SHA2_256),
+		NewParamsW(48, 96, 16, hasher.SHAKE128, hasher.SHAKE128),
+	}
+	for _, info := range RegisteredParams() {
+		list = append(list, info.Params)
+	}
+	return list
+}
+
+func TestVerify_ZeroAllocs(t *testing.T) {
+	msg := []byte("zero allocations")
+	for _, params := range allocParamsList() {
+		key := NewKey(params, rand.Reader)
+		sig := key.Sign(msg)
+		pk := key.ComputePK()
+
+		// Warm up pools
+		if ok, err := params.Verify(msg, sig[1:], pk); !ok || err != nil {
+			t.Fatalf("Params.Verify() returned invalid signature for params %s", params)
+		}
+
+		allocs := testing.AllocsPerRun(10, func() {
+			_, _ = params.Verify(msg, sig[1:], pk)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Params.Verify() should not allocate memory for params %s, got %.1f allocations", params, allocs)
+		}
+
+		out := make([]byte, 0, PKSize)
+		allocs = testing.AllocsPerRun(10, func() {
+			out, _ = params.DecodeTo(out[:0], msg, sig[1:])
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Params.DecodeTo() should not allocate memory for params %s, got %.1f allocations", params, allocs)
+		}
+
+		s, err := ParseSignature(sig)
+		if err != nil {
+			// Unregistered params can't be parsed
+			continue
+		}
+		allocs = testing.AllocsPerRun(10, func() {
+			_, _ = s.Verify(msg, pk)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Signature.Verify() should not allocate memory for params %s, got %.1f allocations", params, allocs)
+		}
+
+		allocs = testing.AllocsPerRun(10, func() {
+			_, _ = Verify(msg, sig, pk)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Verify() should not allocate memory for params %s, got %.1f allocations", params, allocs)
+		}
+	}
+}
+
+func TestKey_SignTo(t *testing.T) {
+	msg := []byte("sign to")
+	for _, params := range allocParamsList() {
+		key := NewKey(params, rand.Reader)
+		expected := key.Sign(msg)
+
+		// Signature is appended to out
+		prefix := []byte{0xAA, 0xBB}
+		out := make([]byte, len(prefix), len(prefix)+len(expected))
+		copy(out, prefix)
+		out = key.SignTo(out, msg)
+		if !bytes.Equal(out[:len(prefix)], prefix) || !bytes.Equal(out[len(prefix):], expected) {
+			t.Fatalf("Key.SignTo() returned wrong signature for params %s", params)
+		}
+
+		// PK is appended to out, and not stored
+		pk := key.ComputePKTo(nil)
+		if key.GetPK() != nil {
+			t.Fatalf("Key.ComputePKTo() shouldn't store the PK in the key")
+		}
+		if !bytes.Equal(pk, key.ComputePK()) {
+			t.Fatalf("Key.ComputePKTo() returned wrong PK for params %s", params)
+		}
+
+		// No allocations with enough capacity
+		allocs := testing.AllocsPerRun(5, func() {
+			out = key.SignTo(out[:0], msg)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Key.SignTo() should not allocate memory for params %s, got %.1f allocations", params, allocs)
+		}
+
+		// Same with generated key
+		key.Generate()
+		allocs = testing.AllocsPerRun(5, func() {
+			out = key.SignTo(out[:0], msg)
+		})
+		if allocs != 0 && !raceEnabled {
+			t.Fatalf("Key.SignTo() with generated key should not allocate memory for params %s, got %.1f allocations",
+				params, allocs)
+		}
+		if !bytes.Equal(out, expected) {
+			t.Fatalf("Key.SignTo() with generated key returned wrong signature for params %s", params)
+		}
+
+		// Destroyed key returns out unchanged
+		key.Destroy()
+		if len(key.SignTo(out[:0], msg)) != 0 || len(key.ComputePKTo(out[:0])) != 0 {
+			t.Fatalf("Key.SignTo() and Key.ComputePKTo() should return out unchanged when key was destroyed")
+		}
+	}
+}
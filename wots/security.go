@@ -73,6 +73,7 @@ var level1Params = NewParams(level1N, level1M, level1PrfH, level1MsgH)
 // Post quantum: 112
 // Note: Post quantum MSG Hash Security is 96, but the message
 // is always structured, so CMA doesn't apply
+// Level2Randomized doesn't rely on messages being structured
 
 // PARAMETERS
 // N = 224 bits = 28 bytes
@@ -102,6 +103,7 @@ var level2Params = NewParams(level2N, level2M, level2PrfH, level2MsgH)
 // Post quantum: 128
 // Note: Post quantum MSG Hash Security is 96, but the message
 // is always structured, so CMA doesn't apply
+// Level3Randomized doesn't rely on messages being structured
 
 // PARAMETERS
 // N = 256 bits = 32 bytes
@@ -153,6 +155,105 @@ var consensusParams = NewParams(consensusN, consensusM, consensusPrfH, consensus
 ///////////////////////////////////////////////////////////////////////
 ///////////////////////////////////////////////////////////////////////
 
+///////////////////////////////////////////////////////////////////////
+// RFC 8391 WOTSP-SHA2_256 INSTANTIATION
+///////////////////////////////////////////////////////////////////////
+// Security Levels
+// Classical:    241.93
+// Post quantum: 128
+
+// PARAMETERS
+// N = 256 bits = 32 bytes
+// M = 256 bits = 32 bytes
+// W = 16
+// F, H, PRF and MSG Hash = SHA2_256
+
+// NOTE: Follows RFC 8391, so signatures can be cross checked
+// against other WOTS+ implementations
+
+// Resulting signature size: 17416 bits
+///////////////////////////////////////////////////////////////////////
+const (
+	wotspSHA2_256H = hasher.SHA2_256
+)
+
+var wotspSHA2_256Params = NewParamsRFC8391(wotspSHA2_256H)
+
+///////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////
+
+///////////////////////////////////////////////////////////////////////
+// RANDOMIZED WOTS+ INSTANTIATIONS
+///////////////////////////////////////////////////////////////////////
+// Security Levels
+// Same as Level0 to Level3
+// Note: The MSG Hash Security holds for any message, since the
+// message is hashed with a randomizer, see randomized.go
+
+// PARAMETERS
+// Same as Level0 to Level3, with randomized message hashing
+
+// Resulting signature size: n bytes larger, for the randomizer
+///////////////////////////////////////////////////////////////////////
+var (
+	level0RandomizedParams = level0Params.WithRandomizedHashing()
+	level1RandomizedParams = level1Params.WithRandomizedHashing()
+	level2RandomizedParams = level2Params.WithRandomizedHashing()
+	level3RandomizedParams = level3Params.WithRandomizedHashing()
+)
+
+///////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////
+
+///////////////////////////////////////////////////////////////////////
+// TARGET SUM WOTS+ INSTANTIATIONS
+///////////////////////////////////////////////////////////////////////
+// Security Levels
+// Classical:    139.42, 171.42, 203.42, 235.42
+// Post quantum: 80, 96, 112, 128
+// Note: MSG Hash Security is 182.17 classical and 91.08 post
+// quantum, since requiring the target sum loses 9.83 bits
+
+// PARAMETERS
+// Same as Level0 to Level3, without checksum ladders
+// Target sum = 3060
+
+// Resulting signature sizes: 4136, 4904, 5672, 6440 bits
+///////////////////////////////////////////////////////////////////////
+var (
+	level0TargetSumParams = NewParamsTargetSum(level0N, level0M, W, level0PrfH, level0MsgH)
+	level1TargetSumParams = NewParamsTargetSum(level1N, level1M, W, level1PrfH, level1MsgH)
+	level2TargetSumParams = NewParamsTargetSum(level2N, level2M, W, level2PrfH, level2MsgH)
+	level3TargetSumParams = NewParamsTargetSum(level3N, level3M, W, level3PrfH, level3MsgH)
+)
+
+///////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////
+
+///////////////////////////////////////////////////////////////////////
+// KEYED WOTS+ INSTANTIATIONS
+///////////////////////////////////////////////////////////////////////
+// Security Levels
+// Same as Level0 to Level3
+
+// PARAMETERS
+// Same as Level0 to Level3, with the keyed hash construction, see keyed.go
+// PRF HASH = BLAKE3_256
+
+// Resulting signature sizes: Same as Level0 to Level3
+///////////////////////////////////////////////////////////////////////
+const keyedPrfH = hasher.BLAKE3_256
+
+var (
+	level0KeyedParams = NewParamsKeyed(level0N, level0M, W, keyedPrfH, level0MsgH)
+	level1KeyedParams = NewParamsKeyed(level1N, level1M, W, keyedPrfH, level1MsgH)
+	level2KeyedParams = NewParamsKeyed(level2N, level2M, W, keyedPrfH, level2MsgH)
+	level3KeyedParams = NewParamsKeyed(level3N, level3M, W, keyedPrfH, level3MsgH)
+)
+
+///////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////
+
 ///////////////////////////////////////////////////////////////////////
 // Params encoding
 type ParamsEncoding uint8
@@ -165,45 +266,103 @@ const (
 	Level3
 	Consensus
 )
+// ParamsEncodingLen is reserved to represent unknown params, and can't be registered
 const (
 	ParamsEncodingLen = Consensus + 1 // 5
 	DefaultParams     = Level0
 )
 
+// Encode the RFC 8391 compliant parameter sets
+// These are kept in a separate range from the xx network parameter sets
+const (
+	WOTSP_SHA2_256 ParamsEncoding = 0x10 + iota
+)
+
+// Encode the parameter sets with randomized message hashing
+// These are kept in a separate range, so signatures of the original sets are unchanged
+const (
+	Level0Randomized ParamsEncoding = 0x20 + iota
+	Level1Randomized
+	Level2Randomized
+	Level3Randomized
+)
+
+// Encode the parameter sets with a target sum instead of checksum ladders
+const (
+	Level0TargetSum ParamsEncoding = 0x30 + iota
+	Level1TargetSum
+	Level2TargetSum
+	Level3TargetSum
+)
+
+// Encode the parameter sets with the keyed hash construction
+const (
+	Level0Keyed ParamsEncoding = 0x40 + iota
+	Level1Keyed
+	Level2Keyed
+	Level3Keyed
+)
+
+// Register the parameter sets defined above
+func init() {
+	mustRegisterParams(Level0, level0Params, "Level0", SecurityLevels{Classical: 139.30, PostQuantum: 80})
+	mustRegisterParams(Level1, level1Params, "Level1", SecurityLevels{Classical: 171.30, PostQuantum: 96})
+	mustRegisterParams(Level2, level2Params, "Level2", SecurityLevels{Classical: 203.30, PostQuantum: 112})
+	mustRegisterParams(Level3, level3Params, "Level3", SecurityLevels{Classical: 235.30, PostQuantum: 128})
+	mustRegisterParams(Consensus, consensusParams, "Consensus", SecurityLevels{Classical: 234.91, PostQuantum: 128})
+	mustRegisterParams(WOTSP_SHA2_256, wotspSHA2_256Params, "WOTSP-SHA2_256",
+		SecurityLevels{Classical: 241.93, PostQuantum: 128})
+	mustRegisterParams(Level0Randomized, level0RandomizedParams, "Level0Randomized",
+		SecurityLevels{Classical: 139.30, PostQuantum: 80})
+	mustRegisterParams(Level1Randomized, level1RandomizedParams, "Level1Randomized",
+		SecurityLevels{Classical: 171.30, PostQuantum: 96})
+	mustRegisterParams(Level2Randomized, level2RandomizedParams, "Level2Randomized",
+		SecurityLevels{Classical: 203.30, PostQuantum: 112})
+	mustRegisterParams(Level3Randomized, level3RandomizedParams, "Level3Randomized",
+		SecurityLevels{Classical: 235.30, PostQuantum: 128})
+	mustRegisterParams(Level0TargetSum, level0TargetSumParams, "Level0TargetSum",
+		SecurityLevels{Classical: 139.42, PostQuantum: 80})
+	mustRegisterParams(Level1TargetSum, level1TargetSumParams, "Level1TargetSum",
+		SecurityLevels{Classical: 171.42, PostQuantum: 96})
+	mustRegisterParams(Level2TargetSum, level2TargetSumParams, "Level2TargetSum",
+		SecurityLevels{Classical: 203.42, PostQuantum: 112})
+	mustRegisterParams(Level3TargetSum, level3TargetSumParams, "Level3TargetSum",
+		SecurityLevels{Classical: 235.42, PostQuantum: 128})
+	mustRegisterParams(Level0Keyed, level0KeyedParams, "Level0Keyed",
+		SecurityLevels{Classical: 139.30, PostQuantum: 80})
+	mustRegisterParams(Level1Keyed, level1KeyedParams, "Level1Keyed",
+		SecurityLevels{Classical: 171.30, PostQuantum: 96})
+	mustRegisterParams(Level2Keyed, level2KeyedParams, "Level2Keyed",
+		SecurityLevels{Classical: 203.30, PostQuantum: 112})
+	mustRegisterParams(Level3Keyed, level3KeyedParams, "Level3Keyed",
+		SecurityLevels{Classical: 235.30, PostQuantum: 128})
+}
+
 // Get the parameter set from its encoding
+// Returns nil if the encoding is not registered
 func DecodeParams(enc ParamsEncoding) *Params {
-	switch enc {
-	case Level0:
-		return level0Params
-	case Level1:
-		return level1Params
-	case Level2:
-		return level2Params
-	case Level3:
-		return level3Params
-	case Consensus:
-		return consensusParams
-	default:
-		return nil
+	registry.RLock()
+	defer registry.RUnlock()
+	if info, ok := registry.byEncoding[enc]; ok {
+		return info.Params
 	}
+	return nil
 }
 
 // Encode a parameter set
+// Returns ParamsEncodingLen if the params are not registered
+// Registered params, and copies of them with different workers, are encoded
+// without taking the registry lock
 func EncodeParams(p *Params) ParamsEncoding {
-	if level0Params.Equal(p) {
-		return Level0
-	}
-	if level1Params.Equal(p) {
-		return Level1
-	}
-	if level2Params.Equal(p) {
-		return Level2
-	}
-	if level3Params.Equal(p) {
-		return Level3
+	if enc, ok := p.cachedEncoding(); ok {
+		return enc
 	}
-	if consensusParams.Equal(p) {
-		return Consensus
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, info := range registry.list {
+		if info.Params.Equal(p) {
+			return info.Encoding
+		}
 	}
 	// This will decode to nil
 	return ParamsEncodingLen
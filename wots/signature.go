@@ -0,0 +1,455 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+///////////////////////////////////////////////////////////////////////
+// SIGNATURE
+/*
+	A WOTS+ signature is serialized as:
+	  ParamsEncoding, 1 byte
+	  Public Seed,    32 bytes
+	  Randomizer,     n bytes (only if the params use randomized message hashing)
+	  Counter,        4 bytes (only if the params use a target sum)
+	  Ladder points,  total*n bytes
+
+	The size of the signature depends on the params, so the params
+	encoding is needed to validate the length
+
+	Signatures must be created with ParseSignature or one of the
+	unmarshal methods. Zero value signatures are invalid: getters
+	return empty values, and the other methods return ErrInvalidSignature
+*/
+type Signature struct {
+	// Encoding of the params used to create the signature
+	encoding ParamsEncoding
+	// The params used to create the signature
+	params *Params
+	// The public seed of the key
+	pSeed []byte
+	// The randomizer, only if the params use randomized message hashing
+	r []byte
+	// The counter, only if the params use a target sum
+	counter []byte
+	// The ladder points, total*n bytes
+	points []byte
+}
+
+// Error returned when the serialized signature is empty
+var ErrEmptySignature = errors.New("signature is empty")
+
+// Error returned when using a signature that wasn't parsed, e.g., a zero value signature
+var ErrInvalidSignature = errors.New("signature is not initialized: use ParseSignature")
+
+// Error returned when the params encoding of a signature is unknown
+type SignatureParamsError struct {
+	Encoding ParamsEncoding
+}
+
+func (e *SignatureParamsError) Error() string {
+	return fmt.Sprintf("signature has unknown params encoding: %d", e.Encoding)
+}
+
+// Error returned when the signature has incorrect length for its params
+type SignatureLengthError struct {
+	Encoding ParamsEncoding
+	Expected int
+	Got      int
+}
+
+func (e *SignatureLengthError) Error() string {
+	return fmt.Sprintf("signature has incorrect length for params encoding %d: expected %d bytes, got %d",
+		e.Encoding, e.Expected, e.Got)
+}
+
+// Get the size of a serialized signature with the given params encoding
+// Returns 0 if the encoding is unknown
+func SignatureSize(enc ParamsEncoding) int {
+	params := DecodeParams(enc)
+	if params == nil {
+		return 0
+	}
+	return 1 + params.sigSize()
+}
+
+///////////////////////////////////////////////////////////////////////
+// PARSING
+
+// Parse a serialized signature, validating params and length
+func ParseSignature(data []byte) (*Signature, error) {
+	// 1. Check signature is not empty
+	if len(data) == 0 {
+		return nil, ErrEmptySignature
+	}
+
+	// 2. Decode params
+	enc := ParamsEncoding(data[0])
+	params := DecodeParams(enc)
+	if params == nil {
+		return nil, &SignatureParamsError{Encoding: enc}
+	}
+
+	// 3. Check length
+	size := SignatureSize(enc)
+	if len(data) != size {
+		return nil, &SignatureLengthError{Encoding: enc, Expected: size, Got: len(data)}
+	}
+
+	// 4. Copy data
+	s := &Signature{
+		encoding: enc,
+		params:   params,
+		pSeed:    make([]byte, SeedSize),
+		points:   make([]byte, params.total*params.n),
+	}
+	pSeed, r, counter, points := params.splitSignature(data[1:])
+	copy(s.pSeed, pSeed)
+	copy(s.points, points)
+	if r != nil {
+		s.r = make([]byte, len(r))
+		copy(s.r, r)
+	}
+	if counter != nil {
+		s.counter = make([]byte, len(counter))
+		copy(s.counter, counter)
+	}
+	return s, nil
+}
+
+// Parse a hex encoded signature
+func ParseSignatureHex(str string) (*Signature, error) {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignature(data)
+}
+
+// Parse a base64 (standard encoding) signature
+func ParseSignatureBase64(str string) (*Signature, error) {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignature(data)
+}
+
+///////////////////////////////////////////////////////////////////////
+// GETTERS
+
+// Check the signature was parsed, i.e., all its parts have the size of its params
+func (s *Signature) check() error {
+	if s == nil || s.params == nil || len(s.pSeed) != SeedSize ||
+		len(s.r) != s.params.randomizerSize() || len(s.counter) != s.params.counterSize() ||
+		len(s.points) != s.params.total*s.params.n {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Get the params used to create the signature
+// Returns nil for invalid signatures
+func (s *Signature) Params() *Params {
+	if s.check() != nil {
+		return nil
+	}
+	return s.params
+}
+
+// Get the encoding of the params used to create the signature
+// Returns ParamsEncodingLen, which is reserved for unknown params, for invalid signatures
+func (s *Signature) Encoding() ParamsEncoding {
+	if s.check() != nil {
+		return ParamsEncodingLen
+	}
+	return s.encoding
+}
+
+// Get the public seed of the signing key
+// Returns nil for invalid signatures
+func (s *Signature) PublicSeed() []byte {
+	if s.check() != nil {
+		return nil
+	}
+	pSeed := make([]byte, SeedSize)
+	copy(pSeed, s.pSeed)
+	return pSeed
+}
+
+// Get the randomizer of the signature
+// Returns nil if the params don't use randomized message hashing, or for invalid signatures
+func (s *Signature) Randomizer() []byte {
+	if s.check() != nil || s.r == nil {
+		return nil
+	}
+	r := make([]byte, len(s.r))
+	copy(r, s.r)
+	return r
+}
+
+// Get the counter of the signature
+// Returns nil if the params don't use a target sum, or for invalid signatures
+func (s *Signature) Counter() []byte {
+	if s.check() != nil || s.counter == nil {
+		return nil
+	}
+	counter := make([]byte, len(s.counter))
+	copy(counter, s.counter)
+	return counter
+}
+
+// Get the ladder points of the signature, one for each ladder
+// Returns nil for invalid signatures
+func (s *Signature) Chains() [][]byte {
+	if s.check() != nil {
+		return nil
+	}
+	n := s.params.n
+	chains := make([][]byte, s.params.total)
+	for i := range chains {
+		chains[i] = make([]byte, n)
+		copy(chains[i], s.points[i*n:(i+1)*n])
+	}
+	return chains
+}
+
+// Get the serialized signature
+// Returns nil for invalid signatures
+func (s *Signature) Bytes() []byte {
+	if s.check() != nil {
+		return nil
+	}
+	data := make([]byte, 0, 1+SeedSize+len(s.r)+len(s.counter)+len(s.points))
+	data = append(data, byte(s.encoding))
+	data = append(data, s.pSeed...)
+	data = append(data, s.r...)
+	data = append(data, s.counter...)
+	return append(data, s.points...)
+}
+
+// Get the hex encoded signature
+// Returns an empty string for invalid signatures
+func (s *Signature) String() string {
+	return hex.EncodeToString(s.Bytes())
+}
+
+// Get the base64 (standard encoding) signature
+// Returns an empty string for invalid signatures
+func (s *Signature) Base64() string {
+	return base64.StdEncoding.EncodeToString(s.Bytes())
+}
+
+///////////////////////////////////////////////////////////////////////
+// VERIFICATION
+
+// Decode the signature, i.e., compute the public key from the message
+// The output slice must have length 0 and capacity of 32 bytes, see Params.Decode
+// NOTE: like DecodeTransactionSignature, Consensus parameters are NOT allowed
+func (s *Signature) Decode(out, msg []byte) ([]byte, error) {
+	if len(out) != 0 || cap(out) != PKSize {
+		return nil, errInvalidOutputSlice
+	}
+	return s.decodeTransaction(out, msg)
+}
+
+// Decode the signature, appending the public key to out
+// If out has enough capacity, no memory is allocated
+// For target sum params, out is returned unchanged if the message doesn't reach the target sum
+// NOTE: like Decode, out is returned unchanged for Consensus parameters
+func (s *Signature) DecodeTo(out, msg []byte) []byte {
+	pk, err := s.decodeTransaction(out, msg)
+	if err != nil {
+		return out
+	}
+	return pk
+}
+
+// Verify the signature of the message against the public key
+// Like the package level Verify, Consensus parameters are allowed: the public
+// key is checked, so a consensus signature can't pass as another key's signature
+func (s *Signature) Verify(msg, pubkey []byte) (bool, error) {
+	if len(pubkey) != PKSize {
+		return false, errWrongPubKeySize
+	}
+	if err := s.check(); err != nil {
+		return false, err
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+	pk, err := s.decodeTo(sc.pk[:0], msg)
+	return bytes.Equal(pk, pubkey), err
+}
+
+// Get the ladder positions of the signature for the message, one for each ladder
+// The positions are computed from the message, randomizer and counter, without
+// checking the ladder points, see Verify
+// For target sum params, returns an error if the message doesn't reach the target sum
+func (s *Signature) Positions(msg []byte) ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+	digest := s.params.hashMsg(sc, s.r, s.pSeed, msg)
+	positions, ok := s.params.digestPositions(sc, digest, s.counter)
+	if !ok {
+		return nil, errTargetSum
+	}
+	return positions, nil
+}
+
+// Decode the signature, appending the public key to out
+// Returns an error for Consensus parameters, see DecodeTransactionSignature
+func (s *Signature) decodeTransaction(out, msg []byte) ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	if s.encoding == Consensus {
+		return nil, errConsensusParams
+	}
+	return s.decodeTo(out, msg)
+}
+
+// Decode the signature, appending the public key to out
+func (s *Signature) decodeTo(out, msg []byte) ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+	return s.params.decodePoints(out, s.pSeed, s.params.hashMsg(sc, s.r, s.pSeed, msg), s.counter, s.points, nil)
+}
+
+///////////////////////////////////////////////////////////////////////
+// MARSHALING
+
+// Binary marshaling, using the serialized signature
+func (s *Signature) MarshalBinary() ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return s.Bytes(), nil
+}
+
+func (s *Signature) UnmarshalBinary(data []byte) error {
+	sig, err := ParseSignature(data)
+	if err != nil {
+		return err
+	}
+	*s = *sig
+	return nil
+}
+
+// Text marshaling, using the hex encoded signature
+func (s *Signature) MarshalText() ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *Signature) UnmarshalText(text []byte) error {
+	sig, err := ParseSignatureHex(string(text))
+	if err != nil {
+		return err
+	}
+	*s = *sig
+	return nil
+}
+
+// JSON representation of a signature
+type signatureJson struct {
+	Params     ParamsEncoding `json:"Params"`
+	PublicSeed string         `json:"PublicSeed"`
+	Randomizer string         `json:"Randomizer,omitempty"`
+	Counter    string         `json:"Counter,omitempty"`
+	Chains     []string       `json:"Chains"`
+}
+
+// JSON marshaling, with the params, public seed, randomizer, counter and each ladder point in hex
+// The randomizer and counter are omitted if the params don't use them
+func (s *Signature) MarshalJSON() ([]byte, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	chains := s.Chains()
+	sj := signatureJson{
+		Params:     s.encoding,
+		PublicSeed: hex.EncodeToString(s.pSeed),
+		Randomizer: hex.EncodeToString(s.r),
+		Counter:    hex.EncodeToString(s.counter),
+		Chains:     make([]string, len(chains)),
+	}
+	for i, c := range chains {
+		sj.Chains[i] = hex.EncodeToString(c)
+	}
+	return json.Marshal(sj)
+}
+
+func (s *Signature) UnmarshalJSON(data []byte) error {
+	var sj signatureJson
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+
+	// Rebuild serialized signature, then parse it to validate
+	pSeed, err := hex.DecodeString(sj.PublicSeed)
+	if err != nil {
+		return err
+	}
+	if len(pSeed) != SeedSize {
+		return errors.New(fmt.Sprintf("signature public seed has incorrect length: expected %d bytes, got %d",
+			SeedSize, len(pSeed)))
+	}
+	params := DecodeParams(sj.Params)
+	if params == nil {
+		return &SignatureParamsError{Encoding: sj.Params}
+	}
+	if len(sj.Chains) != params.total {
+		return errors.New(fmt.Sprintf("signature has incorrect number of ladder points: expected %d, got %d",
+			params.total, len(sj.Chains)))
+	}
+	r, err := hex.DecodeString(sj.Randomizer)
+	if err != nil {
+		return err
+	}
+	if len(r) != params.randomizerSize() {
+		return errors.New(fmt.Sprintf("signature randomizer has incorrect length: expected %d bytes, got %d",
+			params.randomizerSize(), len(r)))
+	}
+	counter, err := hex.DecodeString(sj.Counter)
+	if err != nil {
+		return err
+	}
+	if len(counter) != params.counterSize() {
+		return errors.New(fmt.Sprintf("signature counter has incorrect length: expected %d bytes, got %d",
+			params.counterSize(), len(counter)))
+	}
+	raw := append([]byte{byte(sj.Params)}, pSeed...)
+	raw = append(raw, r...)
+	raw = append(raw, counter...)
+	for _, str := range sj.Chains {
+		c, err := hex.DecodeString(str)
+		if err != nil {
+			return err
+		}
+		if len(c) != params.n {
+			return errors.New(fmt.Sprintf("signature ladder point has incorrect length: expected %d bytes, got %d",
+				params.n, len(c)))
+		}
+		raw = append(raw, c...)
+	}
+	return s.UnmarshalBinary(raw)
+}
@@ -0,0 +1,313 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseSignature(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	msg := getRandData(t, 256)
+	raw := key.Sign(msg)
+
+	// Test empty signature
+	_, err := ParseSignature(nil)
+
+	if err != ErrEmptySignature {
+		t.Fatalf("ParseSignature() should return ErrEmptySignature when signature is empty")
+	}
+
+	// Test unknown params
+	wrong := append([]byte{}, raw...)
+	wrong[0] = byte(ParamsEncodingLen)
+	_, err = ParseSignature(wrong)
+
+	var paramsErr *SignatureParamsError
+	if !errors.As(err, &paramsErr) || paramsErr.Encoding != ParamsEncodingLen {
+		t.Fatalf("ParseSignature() should return SignatureParamsError when params are unknown. Got %v", err)
+	}
+
+	// Test wrong length, for each params level
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		wrong[0] = byte(enc)
+		_, err = ParseSignature(wrong[:100])
+
+		var lenErr *SignatureLengthError
+		if !errors.As(err, &lenErr) {
+			t.Fatalf("ParseSignature() should return SignatureLengthError when length is wrong. Got %v", err)
+		}
+
+		if lenErr.Encoding != enc || lenErr.Expected != SignatureSize(enc) || lenErr.Got != 100 {
+			t.Fatalf("ParseSignature() returned wrong SignatureLengthError: %v", lenErr)
+		}
+	}
+
+	// Test valid signature
+	sig, err := ParseSignature(raw)
+
+	if err != nil {
+		t.Fatalf("ParseSignature() returned error for valid signature: %s", err)
+	}
+
+	if !sig.Params().Equal(level0Params) || sig.Encoding() != Level0 {
+		t.Fatalf("Signature.Params() returned wrong params: %s", sig.Params())
+	}
+
+	if !bytes.Equal(sig.PublicSeed(), key.pSeed) {
+		t.Fatalf("Signature.PublicSeed() returned wrong public seed")
+	}
+
+	chains := sig.Chains()
+
+	if len(chains) != level0Params.total {
+		t.Fatalf("Signature.Chains() returned wrong number of ladders. Got %d, expected %d",
+			len(chains), level0Params.total)
+	}
+
+	if !bytes.Equal(bytes.Join(chains, nil), raw[1+SeedSize:]) {
+		t.Fatalf("Signature.Chains() returned wrong ladder points")
+	}
+
+	if !bytes.Equal(sig.Bytes(), raw) {
+		t.Fatalf("Signature.Bytes() is different from the parsed signature")
+	}
+
+	// Test signature doesn't share memory with input
+	raw[1] ^= 1
+
+	if bytes.Equal(sig.Bytes(), raw) {
+		t.Fatalf("ParseSignature() should copy the signature data")
+	}
+	raw[1] ^= 1
+
+	// Test verification
+	valid, err := sig.Verify(msg, key.ComputePK())
+
+	if err != nil || !valid {
+		t.Fatalf("Signature.Verify() should be valid for correct message and public key")
+	}
+
+	valid, _ = sig.Verify(getRandData(t, 256), key.ComputePK())
+
+	if valid {
+		t.Fatalf("Signature.Verify() should be invalid for wrong message")
+	}
+}
+
+func TestSignature_Encodings(t *testing.T) {
+	key := NewKey(level1Params, rand.Reader)
+	raw := key.Sign(getRandData(t, 256))
+	sig, _ := ParseSignature(raw)
+
+	// Binary
+	data, err := sig.MarshalBinary()
+
+	if err != nil || !bytes.Equal(data, raw) {
+		t.Fatalf("Signature.MarshalBinary() should return the serialized signature")
+	}
+
+	other := new(Signature)
+
+	if err := other.UnmarshalBinary(data); err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("Signature.UnmarshalBinary() didn't load the signature: %v", err)
+	}
+
+	// Hex
+	other, err = ParseSignatureHex(sig.String())
+
+	if err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("ParseSignatureHex() didn't load the signature: %v", err)
+	}
+
+	if _, err = ParseSignatureHex("zz"); err == nil {
+		t.Fatalf("ParseSignatureHex() should return error for invalid hex")
+	}
+
+	// Base64
+	other, err = ParseSignatureBase64(sig.Base64())
+
+	if err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("ParseSignatureBase64() didn't load the signature: %v", err)
+	}
+
+	// Text
+	text, _ := sig.MarshalText()
+	other = new(Signature)
+
+	if err := other.UnmarshalText(text); err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("Signature.UnmarshalText() didn't load the signature: %v", err)
+	}
+
+	// JSON
+	js, err := json.Marshal(sig)
+
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error for signature: %s", err)
+	}
+
+	other = new(Signature)
+
+	if err := json.Unmarshal(js, other); err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("Signature.UnmarshalJSON() didn't load the signature: %v", err)
+	}
+
+	// JSON with wrong number of ladders
+	var sj signatureJson
+	_ = json.Unmarshal(js, &sj)
+	sj.Chains = sj.Chains[1:]
+	js, _ = json.Marshal(sj)
+
+	if err := json.Unmarshal(js, other); err == nil {
+		t.Fatalf("Signature.UnmarshalJSON() should return error when number of ladders is wrong")
+	}
+
+	// JSON with ladder points of wrong size
+	_ = json.Unmarshal(js, &sj)
+	sj.Chains = append(sj.Chains, "")
+	sj.Chains[0] += sj.Chains[1]
+	sj.Chains[1] = ""
+	js, _ = json.Marshal(sj)
+
+	if err := json.Unmarshal(js, other); err == nil {
+		t.Fatalf("Signature.UnmarshalJSON() should return error when ladder points have wrong size")
+	}
+}
+
+func TestSignature_Invalid(t *testing.T) {
+	key := NewKey(level0Params, rand.Reader)
+	msg := getRandData(t, 256)
+	pk := key.ComputePK()
+
+	// Zero value and truncated signatures can't be used, but don't panic
+	short, _ := ParseSignature(key.Sign(msg))
+	short.points = short.points[:len(short.points)-1]
+	for _, s := range []*Signature{{}, new(Signature), short, nil} {
+		if s.Params() != nil || s.Encoding() != ParamsEncodingLen || s.PublicSeed() != nil ||
+			s.Randomizer() != nil || s.Counter() != nil || s.Chains() != nil {
+			t.Fatalf("Signature getters should return empty values for invalid signature")
+		}
+		if s.Bytes() != nil || s.String() != "" || s.Base64() != "" {
+			t.Fatalf("Signature encodings should return empty values for invalid signature")
+		}
+		if ok, err := s.Verify(msg, pk); ok || err != ErrInvalidSignature {
+			t.Fatalf("Signature.Verify() should return ErrInvalidSignature for invalid signature, got %v", err)
+		}
+		if _, err := s.Decode(make([]byte, 0, PKSize), msg); err != ErrInvalidSignature {
+			t.Fatalf("Signature.Decode() should return ErrInvalidSignature for invalid signature, got %v", err)
+		}
+		if len(s.DecodeTo(nil, msg)) != 0 {
+			t.Fatalf("Signature.DecodeTo() should return out unchanged for invalid signature")
+		}
+		if _, err := s.Positions(msg); err != ErrInvalidSignature {
+			t.Fatalf("Signature.Positions() should return ErrInvalidSignature for invalid signature, got %v", err)
+		}
+		if _, err := s.MarshalBinary(); err != ErrInvalidSignature {
+			t.Fatalf("Signature.MarshalBinary() should return ErrInvalidSignature for invalid signature")
+		}
+		if _, err := s.MarshalText(); err != ErrInvalidSignature {
+			t.Fatalf("Signature.MarshalText() should return ErrInvalidSignature for invalid signature")
+		}
+		if _, err := s.MarshalJSON(); err != ErrInvalidSignature {
+			t.Fatalf("Signature.MarshalJSON() should return ErrInvalidSignature for invalid signature")
+		}
+	}
+
+	// JSON marshaling of a struct with a zero value signature fails instead of panicking
+	if _, err := json.Marshal(struct{ Sig *Signature }{Sig: &Signature{}}); err == nil {
+		t.Fatalf("json.Marshal() should return error for zero value signature")
+	}
+}
+
+func TestSignature_Consensus(t *testing.T) {
+	key := NewKey(DecodeParams(Consensus), rand.Reader)
+	msg := getRandData(t, 256)
+	pk := key.ComputePK()
+	sig, err := ParseSignature(key.Sign(msg))
+
+	if err != nil {
+		t.Fatalf("ParseSignature() returned error for consensus signature: %s", err)
+	}
+
+	// Decode behaves like DecodeTransactionSignature
+	if _, err := sig.Decode(make([]byte, 0, PKSize), msg); err != errConsensusParams {
+		t.Fatalf("Signature.Decode() should return error for consensus params, got %v", err)
+	}
+
+	if _, err := DecodeTransactionSignature(make([]byte, 0, PKSize), msg, sig.Bytes()); err != errConsensusParams {
+		t.Fatalf("DecodeTransactionSignature() should return error for consensus params, got %v", err)
+	}
+
+	if len(sig.DecodeTo(nil, msg)) != 0 {
+		t.Fatalf("Signature.DecodeTo() should return out unchanged for consensus params")
+	}
+
+	// Verify behaves like the package level Verify
+	if ok, err := sig.Verify(msg, pk); !ok || err != nil {
+		t.Fatalf("Signature.Verify() should accept consensus signatures, got %v", err)
+	}
+
+	if ok, err := Verify(msg, sig.Bytes(), pk); !ok || err != nil {
+		t.Fatalf("Verify() should accept consensus signatures, got %v", err)
+	}
+}
+
+func TestSignature_Positions(t *testing.T) {
+	msg := getRandData(t, 256)
+	for _, enc := range []ParamsEncoding{Level0, WOTSP_SHA2_256, Level1Randomized, Level2TargetSum, Level3Keyed} {
+		key := NewKey(DecodeParams(enc), rand.Reader)
+		sig, _ := ParseSignature(key.Sign(msg))
+
+		positions, err := sig.Positions(msg)
+		if err != nil {
+			t.Fatalf("Signature.Positions() returned error for %s: %s", enc, err)
+		}
+
+		// The signature points are the ladder values at the positions
+		key.Generate()
+		chains := sig.Chains()
+		if len(positions) != len(chains) {
+			t.Fatalf("Signature.Positions() returned %d positions for %d ladders with %s", len(positions), len(chains), enc)
+		}
+		if enc != WOTSP_SHA2_256 {
+			for i, pos := range positions {
+				if !bytes.Equal(chains[i], key.chains[pos][i*key.params.n:(i+1)*key.params.n]) {
+					t.Fatalf("Signature.Positions() returned wrong position for ladder %d with %s", i, enc)
+				}
+			}
+		}
+	}
+
+	// Target sum is checked, other messages only reach it with probability 2^-9.83
+	params := DecodeParams(Level0TargetSum)
+	sig, _ := ParseSignature(NewKey(params, rand.Reader).Sign(msg))
+	rejected := false
+	for i := 0; i < 16; i++ {
+		positions, err := sig.Positions(getRandData(t, 256))
+		if err == errTargetSum {
+			rejected = true
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Signature.Positions() returned unexpected error: %s", err)
+		}
+		sum := 0
+		for _, pos := range positions {
+			sum += int(pos)
+		}
+		if sum != params.TargetSum() {
+			t.Fatalf("Signature.Positions() returned positions that don't reach the target sum: %d", sum)
+		}
+	}
+	if !rejected {
+		t.Fatalf("Signature.Positions() should return error when the message doesn't reach the target sum")
+	}
+}
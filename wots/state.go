@@ -0,0 +1,234 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// ONE-TIME USE STATE
+/*
+	A WOTS+ key is broken if it signs two different messages, since
+	an attacker learns ladder points from both signatures and can
+	combine them to forge signatures for other messages.
+
+	A StatefulKey wraps a WOTS+ key and durably records each use in a
+	StateStore before releasing a signature. The signature is computed
+	first, and the ladder positions it releases are recorded, which for
+	randomized and target sum params also depend on the randomizer and
+	counter. Signing the same message again is allowed, since it releases
+	exactly the same ladder points, but signing any other message is
+	refused with a KeyReuseError. If the key can't sign, e.g., it was
+	destroyed, a SignError is returned and nothing is recorded
+
+	Sign, SignTo, SignDigest and NewSigner of the StatefulKey all record
+	their signatures in the store, so a digest signed after a message is
+	refused just like a second message.
+
+	The state is only enforced for signatures made through the
+	StatefulKey. The wrapper never exposes the wrapped key, but a key
+	passed to NewStatefulKey is still reachable by the caller, and
+	signing with it directly bypasses the store. Callers must drop all
+	references to the raw key once it is wrapped, or create the key
+	inside the wrapper with NewStatefulKeyFromSeed.
+*/
+
+// StateStore durably records the usage of WOTS+ keys
+// Implementations must be safe for concurrent use
+type StateStore interface {
+	// Reserve atomically records the digest for the key identified by id,
+	// if no digest was recorded for it before, and returns the digest that
+	// is recorded for the key after the call
+	// For StatefulKey, the digest is the ladder positions of the signature
+	// The record MUST be durable when Reserve returns without error
+	Reserve(id, digest []byte) ([]byte, error)
+}
+
+///////////////////////////////////////////////////////////////////////
+// Errors
+
+// KeyReuseError is returned when attempting to sign a different
+// message with a WOTS+ key that was already used
+type KeyReuseError struct {
+	// The public key of the WOTS+ key
+	PK []byte
+}
+
+func (e *KeyReuseError) Error() string {
+	return fmt.Sprintf("WOTS+ key %x was already used to sign a different message", e.PK)
+}
+
+// SignError is returned when the WOTS+ key couldn't sign, e.g., because it
+// was destroyed, or no counter reaches the target sum
+// The use of the key is not recorded
+type SignError struct {
+	// The public key of the WOTS+ key
+	PK []byte
+}
+
+func (e *SignError) Error() string {
+	return fmt.Sprintf("WOTS+ key %x couldn't sign the message", e.PK)
+}
+
+///////////////////////////////////////////////////////////////////////
+// STATEFUL KEY
+type StatefulKey struct {
+	// The WOTS+ key
+	key *Key
+	// The store where key usage is recorded
+	store StateStore
+}
+
+// Creates a stateful WOTS+ key that records usage in the given store
+// The StatefulKey takes ownership of the key: the caller MUST NOT keep or use
+// the raw key after this, since its signatures aren't recorded in the store
+func NewStatefulKey(key *Key, store StateStore) *StatefulKey {
+	if key == nil || store == nil {
+		return nil
+	}
+	// Compute the PK, which identifies the key in the store
+	key.ComputePK()
+	return &StatefulKey{
+		key:   key,
+		store: store,
+	}
+}
+
+// Creates a stateful WOTS+ key from the given seeds, that records usage in the given store
+// The WOTS+ key is only reachable through the StatefulKey
+func NewStatefulKeyFromSeed(params *Params, seed, pSeed []byte, store StateStore) *StatefulKey {
+	key := NewKeyFromSeed(params, seed, pSeed)
+	if key == nil {
+		return nil
+	}
+	return NewStatefulKey(key, store)
+}
+
+// Get the Public Key
+func (s *StatefulKey) GetPK() []byte {
+	return s.key.GetPK()
+}
+
+// Signs a message, after durably recording the use of the key
+// Returns a KeyReuseError if the key was already used to sign a different message
+func (s *StatefulKey) Sign(msg []byte) ([]byte, error) {
+	return s.SignTo(make([]byte, 0, 1+s.key.params.sigSize()), msg)
+}
+
+// Signs a message like Sign, appending the signature to out
+// Returns nil if the signature isn't released
+func (s *StatefulKey) SignTo(out, msg []byte) ([]byte, error) {
+	start := len(out)
+	return s.release(s.key.SignTo(out, msg), start, msg, nil)
+}
+
+// Signs an m byte message digest like Key.SignDigest, after durably recording the use of the key
+// Returns a SignError if the params use randomized message hashing, or the digest has the wrong size
+func (s *StatefulKey) SignDigest(digest []byte) ([]byte, error) {
+	return s.release(s.key.SignDigest(digest), 0, nil, digest)
+}
+
+// Record the ladder positions of the signature appended to out at start,
+// of msg, or of the digest if not nil, releasing it only if the store
+// allows it. Otherwise, the signature is wiped and nil is returned
+func (s *StatefulKey) release(out []byte, start int, msg, digest []byte) ([]byte, error) {
+	// 1. Check the signature was created
+	params := s.key.params
+	if len(out) != start+1+params.sigSize() {
+		return nil, &SignError{PK: s.key.GetPK()}
+	}
+	sig := out[start:]
+
+	// 2. Get the ladder positions released by the signature
+	sc := getScratch()
+	defer putScratch(sc)
+	pSeed, r, counter, _ := params.splitSignature(sig[1:])
+	if digest == nil {
+		digest = params.hashMsg(sc, r, pSeed, msg)
+	}
+	positions, ok := params.digestPositions(sc, digest, counter)
+	if !ok {
+		secmem.Wipe(sig)
+		return nil, &SignError{PK: s.key.GetPK()}
+	}
+
+	// 3. Record them
+	recorded, err := s.store.Reserve(s.key.GetPK(), positions)
+	if err != nil {
+		secmem.Wipe(sig)
+		return nil, err
+	}
+	if !bytes.Equal(recorded, positions) {
+		secmem.Wipe(sig)
+		return nil, &KeyReuseError{PK: s.key.GetPK()}
+	}
+	return out, nil
+}
+
+// Signs a message written to it like Signer, recording the use of the key
+type StatefulSigner struct {
+	key    *StatefulKey
+	signer *Signer
+}
+
+// Creates a streaming signer for the stateful key
+// Returns nil if the params use randomized message hashing
+func (s *StatefulKey) NewSigner() *StatefulSigner {
+	signer := s.key.NewSigner()
+	if signer == nil {
+		return nil
+	}
+	return &StatefulSigner{
+		key:    s,
+		signer: signer,
+	}
+}
+
+// Write part of the message, implementing io.Writer
+// Never returns an error
+func (s *StatefulSigner) Write(p []byte) (int, error) {
+	return s.signer.Write(p)
+}
+
+// Sign the message written so far, after durably recording the use of the key
+// Returns the same signature as StatefulKey.Sign for the whole message
+func (s *StatefulSigner) Sign() ([]byte, error) {
+	return s.key.SignDigest(s.signer.digest())
+}
+
+///////////////////////////////////////////////////////////////////////
+// MEMORY STATE STORE
+// Keeps usage records in memory only, so it is NOT durable
+// Useful for testing and for short lived processes
+type MemoryStateStore struct {
+	mux     sync.Mutex
+	records map[string][]byte
+}
+
+// Creates an empty memory state store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		records: make(map[string][]byte),
+	}
+}
+
+// Reserve implements StateStore
+func (m *MemoryStateStore) Reserve(id, digest []byte) ([]byte, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	key := hex.EncodeToString(id)
+	if recorded, ok := m.records[key]; ok {
+		return recorded, nil
+	}
+	m.records[key] = append([]byte{}, digest...)
+	return digest, nil
+}
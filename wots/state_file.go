@@ -0,0 +1,101 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// FILE STATE STORE
+/*
+	Keeps one record file per key in a directory, named after the hex
+	encoding of the key id.
+
+	Records are created with O_EXCL, which makes the creation atomic
+	even across processes sharing the directory, so only one signer can
+	ever reserve a key. The record contents and the directory entry are
+	fsynced before Reserve returns.
+
+	If the process crashes after the record is created but before the
+	digest is fully written, the record is left incomplete. Such a key
+	is still considered used, since it can't be known whether a
+	signature was released for it.
+*/
+type FileStateStore struct {
+	// Serializes reservations within this process
+	mux sync.Mutex
+	// Directory where records are kept
+	dir string
+}
+
+// Creates a file state store in the given directory, creating it if needed
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// Reserve implements StateStore
+func (f *FileStateStore) Reserve(id, digest []byte) ([]byte, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	path := filepath.Join(f.dir, hex.EncodeToString(id))
+
+	// 1. Atomically create the record, failing if it exists already
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		// Key was already reserved, return the recorded digest
+		return ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Write digest and sync record to disk
+	_, err = file.Write(digest)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Sync directory, so that the record entry is durable
+	if err = syncDir(f.dir); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// Sync a directory to disk
+// Directories can't be synced on windows, where file metadata
+// is already persisted when the file is synced
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
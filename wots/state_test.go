@@ -0,0 +1,245 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func testStatefulKey(t *testing.T, store StateStore, reopen func() StateStore) {
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+	key := NewStatefulKey(NewKeyFromSeed(level0Params, seed, pSeed), store)
+
+	if key == nil {
+		t.Fatalf("NewStatefulKey returned nil")
+	}
+
+	// Test first signature is valid
+	msg := []byte(TestData)
+	sig, err := key.Sign(msg)
+
+	if err != nil {
+		t.Fatalf("StatefulKey.Sign() returned error on first use: %s", err)
+	}
+
+	valid, _ := Verify(msg, sig, key.GetPK())
+
+	if !valid {
+		t.Fatalf("StatefulKey.Sign + Verify are not consistent!")
+	}
+
+	// Test signing the same message again returns the same signature
+	sig2, err := key.Sign(msg)
+
+	if err != nil || !bytes.Equal(sig, sig2) {
+		t.Fatalf("StatefulKey.Sign() should allow signing the same message again. Got error: %v", err)
+	}
+
+	// Test signing a different message is refused
+	sig2, err = key.Sign(getRandData(t, 256))
+
+	var reuse *KeyReuseError
+	if sig2 != nil || !errors.As(err, &reuse) {
+		t.Fatalf("StatefulKey.Sign() should return KeyReuseError when signing a different message. Got: %v", err)
+	}
+
+	if !bytes.Equal(reuse.PK, key.GetPK()) {
+		t.Fatalf("KeyReuseError has wrong public key. Got: %x, Expected: %x", reuse.PK, key.GetPK())
+	}
+
+	// Test a new key instance with the same seeds is also refused
+	key = NewStatefulKeyFromSeed(level0Params, seed, pSeed, reopen())
+	_, err = key.Sign(getRandData(t, 256))
+
+	if !errors.As(err, &reuse) {
+		t.Fatalf("StatefulKey.Sign() should return KeyReuseError for a reused key after reopening the store. Got: %v", err)
+	}
+}
+
+func TestStatefulKey_MemoryStore(t *testing.T) {
+	store := NewMemoryStateStore()
+	testStatefulKey(t, store, func() StateStore { return store })
+
+	// Test nil arguments
+	if NewStatefulKey(nil, store) != nil {
+		t.Fatalf("NewStatefulKey() should return nil when key is nil")
+	}
+
+	if NewStatefulKey(NewKey(level0Params, rand.Reader), nil) != nil {
+		t.Fatalf("NewStatefulKey() should return nil when store is nil")
+	}
+
+	if NewStatefulKeyFromSeed(level0Params, getRandData(t, SeedSize-1), getRandData(t, SeedSize), store) != nil {
+		t.Fatalf("NewStatefulKeyFromSeed() should return nil for invalid seed")
+	}
+}
+
+func TestStatefulKey_SignVariants(t *testing.T) {
+	msg := []byte(TestData)
+	for _, enc := range []ParamsEncoding{Level0, Level0Randomized, Level0TargetSum} {
+		params := DecodeParams(enc)
+		key := NewStatefulKey(NewKey(params, rand.Reader), NewMemoryStateStore())
+		sig, err := key.Sign(msg)
+		if err != nil {
+			t.Fatalf("StatefulKey.Sign() returned error for %s: %s", enc, err)
+		}
+
+		// The same message can be signed again with SignTo, and the signature is appended
+		prefix := []byte{0xAA}
+		out, err := key.SignTo(append([]byte{}, prefix...), msg)
+		if err != nil || !bytes.Equal(out, append(prefix, sig...)) {
+			t.Fatalf("StatefulKey.SignTo() should allow signing the same message again for %s. Got error: %v", enc, err)
+		}
+
+		// Any other message is refused, and the signature is not released
+		other := getRandData(t, 256)
+		var reuse *KeyReuseError
+		if out, err = key.SignTo(nil, other); out != nil || !errors.As(err, &reuse) {
+			t.Fatalf("StatefulKey.SignTo() should return KeyReuseError for %s. Got: %v", enc, err)
+		}
+
+		if enc == Level0Randomized {
+			if key.NewSigner() != nil {
+				t.Fatalf("StatefulKey.NewSigner() should return nil for %s", enc)
+			}
+			var signErr *SignError
+			if _, err = key.SignDigest(getRandData(t, params.m)); !errors.As(err, &signErr) {
+				t.Fatalf("StatefulKey.SignDigest() should return SignError for %s. Got: %v", enc, err)
+			}
+			continue
+		}
+
+		// Digests and streaming signers share the state with Sign
+		signer := key.NewSigner()
+		signer.Write(msg)
+		if out, err = signer.Sign(); err != nil || !bytes.Equal(out, sig) {
+			t.Fatalf("StatefulSigner.Sign() should allow signing the same message again for %s. Got error: %v", enc, err)
+		}
+
+		signer = key.NewSigner()
+		signer.Write(other)
+		if out, err = signer.Sign(); out != nil || !errors.As(err, &reuse) {
+			t.Fatalf("StatefulSigner.Sign() should return KeyReuseError for %s. Got: %v", enc, err)
+		}
+
+		if out, err = key.SignDigest(getRandData(t, params.m)); out != nil || !errors.As(err, &reuse) {
+			t.Fatalf("StatefulKey.SignDigest() should return KeyReuseError for %s. Got: %v", enc, err)
+		}
+	}
+}
+
+func TestStatefulKey_SignError(t *testing.T) {
+	store := NewMemoryStateStore()
+	key := NewStatefulKey(NewKey(level0Params, rand.Reader), store)
+
+	// Destroyed keys can't sign, and the key is not recorded as used
+	key.key.Destroy()
+	sig, err := key.Sign([]byte(TestData))
+
+	var signErr *SignError
+	if sig != nil || !errors.As(err, &signErr) {
+		t.Fatalf("StatefulKey.Sign() should return SignError for destroyed key. Got: %v", err)
+	}
+
+	if len(store.records) != 0 {
+		t.Fatalf("StatefulKey.Sign() shouldn't record the use of a key that can't sign")
+	}
+
+	if _, err = key.SignDigest(getRandData(t, level0Params.m)); !errors.As(err, &signErr) {
+		t.Fatalf("StatefulKey.SignDigest() should return SignError for destroyed key. Got: %v", err)
+	}
+}
+
+func TestStatefulKey_FileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := NewFileStateStore(dir)
+
+	if err != nil {
+		t.Fatalf("NewFileStateStore() returned error: %s", err)
+	}
+
+	testStatefulKey(t, store, func() StateStore {
+		s, err := NewFileStateStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileStateStore() returned error when reopening: %s", err)
+		}
+		return s
+	})
+}
+
+func TestFileStateStore_IncompleteRecord(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileStateStore(dir)
+	key := NewStatefulKey(NewKeyFromSeed(level0Params, getRandData(t, SeedSize), getRandData(t, SeedSize)), store)
+
+	// Simulate a crash after creating the record, but before writing the digest
+	f, err := os.Create(filepath.Join(dir, hex.EncodeToString(key.GetPK())))
+	if err != nil {
+		t.Fatalf("Error creating record file: %s", err)
+	}
+	_ = f.Close()
+
+	_, err = key.Sign([]byte(TestData))
+
+	var reuse *KeyReuseError
+	if !errors.As(err, &reuse) {
+		t.Fatalf("StatefulKey.Sign() should refuse signing when record is incomplete. Got: %v", err)
+	}
+
+	// Test store directory that can't be created
+	file := filepath.Join(dir, "file")
+	_ = ioutil.WriteFile(file, nil, 0600)
+
+	_, err = NewFileStateStore(filepath.Join(file, "state"))
+
+	if err == nil {
+		t.Fatalf("NewFileStateStore() should return error when directory can't be created")
+	}
+}
+
+func TestStatefulKey_Concurrent(t *testing.T) {
+	store, _ := NewFileStateStore(t.TempDir())
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+
+	// Sign different messages concurrently, with different key instances
+	// Exactly one signature must be released
+	const signers = 8
+	var wg sync.WaitGroup
+	sigs := make([][]byte, signers)
+	msgs := make([][]byte, signers)
+	for i := 0; i < signers; i++ {
+		msgs[i] = getRandData(t, 64)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := NewStatefulKey(NewKeyFromSeed(level0Params, seed, pSeed), store)
+			sigs[i], _ = key.Sign(msgs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	released := 0
+	for _, sig := range sigs {
+		if sig != nil {
+			released++
+		}
+	}
+
+	if released != 1 {
+		t.Fatalf("Concurrent StatefulKey.Sign() released %d signatures, expected 1", released)
+	}
+}
@@ -0,0 +1,141 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/xx-labs/sleeve/hasher"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////
+// TARGET SUM
+/*
+	The checksum ladders prevent forgeries by walking the ladders of a
+	signature further: a higher message digit lowers the checksum, which
+	can't be walked back. Following WOTS+C (Kudinov, Hulsing, Ronen,
+	Yogev, "SPHINCS+C: Compressing SPHINCS+ With (Almost) No Cost"),
+	target sum params drop the checksum ladders, and instead require
+	the message digits to add up to a fixed target sum S. Any other
+	digest that can be reached by walking the ladders further has a
+	larger sum, so it is rejected
+
+	To reach the target sum, the signer grinds a 4 byte counter:
+	  digest = MSG(msg)[:m]
+	  target = MSG(digest || counter)[:m]
+	until the len1 base w digits of target add up to S, and signs target
+	The verifier checks the sum before computing the ladders
+
+	S is set to len1 * (w-1) / 2, the most likely sum, so signing takes
+	the fewest tries. Requiring the sum reduces the digests that can be
+	signed, so the security of the message hash is reduced by
+	-log2(P(sum = S)) bits, see SecurityEstimate. Signatures are
+	(total - len1) * n - 4 bytes smaller
+
+	Signatures of target sum params are serialized as:
+	  ParamsEncoding, 1 byte
+	  Public Seed,    32 bytes
+	  Randomizer,     n bytes (only if the params use randomized message hashing)
+	  Counter,        4 bytes
+	  Ladder points,  len1*n bytes
+*/
+
+// Size of the counter in signatures
+const counterSize = 4
+
+var errTargetSum = errors.New("message digest doesn't reach the target sum")
+
+// Creates WOTS+ params with given values of n, m, w; prf and msg hashes,
+// like NewParamsW, without checksum ladders, using a target sum instead
+func NewParamsTargetSum(n, m, w int, prf, msg hasher.Hasher) *Params {
+	params := NewParamsW(n, m, w, prf, msg)
+	if params == nil {
+		return nil
+	}
+	params.total = params.len1
+	params.targetSum = params.len1 * (w - 1) / 2
+	return params
+}
+
+// Get the target sum of the message digits
+// Returns 0 if the params use checksum ladders
+func (p *Params) TargetSum() int {
+	return p.targetSum
+}
+
+// Get the size of the counter in signatures, 0 if the params don't use a target sum
+func (p *Params) counterSize() int {
+	if p.targetSum == 0 {
+		return 0
+	}
+	return counterSize
+}
+
+// Grind the counter until the digest hashed with it reaches the target sum,
+// using the scratch memory
+// The counter is appended to out, and the target digest is returned
+// Returns false if no counter reaches the target sum
+func (p *Params) grindTargetSum(s *scratch, out, digest []byte) ([]byte, []byte, bool) {
+	out, counter := extend(out, counterSize)
+	for c := uint64(0); c <= math.MaxUint32; c++ {
+		binary.BigEndian.PutUint32(counter, uint32(c))
+		if target, ok := p.targetDigest(s, digest, counter); ok {
+			return out, target, true
+		}
+	}
+	return out[:len(out)-counterSize], nil, false
+}
+
+// Hash the digest with the counter into the scratch memory
+// Returns the target digest, and whether its digits add up to the target sum
+func (p *Params) targetDigest(s *scratch, digest, counter []byte) ([]byte, bool) {
+	hMsg := p.msgHash.Get()
+	hMsg.Write(digest)
+	hMsg.Write(counter)
+	s.target = hasher.SumN(hMsg, growCap(s.target, outputSize(hMsg, p.m)), p.m)
+	p.msgHash.Put(hMsg)
+	target := s.target
+	return target, digitSum(target, p.logW, p.len1) == p.targetSum
+}
+
+// Get the bits of message hash security lost by requiring the target sum,
+// i.e., -log2(P(sum = S)) for len1 random base w digits
+func (p *Params) targetSumBits() float64 {
+	if p.targetSum == 0 {
+		return 0
+	}
+	// Distribution of the sum of i digits, adding one digit at a time
+	dist := []float64{1}
+	for i := 0; i < p.len1; i++ {
+		next := make([]float64, len(dist)+p.w-1)
+		window := 0.0
+		for sum := range next {
+			if sum < len(dist) {
+				window += dist[sum]
+			}
+			if sum >= p.w {
+				window -= dist[sum-p.w]
+			}
+			next[sum] = window / float64(p.w)
+		}
+		dist = next
+	}
+	return -math.Log2(dist[p.targetSum])
+}
+
+// Get the sum of the first n base w digits of x, where w = 2^logW
+// Digits are taken from the most significant bits of each byte first, like baseW
+func digitSum(x []byte, logW, n int) int {
+	sum := 0
+	mask := 1<<uint(logW) - 1
+	for i := 0; i < n; i++ {
+		bit := i * logW
+		sum += int(x[bit/8]>>uint(8-logW-bit%8)) & mask
+	}
+	return sum
+}
@@ -0,0 +1,274 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/xx-labs/sleeve/hasher"
+	"math"
+	"strings"
+	"testing"
+)
+
+var targetSumEncodings = []ParamsEncoding{Level0TargetSum, Level1TargetSum, Level2TargetSum, Level3TargetSum}
+
+func TestNewParamsTargetSum(t *testing.T) {
+	if NewParamsTargetSum(32, 0, 16, hasher.BLAKE2B_256, hasher.SHA3_256) != nil {
+		t.Fatalf("NewParamsTargetSum() should return nil for invalid params")
+	}
+
+	for _, w := range []int{4, 16, 256} {
+		params := NewParamsTargetSum(32, 24, w, hasher.BLAKE2B_256, hasher.SHA3_256)
+		checksumParams := NewParamsW(32, 24, w, hasher.BLAKE2B_256, hasher.SHA3_256)
+
+		if params.total != params.len1 || params.len1 != checksumParams.len1 {
+			t.Fatalf("NewParamsTargetSum() should only have message ladders for W = %d. Got %d ladders", w, params.total)
+		}
+
+		if params.TargetSum() != params.len1*(w-1)/2 {
+			t.Fatalf("TargetSum() returned wrong target sum for W = %d: %d", w, params.TargetSum())
+		}
+
+		if checksumParams.TargetSum() != 0 {
+			t.Fatalf("TargetSum() should return 0 for params with checksum ladders")
+		}
+
+		if params.Equal(checksumParams) {
+			t.Fatalf("Equal() should return false for target sum and checksum params")
+		}
+
+		if !strings.Contains(params.String(), "TARGET SUM") {
+			t.Fatalf("String() should show the target sum, got %s", params)
+		}
+
+		if params.SecurityEstimate().SignatureSize >= checksumParams.SecurityEstimate().SignatureSize {
+			t.Fatalf("Target sum signatures should be smaller than checksum signatures for W = %d", w)
+		}
+	}
+}
+
+func TestParams_TargetSumBits(t *testing.T) {
+	// W = 256, 1 digit: P(sum = 127) = 1/256
+	params := NewParamsTargetSum(32, 1, 256, hasher.BLAKE2B_256, hasher.SHA3_256)
+	if math.Abs(params.targetSumBits()-8) > 1e-9 {
+		t.Fatalf("targetSumBits() should return 8 bits for 1 digit of W = 256, got %f", params.targetSumBits())
+	}
+
+	// W = 4, 4 digits: 44 of the 256 digests have sum 6
+	params = NewParamsTargetSum(32, 1, 4, hasher.BLAKE2B_256, hasher.SHA3_256)
+	if math.Abs(params.targetSumBits()-math.Log2(256.0/44)) > 1e-9 {
+		t.Fatalf("targetSumBits() returned wrong value for 4 digits of W = 4: %f", params.targetSumBits())
+	}
+
+	est := params.SecurityEstimate()
+	if math.Abs(est.MsgClassical-(8-math.Log2(256.0/44))) > 1e-9 {
+		t.Fatalf("SecurityEstimate() should subtract the target sum bits from the message security, got %f",
+			est.MsgClassical)
+	}
+}
+
+func TestKey_Sign_TargetSum(t *testing.T) {
+	msg := getRandData(t, 256)
+	list := []*Params{
+		NewParamsTargetSum(32, 24, 4, hasher.BLAKE2B_256, hasher.SHA3_256),
+		NewParamsTargetSum(32, 24, 16, hasher.BLAKE2B_256, hasher.SHA3_256),
+		NewParamsTargetSum(32, 24, 16, hasher.BLAKE2B_256, hasher.SHA3_256).WithRandomizedHashing(),
+	}
+	for _, enc := range targetSumEncodings {
+		list = append(list, DecodeParams(enc))
+	}
+
+	for _, params := range list {
+		key := NewKey(params, rand.Reader)
+		pk := key.ComputePK()
+		sig := key.Sign(msg)
+
+		if len(sig) != 1+params.sigSize() || len(sig) != params.SecurityEstimate().SignatureSize {
+			t.Fatalf("Sign() returned signature with wrong size for %s: %d", params, len(sig))
+		}
+
+		// The signed digest reaches the target sum
+		_, r, counter, _ := params.splitSignature(sig[1:])
+		s := getScratch()
+		_, ok := params.targetDigest(s, params.hashMsg(s, r, key.pSeed, msg), counter)
+		putScratch(s)
+		if !ok {
+			t.Fatalf("Sign() should sign a digest that reaches the target sum for %s", params)
+		}
+
+		valid, err := params.Verify(msg, sig[1:], pk)
+		if err != nil || !valid {
+			t.Fatalf("Verify() should return true for valid signature with %s: %v", params, err)
+		}
+
+		// Generated keys return the same signature
+		key.Generate()
+		if !bytes.Equal(key.Sign(msg), sig) {
+			t.Fatalf("Sign() should return the same signature for generated key with %s", params)
+		}
+
+		// Tampering with the counter
+		sig[1+SeedSize+params.randomizerSize()] ^= 0x01
+		if valid, _ := params.Verify(msg, sig[1:], pk); valid {
+			t.Fatalf("Verify() should return false for tampered counter with %s", params)
+		}
+	}
+
+	// Package verification and digests
+	key := NewKey(DecodeParams(Level1TargetSum), rand.Reader)
+	sig := key.Sign(msg)
+	if valid, err := Verify(msg, sig, key.ComputePK()); err != nil || !valid {
+		t.Fatalf("Verify() should return true for valid signature: %v", err)
+	}
+	if !bytes.Equal(key.SignDigest(key.params.HashMessage(msg)), sig) {
+		t.Fatalf("SignDigest() should return the same signature as Sign()")
+	}
+	if valid, err := VerifyDigest(key.params.HashMessage(msg), sig, key.ComputePK()); err != nil || !valid {
+		t.Fatalf("VerifyDigest() should return true for valid signature: %v", err)
+	}
+}
+
+func TestSignature_TargetSum(t *testing.T) {
+	key := NewKey(DecodeParams(Level2TargetSum), rand.Reader)
+	msg := getRandData(t, 256)
+	raw := key.Sign(msg)
+
+	sig, err := ParseSignature(raw)
+	if err != nil {
+		t.Fatalf("ParseSignature() returned error for target sum signature: %s", err)
+	}
+
+	if !bytes.Equal(sig.Counter(), raw[1+SeedSize:1+SeedSize+counterSize]) || !bytes.Equal(sig.Bytes(), raw) {
+		t.Fatalf("ParseSignature() didn't load the counter")
+	}
+
+	if valid, err := sig.Verify(msg, key.ComputePK()); err != nil || !valid {
+		t.Fatalf("Signature.Verify() should return true for valid signature: %v", err)
+	}
+
+	// Wrong message
+	if valid, _ := sig.Verify(getRandData(t, 256), key.ComputePK()); valid {
+		t.Fatalf("Signature.Verify() should return false for wrong message")
+	}
+
+	data, _ := json.Marshal(sig)
+	other := new(Signature)
+	if err := json.Unmarshal(data, other); err != nil || !bytes.Equal(other.Bytes(), raw) {
+		t.Fatalf("json.Unmarshal() didn't load the target sum signature: %v", err)
+	}
+}
+
+// Find a message digest whose first len1 digits are all at least the digits of
+// the signed digest, but different, using next to get candidate digests
+// Ladder points for such a digest can be computed by anyone, walking the
+// ladders of the signature further
+func findDominatingDigest(t *testing.T, params *Params, signed []byte, next func(i int) []byte) []byte {
+	digits := make([]byte, params.len1)
+	candidate := make([]byte, params.len1)
+	baseW(digits, signed, params.logW)
+	for i := 0; i < 1<<20; i++ {
+		digest := next(i)
+		baseW(candidate, digest, params.logW)
+		dominates := !bytes.Equal(candidate, digits)
+		for j := range digits {
+			dominates = dominates && candidate[j] >= digits[j]
+		}
+		if dominates {
+			return digest
+		}
+	}
+	t.Fatalf("Couldn't find a dominating digest")
+	return nil
+}
+
+// Get the ladder points of the digits, as an attacker walking the ladders of a signature further would
+func forgePoints(key *Key, digits []byte) []byte {
+	n := key.params.n
+	points := make([]byte, 0, len(digits)*n)
+	for i, d := range digits {
+		points = append(points, key.chains[d][i*n:(i+1)*n]...)
+	}
+	return points
+}
+
+func TestTargetSum_Forgery(t *testing.T) {
+	// Small digests, so that dominating digests are found quickly
+	params := NewParamsTargetSum(32, 2, 4, hasher.BLAKE2B_256, hasher.SHA3_256)
+	key := NewKey(params, rand.Reader)
+	key.Generate()
+	pk := key.ComputePK()
+	msg := getRandData(t, 64)
+	sig := key.Sign(msg)
+	pSeed, _, counter, _ := params.splitSignature(sig[1:])
+
+	s := getScratch()
+	defer putScratch(s)
+	signed, _ := params.targetDigest(s, params.hashMsg(s, nil, nil, msg), counter)
+	signed = append([]byte{}, signed...)
+
+	// Grind counters for another message until the target digest dominates the signed one
+	other := getRandData(t, 64)
+	digest := append([]byte{}, params.hashMsg(s, nil, nil, other)...)
+	forgedCounter := make([]byte, counterSize)
+	forged := findDominatingDigest(t, params, signed, func(i int) []byte {
+		forgedCounter[0], forgedCounter[1], forgedCounter[2], forgedCounter[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		target, _ := params.targetDigest(s, digest, forgedCounter)
+		return target
+	})
+	digits := make([]byte, params.total)
+	baseW(digits, forged, params.logW)
+	points := forgePoints(key, digits)
+
+	// Without the target sum check, the forgery gives the public key
+	if !bytes.Equal(params.computeLadders(make([]byte, 0, PKSize), pSeed, forged, points, nil, false), pk) {
+		t.Fatalf("Forged ladder points should give the public key without the target sum check")
+	}
+
+	// With the target sum check, the forgery is rejected
+	forgedSig := append([]byte{}, pSeed...)
+	forgedSig = append(forgedSig, forgedCounter...)
+	forgedSig = append(forgedSig, points...)
+	valid, err := params.Verify(other, forgedSig, pk)
+	if valid || err != errTargetSum {
+		t.Fatalf("Verify() should reject forged signature with target sum error, got %v, %v", valid, err)
+	}
+}
+
+func TestChecksum_Forgery(t *testing.T) {
+	// The same forgery against the checksum construction
+	params := NewParamsW(32, 2, 4, hasher.BLAKE2B_256, hasher.SHA3_256)
+	key := NewKey(params, rand.Reader)
+	key.Generate()
+	pk := key.ComputePK()
+	msg := getRandData(t, 64)
+	sig := key.Sign(msg)
+
+	s := getScratch()
+	defer putScratch(s)
+	signed := append([]byte{}, params.hashMsg(s, nil, nil, msg)...)
+
+	// Find another message whose digest dominates the signed one
+	var other []byte
+	forged := findDominatingDigest(t, params, signed, func(i int) []byte {
+		other = getRandData(t, 64)
+		return params.hashMsg(s, nil, nil, other)
+	})
+	digits := make([]byte, params.total)
+	baseW(digits[:params.len1], forged, params.logW)
+
+	// Message ladders can be walked further, but the checksum ladders can't be walked back,
+	// so they are kept from the signature
+	n := params.n
+	points := forgePoints(key, digits[:params.len1])
+	points = append(points, sig[1+SeedSize+params.len1*n:]...)
+	forgedSig := append(append([]byte{}, sig[1:1+SeedSize]...), points...)
+	if valid, _ := params.Verify(other, forgedSig, pk); valid {
+		t.Fatalf("Verify() should reject forged signature")
+	}
+}
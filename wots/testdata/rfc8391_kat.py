@@ -0,0 +1,140 @@
+#!/usr/bin/env python3
+###############################################################################
+# Copyright © 2020 xx network SEZC                                            #
+#                                                                             #
+# Use of this source code is governed by a license that can be found in the   #
+# LICENSE file                                                                #
+###############################################################################
+
+# Independent cross check of the RFC 8391 WOTS+ known answer test in
+# wots/rfc8391_test.go (TestRFC8391_KnownAnswer)
+#
+# This is a direct transcription of the RFC 8391 pseudocode for
+# WOTSP-SHA2_256 (n = 32, w = 16, len = 67): base_w (Algorithm 1),
+# chain (Algorithm 2), WOTS_genPK (Algorithm 4), WOTS_sign (Algorithm 5)
+# and ltree (Algorithm 8), with the secret keys derived as in the XMSS
+# reference code and NIST SP 800-208:
+#     sk_i = SHA256(toByte(4, 32) || SEED_SK || SEED || ADRS)
+# It shares no code with the Go implementation and only uses hashlib
+#
+# Usage: python3 wots/testdata/rfc8391_kat.py
+# Prints the public key and the signature (ParamsEncoding || SEED || sig),
+# which must match rfcTestVectorPubKey and rfcTestVectorSignature
+
+import hashlib
+
+N = 32
+W = 16
+LOG_W = 4
+LEN_1 = 64
+LEN_2 = 3
+LEN = LEN_1 + LEN_2
+
+# Encoding of WOTSP_SHA2_256 in the wots params registry
+PARAMS_ENCODING = 0x10
+
+
+def to_byte(x, y):
+    return x.to_bytes(y, "big")
+
+
+def adrs(typ, w5=0, w6=0, w7=0):
+    # layer || tree (2 words) || type || 4 type specific words
+    words = [0, 0, 0, typ, 0, w5, w6, w7]
+    return b"".join(to_byte(w, 4) for w in words)
+
+
+def sha256(*parts):
+    return hashlib.sha256(b"".join(parts)).digest()
+
+
+def F(key, m):
+    return sha256(to_byte(0, 32), key, m)
+
+
+def H(key, m):
+    return sha256(to_byte(1, 32), key, m)
+
+
+def PRF(key, m):
+    return sha256(to_byte(3, 32), key, m)
+
+
+def base_w(x, out_len):
+    bits = 0
+    total = 0
+    i = 0
+    out = []
+    for _ in range(out_len):
+        if bits == 0:
+            total = x[i]
+            i += 1
+            bits += 8
+        bits -= LOG_W
+        out.append((total >> bits) & (W - 1))
+    return out
+
+
+# ADRS for OTS hash addresses: word 5 is the chain, word 6 the hash address
+# and word 7 keyAndMask
+def chain(x, i, s, seed, ch):
+    if s == 0:
+        return x
+    tmp = chain(x, i, s - 1, seed, ch)
+    key = PRF(seed, adrs(0, ch, i + s - 1, 0))
+    bm = PRF(seed, adrs(0, ch, i + s - 1, 1))
+    return F(key, bytes(a ^ b for a, b in zip(tmp, bm)))
+
+
+def secret_keys(sk_seed, seed):
+    return [sha256(to_byte(4, 32), sk_seed, seed, adrs(0, i)) for i in range(LEN)]
+
+
+def wots_gen_pk(sk, seed):
+    return [chain(sk[i], 0, W - 1, seed, i) for i in range(LEN)]
+
+
+def wots_sign(m, sk, seed):
+    msg = base_w(m, LEN_1)
+    csum = sum(W - 1 - d for d in msg)
+    csum <<= 8 - ((LEN_2 * LOG_W) % 8)
+    len_2_bytes = (LEN_2 * LOG_W + 7) // 8
+    msg += base_w(to_byte(csum, len_2_bytes), LEN_2)
+    return [chain(sk[i], 0, msg[i], seed, i) for i in range(LEN)]
+
+
+# ADRS for L-tree addresses: word 5 is the tree height, word 6 the tree index
+def ltree(pk, seed):
+    pk = list(pk)
+    length = len(pk)
+    height = 0
+    while length > 1:
+        for i in range(length // 2):
+            key = PRF(seed, adrs(1, height, i, 0))
+            bm0 = PRF(seed, adrs(1, height, i, 1))
+            bm1 = PRF(seed, adrs(1, height, i, 2))
+            left = bytes(a ^ b for a, b in zip(pk[2 * i], bm0))
+            right = bytes(a ^ b for a, b in zip(pk[2 * i + 1], bm1))
+            pk[i] = H(key, left + right)
+        if length % 2 == 1:
+            pk[length // 2] = pk[length - 1]
+        length = (length + 1) // 2
+        height += 1
+    return pk[0]
+
+
+def main():
+    sk_seed = bytes(range(0, 32))
+    seed = bytes(range(32, 64))
+    m = hashlib.sha256(b"XX NETWORK").digest()
+
+    sk = secret_keys(sk_seed, seed)
+    pk = ltree(wots_gen_pk(sk, seed), seed)
+    sig = wots_sign(m, sk, seed)
+
+    print("pk:  " + pk.hex())
+    print("sig: " + (bytes([PARAMS_ENCODING]) + seed + b"".join(sig)).hex())
+
+
+if __name__ == "__main__":
+    main()
@@ -23,7 +23,7 @@ func (k *Key) benchComputePK() []byte {
 	prfBuffer := make([]byte, 0, hPrf.Size())
 
 	// Compute random elements
-	rands := computeRands(k.params.n, k.pSeed, hPrf)
+	rands := computeRands(k.params.n, k.params.w, k.pSeed, hPrf)
 
 	// Chains memory
 	value := make([]byte, k.params.n)
@@ -0,0 +1,100 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"runtime"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// PARALLEL LADDERS
+/*
+	Each ladder is computed independently of all others, until the
+	final tweak and public key hash. This allows Generate, ComputePK,
+	Sign and Decode to split the ladders across multiple goroutines
+
+	Each worker is assigned a contiguous range of ladders, and writes
+	its results to that range of the outputs only, so the result is
+	byte-identical to computing the ladders sequentially
+*/
+
+// Use one worker per CPU
+const AllCPUs = -1
+
+// Get a copy of the params using the given maximum number of workers
+// to compute ladders
+// If n is AllCPUs, runtime.NumCPU() workers are used
+// If n is 0 or 1, ladders are computed sequentially (default)
+func (p *Params) WithWorkers(n int) *Params {
+	if n == AllCPUs {
+		n = runtime.NumCPU()
+	}
+	if n < 1 {
+		n = 1
+	}
+	cp := *p
+	cp.workers = n
+	return &cp
+}
+
+// Get the maximum number of workers used to compute ladders
+func (p *Params) Workers() int {
+	if p.workers < 1 {
+		return 1
+	}
+	return p.workers
+}
+
+// Set the maximum number of workers used by this key to compute ladders
+// The key params are replaced by a copy, so other keys using the same
+// params are not affected
+func (k *Key) SetWorkers(n int) {
+	k.params = k.params.WithWorkers(n)
+}
+
+// Compute the ladders of the scratch job over contiguous ranges of
+// ladders [from, to), covering all ladders, using at most p.Workers()
+// goroutines, each with its own worker memory
+func (p *Params) forEachLadder(s *scratch) {
+	workers := p.Workers()
+	if workers > p.total {
+		workers = p.total
+	}
+
+	// Sequential
+	if workers == 1 {
+		s.job.run(&s.getWorkers(1)[0], 0, p.total)
+		return
+	}
+
+	// Parallel
+	size := (p.total + workers - 1) / workers
+	mem := s.getWorkers(workers)
+	var wg sync.WaitGroup
+	for from, k := 0, 0; from < p.total; from, k = from+size, k+1 {
+		to := from + size
+		if to > p.total {
+			to = p.total
+		}
+		wg.Add(1)
+		go func(w *ladderWorker, from, to int) {
+			defer wg.Done()
+			s.job.run(w, from, to)
+		}(&mem[k], from, to)
+	}
+	wg.Wait()
+}
+
+// Compute ladders [from, to) of the job
+func (j *ladderJob) run(w *ladderWorker, from, to int) {
+	if j.params.construction == ConstructionRFC8391 {
+		j.params.rfcWalkLadders(j, w, from, to)
+		return
+	}
+	j.params.walkLadders(j, w, from, to)
+}
@@ -0,0 +1,106 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package wots
+
+import (
+	"bytes"
+	"github.com/xx-labs/sleeve/hasher"
+	"reflect"
+	"testing"
+)
+
+func TestParams_WithWorkers(t *testing.T) {
+	params := NewParams(32, 32, hasher.BLAKE3_256, hasher.BLAKE3_256)
+
+	if params.Workers() != 1 {
+		t.Fatalf("Params should use 1 worker by default. Got %d", params.Workers())
+	}
+
+	other := params.WithWorkers(4)
+
+	if other.Workers() != 4 {
+		t.Fatalf("Params.WithWorkers() didn't set workers. Got %d, expected 4", other.Workers())
+	}
+
+	if params.Workers() != 1 {
+		t.Fatalf("Params.WithWorkers() shouldn't modify the original params")
+	}
+
+	if !params.Equal(other) {
+		t.Fatalf("Params with different number of workers should be equal")
+	}
+
+	if EncodeParams(level0Params.WithWorkers(4)) != Level0 {
+		t.Fatalf("Params with different number of workers should have the same encoding")
+	}
+
+	if params.WithWorkers(0).Workers() != 1 || params.WithWorkers(-5).Workers() != 1 {
+		t.Fatalf("Params.WithWorkers() should use 1 worker for invalid values")
+	}
+
+	if params.WithWorkers(AllCPUs).Workers() < 1 {
+		t.Fatalf("Params.WithWorkers() should use at least 1 worker for AllCPUs")
+	}
+}
+
+func TestKey_Workers_Consistency(t *testing.T) {
+	paramsList := []*Params{
+		DecodeParams(Level0),
+		DecodeParams(Consensus),
+		DecodeParams(WOTSP_SHA2_256),
+		NewParamsW(32, 24, 4, hasher.BLAKE3_256, hasher.SHA3_224),
+	}
+	seed := getRandData(t, SeedSize)
+	pSeed := getRandData(t, SeedSize)
+	msg := getRandData(t, 256)
+
+	for _, params := range paramsList {
+		// Sequential results
+		key := NewKeyFromSeed(params, seed, pSeed)
+		sig := key.Sign(msg)
+		pk := key.ComputePK()
+		key.Generate()
+		chains := key.chains
+
+		for _, workers := range []int{2, 3, 7, AllCPUs, 1000} {
+			key = NewKeyFromSeed(params, seed, pSeed)
+			key.SetWorkers(workers)
+
+			if !bytes.Equal(key.Sign(msg), sig) {
+				t.Fatalf("Key.Sign() with %d workers is different for params %s", workers, params)
+			}
+
+			if !bytes.Equal(key.ComputePK(), pk) {
+				t.Fatalf("Key.ComputePK() with %d workers is different for params %s", workers, params)
+			}
+
+			key = NewKeyFromSeed(params, seed, pSeed)
+			key.SetWorkers(workers)
+			key.Generate()
+
+			if !bytes.Equal(key.GetPK(), pk) {
+				t.Fatalf("Key.Generate() with %d workers computed different PK for params %s", workers, params)
+			}
+
+			if !reflect.DeepEqual(key.chains, chains) {
+				t.Fatalf("Key.Generate() with %d workers computed different chains for params %s", workers, params)
+			}
+
+			out := make([]byte, 0, PKSize)
+			out, err := params.WithWorkers(workers).Decode(out, msg, sig[1:])
+
+			if err != nil || !bytes.Equal(out, pk) {
+				t.Fatalf("Params.Decode() with %d workers is different for params %s", workers, params)
+			}
+		}
+
+		// Params of the original key are not modified
+		if params.Workers() != 1 {
+			t.Fatalf("Key.SetWorkers() shouldn't modify the original params")
+		}
+	}
+}
@@ -8,6 +8,7 @@ package wots
 
 import (
 	"crypto/rand"
+	"fmt"
 	"testing"
 )
 
@@ -52,3 +53,106 @@ func BenchmarkDecodeParallel(b *testing.B) {
 		b.Run(p.String(), benchmarkDecodeParams)
 	}
 }
+
+func benchmarkGenerateWorkers(workers int) func(b *testing.B) {
+	return func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			key := NewKeyFromSeed(p, t.seed, t.pSeed)
+			key.SetWorkers(workers)
+			key.Generate()
+		}
+	}
+}
+
+func BenchmarkGenerateWorkers(b *testing.B) {
+	initTestData()
+	p = DecodeParams(DefaultParams)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("Workers %d", workers), benchmarkGenerateWorkers(workers))
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	initTestData()
+	p = DecodeParams(DefaultParams)
+
+	// Signatures from keys sharing the same public seed
+	items := make([]Item, 64)
+	for i := range items {
+		seed := make([]byte, SeedSize)
+		_, _ = rand.Read(seed)
+		key := NewKeyFromSeed(p, seed, t.pSeed)
+		items[i] = Item{Msg: t.msg, Signature: key.Sign(t.msg), PublicKey: key.ComputePK()}
+	}
+
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				_, _ = Verify(item.Msg, item.Signature, item.PublicKey)
+			}
+		}
+	})
+
+	b.Run("VerifyBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = VerifyBatch(items)
+		}
+	})
+}
+
+func BenchmarkSignCheckpoints(b *testing.B) {
+	initTestData()
+	p = DecodeParams(DefaultParams)
+	for _, interval := range []int{1, 4, 16, 64, W} {
+		key := NewKeyFromSeed(p, t.seed, t.pSeed)
+		_ = key.SetCheckpointInterval(interval)
+		key.Generate()
+		b.Run(fmt.Sprintf("Interval %d (%d bytes)", interval, p.CheckpointMemory(interval)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = key.Sign(t.msg)
+			}
+		})
+	}
+}
+
+// Steady state allocations of the hot path, should be 0 allocs/op
+func BenchmarkAllocs(b *testing.B) {
+	initTestData()
+	for enc := ParamsEncoding(0); enc < ParamsEncodingLen; enc++ {
+		p = DecodeParams(enc)
+		key := NewKeyFromSeed(p, t.seed, t.pSeed)
+		sig := key.Sign(t.msg)
+		pk := key.ComputePK()
+		out := make([]byte, 0, len(sig))
+
+		b.Run(fmt.Sprintf("Verify %s", p), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = Verify(t.msg, sig, pk)
+			}
+		})
+
+		b.Run(fmt.Sprintf("DecodeTo %s", p), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out, _ = p.DecodeTo(out[:0], t.msg, sig[1:])
+			}
+		})
+
+		b.Run(fmt.Sprintf("SignTo %s", p), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out = key.SignTo(out[:0], t.msg)
+			}
+		})
+
+		// PK is not stored by ComputePKTo, so it's computed every time
+		fresh := NewKeyFromSeed(p, t.seed, t.pSeed)
+		b.Run(fmt.Sprintf("ComputePKTo %s", p), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out = fresh.ComputePKTo(out[:0])
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/xx-labs/sleeve/hasher"
+	"github.com/xx-labs/sleeve/internal/secmem"
+	"github.com/xx-labs/sleeve/wots"
+	"io"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////
+// XMSS-STYLE MERKLE TREE OF WOTS+ KEYS
+/*
+	A single WOTS+ key can only sign one message. This package builds a
+	Merkle tree over the public keys of 2^h WOTS+ keys, so that a single
+	32 byte root can be used as a long lived public key, able to sign
+	up to 2^h messages.
+
+	All WOTS+ keys are derived from one secret seed and one public seed:
+	  seed_i  = SHA3_256("xx network xmss seed"  || seed  || i)
+	  pSeed_i = SHA3_256("xx network xmss pseed" || pSeed || i)
+
+	Tree nodes are computed as
+	  node = SHA3_256(pSeed || level || index || left || right)
+	where level is 1 byte and index is 4 bytes big endian, with the
+	leaves being the WOTS+ public keys.
+
+	Signatures are composed by
+	  Height,         1 byte
+	  Leaf index,     4 bytes
+	  Public seed,    32 bytes
+	  WOTS+ signature
+	  Auth path,      Height*32 bytes
+
+	The root doesn't commit to the height and WOTS+ params, which are read
+	from the signature, so they are part of the public key:
+	  Height,         1 byte
+	  WOTS+ params,   1 byte
+	  Root,           32 bytes
+	and Verify rejects signatures using any other height or params, e.g.,
+	weaker params registered with wots.RegisterParams
+
+	WARNING: This is a stateful scheme. The leaf index MUST never be
+	reused, so it must be persisted after each signature (see Index)
+*/
+
+// Maximum height of the tree, allowing 2^16 signatures per key
+const MaxHeight = 16
+
+// The size of the tree root and nodes
+const RootSize = 32
+
+// The size of the public key: height || params || root
+const PublicKeySize = 2 + RootSize
+
+// The hash function used for tree nodes and key derivation
+const TreeHash = hasher.SHA3_256
+
+// Size of the signature header: height || index || public seed
+const headerSize = 1 + 4 + wots.SeedSize
+
+///////////////////////////////////////////////////////////////////////
+// Errors
+var (
+	errInvalidHeight    = errors.New(fmt.Sprintf("tree height must be between 1 and %d", MaxHeight))
+	errInvalidParams    = errors.New("unknown WOTS+ params encoding")
+	errInvalidSeed      = errors.New(fmt.Sprintf("seeds must have %d bytes", wots.SeedSize))
+	errReadingSeed      = errors.New("couldn't read enough bytes of entropy from provided reader")
+	errKeyExhausted     = errors.New("all one-time keys of the tree have been used")
+	errInvalidIndex     = errors.New("leaf index can't be decreased or exceed the number of leaves")
+	errWrongPubKeySize  = errors.New(fmt.Sprintf("public key has incorrect length: should be %d bytes", PublicKeySize))
+	errInvalidSignature = errors.New("signature is malformed")
+	errKeyMismatch      = errors.New("signature height or WOTS+ params don't match the public key")
+)
+
+// XMSS PRIVATE KEY //
+type PrivateKey struct {
+	// Height of the tree
+	height int
+	// WOTS+ params encoding used for all leaves
+	params wots.ParamsEncoding
+	// Secret seed, used to derive WOTS+ secret seeds
+	seed []byte
+	// Public seed, used to derive WOTS+ public seeds and hash the tree
+	pSeed []byte
+	// Full tree, tree[0] are the leaves and tree[height][0] is the root
+	tree [][][]byte
+	// Index of the next leaf to be used
+	index uint32
+	// Protects index
+	mux sync.Mutex
+}
+
+///////////////////////////////////////////////////////////////////////
+// Constructors
+
+// Creates an XMSS key with given height and WOTS+ params, and uses csprng
+// to read random values for the seed and public seed
+func NewKey(height int, params wots.ParamsEncoding, csprng io.Reader) (*PrivateKey, error) {
+	seed := make([]byte, wots.SeedSize)
+	pSeed := make([]byte, wots.SeedSize)
+	if n, err := csprng.Read(seed); err != nil || n != wots.SeedSize {
+		return nil, errReadingSeed
+	}
+	if n, err := csprng.Read(pSeed); err != nil || n != wots.SeedSize {
+		return nil, errReadingSeed
+	}
+	return NewKeyFromSeed(height, params, seed, pSeed)
+}
+
+// Creates an XMSS key with given height and WOTS+ params, and given secret and public seeds
+// This computes all 2^height WOTS+ public keys, so it can take a while for large trees
+func NewKeyFromSeed(height int, params wots.ParamsEncoding, seed, pSeed []byte) (*PrivateKey, error) {
+	if height < 1 || height > MaxHeight {
+		return nil, errInvalidHeight
+	}
+	if wots.DecodeParams(params) == nil {
+		return nil, errInvalidParams
+	}
+	if len(seed) != wots.SeedSize || len(pSeed) != wots.SeedSize {
+		return nil, errInvalidSeed
+	}
+
+	k := &PrivateKey{
+		height: height,
+		params: params,
+		seed:   make([]byte, wots.SeedSize),
+		pSeed:  make([]byte, wots.SeedSize),
+	}
+	copy(k.seed, seed)
+	copy(k.pSeed, pSeed)
+
+	// Compute leaves
+	k.tree = make([][][]byte, height+1)
+	k.tree[0] = make([][]byte, 1<<uint(height))
+	for i := range k.tree[0] {
+		key := k.wotsKey(uint32(i))
+		k.tree[0][i] = key.ComputePK()
+		key.Destroy()
+	}
+
+	// Compute inner nodes
+	for level := 1; level <= height; level++ {
+		k.tree[level] = make([][]byte, len(k.tree[level-1])/2)
+		for i := range k.tree[level] {
+			k.tree[level][i] = hashNode(k.pSeed, level, uint32(i), k.tree[level-1][2*i], k.tree[level-1][2*i+1])
+		}
+	}
+	return k, nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// Getters
+
+// Get the root of the tree, which is the public key
+func (k *PrivateKey) Root() []byte {
+	root := make([]byte, RootSize)
+	copy(root, k.tree[k.height][0])
+	return root
+}
+
+// Get the public key: the height, WOTS+ params encoding and root of the tree
+func (k *PrivateKey) PublicKey() []byte {
+	pk := make([]byte, 0, PublicKeySize)
+	pk = append(pk, byte(k.height), byte(k.params))
+	return append(pk, k.tree[k.height][0]...)
+}
+
+// Get the height of the tree
+func (k *PrivateKey) Height() int {
+	return k.height
+}
+
+// Get the index of the next leaf to be used
+// This MUST be persisted after each signature, and restored with SetIndex
+func (k *PrivateKey) Index() uint32 {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	return k.index
+}
+
+// Get the number of signatures that can still be produced
+func (k *PrivateKey) Remaining() uint32 {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	return k.leaves() - k.index
+}
+
+// Set the index of the next leaf to be used, restoring persisted state
+// The index can't be decreased, since that would reuse one-time keys
+func (k *PrivateKey) SetIndex(index uint32) error {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	if index < k.index || index > k.leaves() {
+		return errInvalidIndex
+	}
+	k.index = index
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// SIGN
+// Signs an arbitrary length message using the next unused leaf
+// Returns an error if all leaves have been used
+func (k *PrivateKey) Sign(msg []byte) ([]byte, error) {
+	// 1. Reserve leaf index
+	k.mux.Lock()
+	if k.index >= k.leaves() {
+		k.mux.Unlock()
+		return nil, errKeyExhausted
+	}
+	idx := k.index
+	k.index++
+	k.mux.Unlock()
+
+	// 2. Sign message with the leaf WOTS+ key
+	key := k.wotsKey(idx)
+	wotsSig := key.Sign(msg)
+	key.Destroy()
+
+	// 3. Build signature
+	signature := make([]byte, headerSize, headerSize+len(wotsSig)+k.height*RootSize)
+	signature[0] = byte(k.height)
+	binary.BigEndian.PutUint32(signature[1:5], idx)
+	copy(signature[5:headerSize], k.pSeed)
+	signature = append(signature, wotsSig...)
+
+	// 4. Append auth path, i.e., the sibling of each node from leaf to root
+	for level := 0; level < k.height; level++ {
+		signature = append(signature, k.tree[level][(idx>>uint(level))^1]...)
+	}
+	return signature, nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// VERIFY
+// Verify a signature against the public key, see PrivateKey.PublicKey
+// Signatures with a different height or WOTS+ params than the public key are rejected
+func Verify(msg, signature, publicKey []byte) (bool, error) {
+	// 1. Check arguments
+	if len(publicKey) != PublicKeySize {
+		return false, errWrongPubKeySize
+	}
+	if len(signature) < headerSize+1 {
+		return false, errInvalidSignature
+	}
+	root := publicKey[2:]
+
+	// 2. Parse header
+	height := int(signature[0])
+	if height < 1 || height > MaxHeight {
+		return false, errInvalidHeight
+	}
+	if height != int(publicKey[0]) {
+		return false, errKeyMismatch
+	}
+	idx := binary.BigEndian.Uint32(signature[1:5])
+	if idx >= 1<<uint(height) {
+		return false, errInvalidSignature
+	}
+	pSeed := signature[5:headerSize]
+
+	// 3. Split WOTS+ signature and auth path
+	wotsSig := signature[headerSize:]
+	if len(wotsSig) < height*RootSize+1 {
+		return false, errInvalidSignature
+	}
+	auth := wotsSig[len(wotsSig)-height*RootSize:]
+	wotsSig = wotsSig[:len(wotsSig)-height*RootSize]
+
+	// 4. WOTS+ signature must use the params of the public key, and the public seed derived for the leaf
+	if wotsSig[0] != publicKey[1] {
+		return false, errKeyMismatch
+	}
+	params := wots.DecodeParams(wots.ParamsEncoding(wotsSig[0]))
+	if params == nil {
+		return false, errInvalidParams
+	}
+	if len(wotsSig) < 1+wots.SeedSize || !bytes.Equal(wotsSig[1:1+wots.SeedSize], deriveSeed(pSeedTag, pSeed, idx)) {
+		return false, nil
+	}
+
+	// 5. Decode WOTS+ signature into leaf
+	node := make([]byte, 0, wots.PKSize)
+	node, err := params.Decode(node, msg, wotsSig[1:])
+	if err != nil {
+		return false, err
+	}
+
+	// 6. Climb the tree using the auth path
+	for level := 0; level < height; level++ {
+		sibling := auth[level*RootSize : (level+1)*RootSize]
+		parent := idx >> uint(level+1)
+		if (idx>>uint(level))&1 == 0 {
+			node = hashNode(pSeed, level+1, parent, node, sibling)
+		} else {
+			node = hashNode(pSeed, level+1, parent, sibling, node)
+		}
+	}
+
+	// 7. Compare root
+	return bytes.Equal(node, root), nil
+}
+
+///////////////////////////////////////////////////////////////////////
+// PRIVATE
+
+const (
+	seedTag  = "xx network xmss seed"
+	pSeedTag = "xx network xmss pseed"
+)
+
+// Number of leaves in the tree
+func (k *PrivateKey) leaves() uint32 {
+	return 1 << uint(k.height)
+}
+
+// Derive the WOTS+ key of the given leaf
+func (k *PrivateKey) wotsKey(idx uint32) *wots.Key {
+	seed := deriveSeed(seedTag, k.seed, idx)
+	defer secmem.Wipe(seed)
+	return wots.NewKeyFromSeed(wots.DecodeParams(k.params), seed, deriveSeed(pSeedTag, k.pSeed, idx))
+}
+
+// Derive a leaf seed: SHA3_256(tag || seed || idx)
+func deriveSeed(tag string, seed []byte, idx uint32) []byte {
+	h := TreeHash.New()
+	h.Write([]byte(tag))
+	h.Write(seed)
+	var idxBytes [4]byte
+	binary.BigEndian.PutUint32(idxBytes[:], idx)
+	h.Write(idxBytes[:])
+	return h.Sum(nil)
+}
+
+// Hash a tree node: SHA3_256(pSeed || level || index || left || right)
+func hashNode(pSeed []byte, level int, idx uint32, left, right []byte) []byte {
+	h := TreeHash.New()
+	h.Write(pSeed)
+	var pos [5]byte
+	pos[0] = byte(level)
+	binary.BigEndian.PutUint32(pos[1:], idx)
+	h.Write(pos[:])
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
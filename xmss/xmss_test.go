@@ -0,0 +1,271 @@
+////////////////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2020 xx network SEZC                                                       //
+//                                                                                        //
+// Use of this source code is governed by a license that can be found in the LICENSE file //
+////////////////////////////////////////////////////////////////////////////////////////////
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"github.com/xx-labs/sleeve/wots"
+	"testing"
+)
+
+type ErrReader struct{}
+
+func (r *ErrReader) Read(p []byte) (n int, err error) {
+	return 0, errors.New("TEST")
+}
+
+func getRandData(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("Error reading random bytes: %s", err)
+	}
+	return data
+}
+
+func TestNewKey(t *testing.T) {
+	// Test valid key
+	key, err := NewKey(2, wots.Level0, rand.Reader)
+
+	if err != nil || key == nil {
+		t.Fatalf("NewKey() returned error for valid arguments: %v", err)
+	}
+
+	if len(key.Root()) != RootSize {
+		t.Fatalf("NewKey() generated root of wrong size")
+	}
+
+	// Test error reader
+	_, err = NewKey(2, wots.Level0, &ErrReader{})
+
+	if err == nil {
+		t.Fatalf("NewKey() should return error when there's an error reading seeds")
+	}
+
+	// Test invalid height
+	seed := getRandData(t, wots.SeedSize)
+	_, err = NewKeyFromSeed(0, wots.Level0, seed, seed)
+
+	if err == nil {
+		t.Fatalf("NewKeyFromSeed() should return error when height is 0")
+	}
+
+	_, err = NewKeyFromSeed(MaxHeight+1, wots.Level0, seed, seed)
+
+	if err == nil {
+		t.Fatalf("NewKeyFromSeed() should return error when height is larger than %d", MaxHeight)
+	}
+
+	// Test invalid params
+	_, err = NewKeyFromSeed(2, wots.ParamsEncodingLen, seed, seed)
+
+	if err == nil {
+		t.Fatalf("NewKeyFromSeed() should return error when WOTS+ params are invalid")
+	}
+
+	// Test invalid seeds
+	_, err = NewKeyFromSeed(2, wots.Level0, seed[1:], seed)
+
+	if err == nil {
+		t.Fatalf("NewKeyFromSeed() should return error when secret seed has wrong size")
+	}
+
+	// Test root is deterministic
+	pSeed := getRandData(t, wots.SeedSize)
+	key, _ = NewKeyFromSeed(2, wots.Level0, seed, pSeed)
+	key2, _ := NewKeyFromSeed(2, wots.Level0, seed, pSeed)
+
+	if !bytes.Equal(key.Root(), key2.Root()) {
+		t.Fatalf("NewKeyFromSeed() should generate the same root for the same seeds")
+	}
+}
+
+func TestPrivateKey_SignVerify(t *testing.T) {
+	const height = 3
+	key, err := NewKey(height, wots.Level1, rand.Reader)
+
+	if err != nil {
+		t.Fatalf("NewKey() returned error for valid arguments: %s", err)
+	}
+
+	root := key.PublicKey()
+
+	// Sign with all leaves
+	for i := uint32(0); i < 1<<height; i++ {
+		if key.Index() != i {
+			t.Fatalf("Wrong leaf index. Got %d, expected %d", key.Index(), i)
+		}
+
+		msg := getRandData(t, 64)
+		sig, err := key.Sign(msg)
+
+		if err != nil {
+			t.Fatalf("Sign() returned error for leaf %d: %s", i, err)
+		}
+
+		valid, err := Verify(msg, sig, root)
+
+		if err != nil || !valid {
+			t.Fatalf("Sign + Verify are not consistent for leaf %d: (%v, %v)", i, valid, err)
+		}
+
+		// Test wrong message
+		valid, _ = Verify(getRandData(t, 64), sig, root)
+
+		if valid {
+			t.Fatalf("Verify() should return false for a different message")
+		}
+
+		// Test tampered auth path
+		sig[len(sig)-1] ^= 0x01
+		valid, _ = Verify(msg, sig, root)
+
+		if valid {
+			t.Fatalf("Verify() should return false when auth path is tampered")
+		}
+		sig[len(sig)-1] ^= 0x01
+
+		// Test tampered leaf index
+		sig[4] ^= 0x01
+		valid, _ = Verify(msg, sig, root)
+
+		if valid {
+			t.Fatalf("Verify() should return false when leaf index is tampered")
+		}
+		sig[4] ^= 0x01
+	}
+
+	// Test key is exhausted
+	if key.Remaining() != 0 {
+		t.Fatalf("Remaining() should return 0 after using all leaves. Got %d", key.Remaining())
+	}
+
+	_, err = key.Sign(getRandData(t, 64))
+
+	if err == nil {
+		t.Fatalf("Sign() should return error when all leaves have been used")
+	}
+}
+
+func TestPrivateKey_SetIndex(t *testing.T) {
+	seed := getRandData(t, wots.SeedSize)
+	pSeed := getRandData(t, wots.SeedSize)
+	key, _ := NewKeyFromSeed(2, wots.Level0, seed, pSeed)
+
+	// Test restoring index
+	err := key.SetIndex(3)
+
+	if err != nil || key.Index() != 3 || key.Remaining() != 1 {
+		t.Fatalf("SetIndex() didn't restore index correctly")
+	}
+
+	// Test index can't be decreased
+	err = key.SetIndex(2)
+
+	if err == nil {
+		t.Fatalf("SetIndex() should return error when decreasing the index")
+	}
+
+	// Test index can't exceed leaves
+	err = key.SetIndex(5)
+
+	if err == nil {
+		t.Fatalf("SetIndex() should return error when index exceeds number of leaves")
+	}
+
+	// Test restored key signs with the correct leaf
+	msg := getRandData(t, 64)
+	sig, _ := key.Sign(msg)
+	valid, _ := Verify(msg, sig, key.PublicKey())
+
+	if !valid || sig[4] != 3 {
+		t.Fatalf("Sign() after SetIndex() should use the restored leaf index")
+	}
+}
+
+func TestVerify_WrongInputs(t *testing.T) {
+	key, _ := NewKey(2, wots.Level0, rand.Reader)
+	pk := key.PublicKey()
+	msg := getRandData(t, 64)
+	sig, _ := key.Sign(msg)
+
+	// Test public key layout
+	if len(pk) != PublicKeySize || pk[0] != 2 || pk[1] != byte(wots.Level0) || !bytes.Equal(pk[2:], key.Root()) {
+		t.Fatalf("PublicKey() returned wrong public key: %x", pk)
+	}
+
+	// Test wrong public key size
+	_, err := Verify(msg, sig, pk[1:])
+
+	if err == nil {
+		t.Fatalf("Verify() should return error when public key has wrong size")
+	}
+
+	// Test wrong root
+	valid, _ := Verify(msg, sig, append(pk[:2:2], getRandData(t, RootSize)...))
+
+	if valid {
+		t.Fatalf("Verify() should return false for a different root")
+	}
+
+	// Test empty message is accepted, like wots.Verify
+	empty, _ := key.Sign(nil)
+	valid, err = Verify(nil, empty, pk)
+
+	if err != nil || !valid {
+		t.Fatalf("Verify() should accept a signature of an empty message: (%v, %v)", valid, err)
+	}
+
+	// Test truncated signatures
+	_, err = Verify(msg, sig[:headerSize], pk)
+
+	if err == nil {
+		t.Fatalf("Verify() should return error when signature is truncated")
+	}
+
+	_, err = Verify(msg, sig[:len(sig)-1], pk)
+
+	if err == nil {
+		t.Fatalf("Verify() should return error when signature is truncated")
+	}
+
+	// Test invalid height
+	sig[0] = MaxHeight + 1
+	_, err = Verify(msg, sig, pk)
+
+	if err == nil {
+		t.Fatalf("Verify() should return error when height is invalid")
+	}
+	sig[0] = 2
+}
+
+func TestVerify_KeyMismatch(t *testing.T) {
+	seed := getRandData(t, wots.SeedSize)
+	pSeed := getRandData(t, wots.SeedSize)
+	key, _ := NewKeyFromSeed(2, wots.Level1, seed, pSeed)
+	msg := getRandData(t, 64)
+
+	// A signature from a tree with other params or height is rejected, even if the root matches
+	for _, other := range []struct {
+		height int
+		params wots.ParamsEncoding
+	}{{2, wots.Level0}, {3, wots.Level1}} {
+		otherKey, _ := NewKeyFromSeed(other.height, other.params, seed, pSeed)
+		sig, _ := otherKey.Sign(msg)
+
+		if valid, err := Verify(msg, sig, otherKey.PublicKey()); err != nil || !valid {
+			t.Fatalf("Sign + Verify are not consistent for height %d and %s", other.height, other.params)
+		}
+
+		forged := append(key.PublicKey()[:2:2], otherKey.Root()...)
+		if valid, err := Verify(msg, sig, forged); valid || err != errKeyMismatch {
+			t.Fatalf("Verify() should return errKeyMismatch for height %d and %s. Got (%v, %v)",
+				other.height, other.params, valid, err)
+		}
+	}
+}